@@ -87,8 +87,20 @@ var sampleAppsAndroid22Versions = []interface{}{
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.InstallMissingAndroidToolsVersion,
+	steps.CachePullVersion,
 	steps.GradleRunnerVersion,
+	steps.SignAPKVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
+
+	steps.ActivateSSHKeyVersion,
+	steps.GitCloneVersion,
+	steps.ScriptVersion,
+	steps.InstallMissingAndroidToolsVersion,
+	steps.CachePullVersion,
+	steps.GradleRunnerVersion,
+	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsAndroid22ResultYML = fmt.Sprintf(`options:
@@ -122,6 +134,31 @@ configs:
       - pull_request_source_branch: '*'
         workflow: primary
       workflows:
+        deploy:
+          steps:
+          - activate-ssh-key@%s:
+              run_if: '{{getenv "SSH_RSA_PRIVATE_KEY" | ne ""}}'
+          - git-clone@%s: {}
+          - script@%s:
+              title: Do anything with Script step
+          - install-missing-android-tools@%s: {}
+          - cache-pull@%s: {}
+          - gradle-runner@%s:
+              inputs:
+              - gradle_file: $GRADLE_BUILD_FILE_PATH
+              - gradle_task: assembleRelease
+              - gradlew_path: $GRADLEW_PATH
+          - sign-apk@%s:
+              inputs:
+              - android_app: $BITRISE_APK_PATH
+          - deploy-to-bitrise-io@%s:
+              inputs:
+              - deploy_path: $BITRISE_SIGNED_APK_PATH
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -130,12 +167,18 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - install-missing-android-tools@%s: {}
+          - cache-pull@%s: {}
           - gradle-runner@%s:
               inputs:
               - gradle_file: $GRADLE_BUILD_FILE_PATH
               - gradle_task: $GRADLE_TASK
               - gradlew_path: $GRADLEW_PATH
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
 warnings:
   android: []
 `, sampleAppsAndroid22Versions...)
@@ -146,8 +189,20 @@ var androidNonExecutableGradlewVersions = []interface{}{
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.InstallMissingAndroidToolsVersion,
+	steps.CachePullVersion,
 	steps.GradleRunnerVersion,
+	steps.SignAPKVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
+
+	steps.ActivateSSHKeyVersion,
+	steps.GitCloneVersion,
+	steps.ScriptVersion,
+	steps.InstallMissingAndroidToolsVersion,
+	steps.CachePullVersion,
+	steps.GradleRunnerVersion,
+	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var androidNonExecutableGradlewResultYML = fmt.Sprintf(`options:
@@ -181,6 +236,31 @@ configs:
       - pull_request_source_branch: '*'
         workflow: primary
       workflows:
+        deploy:
+          steps:
+          - activate-ssh-key@%s:
+              run_if: '{{getenv "SSH_RSA_PRIVATE_KEY" | ne ""}}'
+          - git-clone@%s: {}
+          - script@%s:
+              title: Do anything with Script step
+          - install-missing-android-tools@%s: {}
+          - cache-pull@%s: {}
+          - gradle-runner@%s:
+              inputs:
+              - gradle_file: $GRADLE_BUILD_FILE_PATH
+              - gradle_task: assembleRelease
+              - gradlew_path: $GRADLEW_PATH
+          - sign-apk@%s:
+              inputs:
+              - android_app: $BITRISE_APK_PATH
+          - deploy-to-bitrise-io@%s:
+              inputs:
+              - deploy_path: $BITRISE_SIGNED_APK_PATH
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -189,12 +269,18 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - install-missing-android-tools@%s: {}
+          - cache-pull@%s: {}
           - gradle-runner@%s:
               inputs:
               - gradle_file: $GRADLE_BUILD_FILE_PATH
               - gradle_task: $GRADLE_TASK
               - gradlew_path: $GRADLEW_PATH
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
 warnings:
   android: []
 `, androidNonExecutableGradlewVersions...)
@@ -59,16 +59,22 @@ var sampleAppsCordovaWithJasmineVersions = []interface{}{
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
+	steps.CachePullVersion,
+	steps.NpmVersion,
 	steps.JasmineTestRunnerVersion,
 	steps.GenerateCordovaBuildConfigVersion,
 	steps.CordovaArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
+	steps.CachePullVersion,
+	steps.NpmVersion,
 	steps.JasmineTestRunnerVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsCordovaWithJasmineResultYML = fmt.Sprintf(`options:
@@ -101,6 +107,11 @@ configs:
           - git-clone@%s: {}
           - script@%s:
               title: Do anything with Script step
+          - cache-pull@%s: {}
+          - npm@%s:
+              inputs:
+              - command: install
+              - workdir: $BITRISE_SOURCE_DIR
           - jasmine-runner@%s: {}
           - generate-cordova-build-configuration@%s: {}
           - cordova-archive@%s:
@@ -108,6 +119,9 @@ configs:
               - platform: $CORDOVA_PLATFORM
               - target: emulator
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/node_modules
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -115,8 +129,16 @@ configs:
           - git-clone@%s: {}
           - script@%s:
               title: Do anything with Script step
+          - cache-pull@%s: {}
+          - npm@%s:
+              inputs:
+              - command: install
+              - workdir: $BITRISE_SOURCE_DIR
           - jasmine-runner@%s: {}
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/node_modules
 warnings:
   cordova: []
 `, sampleAppsCordovaWithJasmineVersions...)
@@ -126,16 +148,22 @@ var sampleAppsCordovaWithKarmaJasmineVersions = []interface{}{
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
+	steps.CachePullVersion,
+	steps.NpmVersion,
 	steps.KarmaJasmineTestRunnerVersion,
 	steps.GenerateCordovaBuildConfigVersion,
 	steps.CordovaArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
+	steps.CachePullVersion,
+	steps.NpmVersion,
 	steps.KarmaJasmineTestRunnerVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsCordovaWithKarmaJasmineResultYML = fmt.Sprintf(`options:
@@ -168,6 +196,11 @@ configs:
           - git-clone@%s: {}
           - script@%s:
               title: Do anything with Script step
+          - cache-pull@%s: {}
+          - npm@%s:
+              inputs:
+              - command: install
+              - workdir: $BITRISE_SOURCE_DIR
           - karma-jasmine-runner@%s: {}
           - generate-cordova-build-configuration@%s: {}
           - cordova-archive@%s:
@@ -175,6 +208,9 @@ configs:
               - platform: $CORDOVA_PLATFORM
               - target: emulator
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/node_modules
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -182,8 +218,16 @@ configs:
           - git-clone@%s: {}
           - script@%s:
               title: Do anything with Script step
+          - cache-pull@%s: {}
+          - npm@%s:
+              inputs:
+              - command: install
+              - workdir: $BITRISE_SOURCE_DIR
           - karma-jasmine-runner@%s: {}
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/node_modules
 warnings:
   cordova: []
   `, sampleAppsCordovaWithKarmaJasmineVersions...)
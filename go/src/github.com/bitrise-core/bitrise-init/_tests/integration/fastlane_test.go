@@ -76,6 +76,10 @@ var fastlaneResultYML = fmt.Sprintf(`options:
         value_map:
           ios test:
             config: fastlane-config
+        value_descriptions:
+          ios test: Runs all tests, archives app
+    value_descriptions:
+      BitriseFastlaneSample: match (git)
   ios:
     title: Project (or Workspace) path
     env_key: BITRISE_PROJECT_PATH
@@ -156,6 +160,8 @@ configs:
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
 warnings:
-  fastlane: []
+  fastlane:
+  - match configured but no MATCH_PASSWORD secret likely set, add it as a secret env
+    var to let match decrypt the code signing files
   ios: []
 `, fastlaneVersions...)
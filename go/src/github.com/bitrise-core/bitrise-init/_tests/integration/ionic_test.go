@@ -42,8 +42,11 @@ var sampleAppsIonicVersions = []interface{}{
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
+	steps.CachePullVersion,
+	steps.NpmVersion,
 	steps.IonicBuildVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsIonicResultYML = fmt.Sprintf(`options:
@@ -74,10 +77,18 @@ configs:
           - git-clone@%s: {}
           - script@%s:
               title: Do anything with Script step
+          - cache-pull@%s: {}
+          - npm@%s:
+              inputs:
+              - command: ci
+              - workdir: $BITRISE_SOURCE_DIR
           - ionic-build@%s:
               inputs:
               - build_for_platform: $IONIC_PLATFORM
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/node_modules -> $BITRISE_SOURCE_DIR/package-lock.json
 warnings:
   ionic: []
 `, sampleAppsIonicVersions...)
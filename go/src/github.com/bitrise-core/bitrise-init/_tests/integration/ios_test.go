@@ -183,18 +183,22 @@ var iosCocoapodsAtRootVersions = []interface{}{
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
+	steps.CachePullVersion,
 	steps.CocoapodsInstallVersion,
 	steps.XcodeTestVersion,
 	steps.XcodeArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
+	steps.CachePullVersion,
 	steps.CocoapodsInstallVersion,
 	steps.XcodeTestVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var iosCocoapodsAtRootResultYML = fmt.Sprintf(`options:
@@ -228,6 +232,7 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - certificate-and-profile-installer@%s: {}
+          - cache-pull@%s: {}
           - cocoapods-install@%s: {}
           - xcode-test@%s:
               inputs:
@@ -238,6 +243,9 @@ configs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -246,12 +254,16 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - certificate-and-profile-installer@%s: {}
+          - cache-pull@%s: {}
           - cocoapods-install@%s: {}
           - xcode-test@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock
 warnings:
   ios: []
 `, iosCocoapodsAtRootVersions...)
@@ -391,18 +403,22 @@ var sampleAppsCarthageVersions = []interface{}{
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
+	steps.CachePullVersion,
 	steps.CarthageVersion,
 	steps.XcodeTestVersion,
 	steps.XcodeArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
+	steps.CachePullVersion,
 	steps.CarthageVersion,
 	steps.XcodeTestVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsCarthageResultYML = fmt.Sprintf(`options:
@@ -436,6 +452,7 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - certificate-and-profile-installer@%s: {}
+          - cache-pull@%s: {}
           - carthage@%s:
               inputs:
               - carthage_command: bootstrap
@@ -448,6 +465,9 @@ configs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Carthage -> $BITRISE_SOURCE_DIR/Cartfile.resolved
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -456,6 +476,7 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - certificate-and-profile-installer@%s: {}
+          - cache-pull@%s: {}
           - carthage@%s:
               inputs:
               - carthage_command: bootstrap
@@ -464,6 +485,9 @@ configs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Carthage -> $BITRISE_SOURCE_DIR/Cartfile.resolved
 warnings:
   ios: []
 `, sampleAppsCarthageVersions...)
@@ -45,14 +45,28 @@ var customConfigVersions = []interface{}{
 	steps.ScriptVersion,
 	steps.InstallMissingAndroidToolsVersion,
 	steps.ChangeWorkDirVersion,
+	steps.CachePullVersion,
 	steps.GradleRunnerVersion,
+	steps.SignAPKVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
+
+	steps.ActivateSSHKeyVersion,
+	steps.GitCloneVersion,
+	steps.ScriptVersion,
+	steps.InstallMissingAndroidToolsVersion,
+	steps.ChangeWorkDirVersion,
+	steps.CachePullVersion,
+	steps.GradleRunnerVersion,
+	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	// cordova
 	models.FormatVersion,
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
+	steps.NpmVersion,
 	steps.GenerateCordovaBuildConfigVersion,
 	steps.CordovaArchiveVersion,
 	steps.DeployToBitriseIoVersion,
@@ -66,6 +80,15 @@ var customConfigVersions = []interface{}{
 	steps.FastlaneVersion,
 	steps.DeployToBitriseIoVersion,
 
+	// ionic
+	models.FormatVersion,
+	steps.ActivateSSHKeyVersion,
+	steps.GitCloneVersion,
+	steps.ScriptVersion,
+	steps.NpmVersion,
+	steps.IonicBuildVersion,
+	steps.DeployToBitriseIoVersion,
+
 	// ios
 	models.FormatVersion,
 	steps.ActivateSSHKeyVersion,
@@ -73,19 +96,34 @@ var customConfigVersions = []interface{}{
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
 	steps.RecreateUserSchemesVersion,
+	steps.CachePullVersion,
 	steps.CocoapodsInstallVersion,
 	steps.XcodeTestVersion,
 	steps.XcodeArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
 	steps.RecreateUserSchemesVersion,
+	steps.CachePullVersion,
 	steps.CocoapodsInstallVersion,
 	steps.XcodeTestVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
+
+	// kotlin-multiplatform
+	models.FormatVersion,
+	steps.ActivateSSHKeyVersion,
+	steps.GitCloneVersion,
+	steps.ScriptVersion,
+	steps.InstallMissingAndroidToolsVersion,
+	steps.CachePullVersion,
+	steps.GradleRunnerVersion,
+	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	// macos
 	models.FormatVersion,
@@ -94,19 +132,23 @@ var customConfigVersions = []interface{}{
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
 	steps.RecreateUserSchemesVersion,
+	steps.CachePullVersion,
 	steps.CocoapodsInstallVersion,
 	steps.XcodeTestMacVersion,
 	steps.XcodeArchiveMacVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	steps.ActivateSSHKeyVersion,
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
 	steps.RecreateUserSchemesVersion,
+	steps.CachePullVersion,
 	steps.CocoapodsInstallVersion,
 	steps.XcodeTestMacVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 
 	// other
 	models.FormatVersion,
@@ -122,10 +164,12 @@ var customConfigVersions = []interface{}{
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
 	steps.XamarinUserManagementVersion,
+	steps.CachePullVersion,
 	steps.NugetRestoreVersion,
 	steps.XamarinComponentsRestoreVersion,
 	steps.XamarinArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var customConfigResultYML = fmt.Sprintf(`options:
@@ -171,6 +215,14 @@ var customConfigResultYML = fmt.Sprintf(`options:
         value_map:
           _:
             config: default-fastlane-config
+  ionic:
+    title: Platform to use in ionic-cli commands
+    env_key: IONIC_PLATFORM
+    value_map:
+      android:
+        config: default-ionic-config
+      ios:
+        config: default-ionic-config
   ios:
     title: Project (or Workspace) path
     env_key: BITRISE_PROJECT_PATH
@@ -181,6 +233,20 @@ var customConfigResultYML = fmt.Sprintf(`options:
         value_map:
           _:
             config: default-ios-config
+  kotlin-multiplatform:
+    title: Gradlew file path
+    env_key: GRADLEW_PATH
+    value_map:
+      _:
+        title: Path to the gradle file to use
+        env_key: GRADLE_BUILD_FILE_PATH
+        value_map:
+          _:
+            title: Gradle task to run
+            env_key: GRADLE_TASK
+            value_map:
+              _:
+                config: default-kotlin-multiplatform-config
   macos:
     title: Project (or Workspace) path
     env_key: BITRISE_PROJECT_PATH
@@ -217,6 +283,35 @@ configs:
       - pull_request_source_branch: '*'
         workflow: primary
       workflows:
+        deploy:
+          steps:
+          - activate-ssh-key@%s:
+              run_if: '{{getenv "SSH_RSA_PRIVATE_KEY" | ne ""}}'
+          - git-clone@%s: {}
+          - script@%s:
+              title: Do anything with Script step
+          - install-missing-android-tools@%s: {}
+          - change-workdir@%s:
+              inputs:
+              - path: $GRADLEW_DIR_PATH
+              - is_create_path: "false"
+          - cache-pull@%s: {}
+          - gradle-runner@%s:
+              inputs:
+              - gradle_file: $GRADLE_BUILD_FILE_PATH
+              - gradle_task: assembleRelease
+              - gradlew_path: $GRADLEW_PATH
+          - sign-apk@%s:
+              inputs:
+              - android_app: $BITRISE_APK_PATH
+          - deploy-to-bitrise-io@%s:
+              inputs:
+              - deploy_path: $BITRISE_SIGNED_APK_PATH
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -229,12 +324,18 @@ configs:
               inputs:
               - path: $GRADLEW_DIR_PATH
               - is_create_path: "false"
+          - cache-pull@%s: {}
           - gradle-runner@%s:
               inputs:
               - gradle_file: $GRADLE_BUILD_FILE_PATH
               - gradle_task: $GRADLE_TASK
               - gradlew_path: $GRADLEW_PATH
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
   cordova:
     default-cordova-config: |
       format_version: "%s"
@@ -253,6 +354,10 @@ configs:
           - git-clone@%s: {}
           - script@%s:
               title: Do anything with Script step
+          - npm@%s:
+              inputs:
+              - command: install
+              - workdir: $CORDOVA_WORK_DIR
           - generate-cordova-build-configuration@%s: {}
           - cordova-archive@%s:
               inputs:
@@ -287,6 +392,32 @@ configs:
               - lane: $FASTLANE_LANE
               - work_dir: $FASTLANE_WORK_DIR
           - deploy-to-bitrise-io@%s: {}
+  ionic:
+    default-ionic-config: |
+      format_version: "%s"
+      default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+      project_type: ionic
+      trigger_map:
+      - push_branch: '*'
+        workflow: primary
+      - pull_request_source_branch: '*'
+        workflow: primary
+      workflows:
+        primary:
+          steps:
+          - activate-ssh-key@%s:
+              run_if: '{{getenv "SSH_RSA_PRIVATE_KEY" | ne ""}}'
+          - git-clone@%s: {}
+          - script@%s:
+              title: Do anything with Script step
+          - npm@%s:
+              inputs:
+              - command: install
+              - workdir: $BITRISE_SOURCE_DIR
+          - ionic-build@%s:
+              inputs:
+              - build_for_platform: $IONIC_PLATFORM
+          - deploy-to-bitrise-io@%s: {}
   ios:
     default-ios-config: |
       format_version: "%s"
@@ -309,6 +440,7 @@ configs:
           - recreate-user-schemes@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
+          - cache-pull@%s: {}
           - cocoapods-install@%s: {}
           - xcode-test@%s:
               inputs:
@@ -319,6 +451,9 @@ configs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -330,12 +465,47 @@ configs:
           - recreate-user-schemes@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
+          - cache-pull@%s: {}
           - cocoapods-install@%s: {}
           - xcode-test@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock
+  kotlin-multiplatform:
+    default-kotlin-multiplatform-config: |
+      format_version: "%s"
+      default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+      project_type: kotlin-multiplatform
+      trigger_map:
+      - push_branch: '*'
+        workflow: primary
+      - pull_request_source_branch: '*'
+        workflow: primary
+      workflows:
+        primary:
+          steps:
+          - activate-ssh-key@%s:
+              run_if: '{{getenv "SSH_RSA_PRIVATE_KEY" | ne ""}}'
+          - git-clone@%s: {}
+          - script@%s:
+              title: Do anything with Script step
+          - install-missing-android-tools@%s: {}
+          - cache-pull@%s: {}
+          - gradle-runner@%s:
+              inputs:
+              - gradle_file: $GRADLE_BUILD_FILE_PATH
+              - gradle_task: $GRADLE_TASK
+              - gradlew_path: $GRADLEW_PATH
+          - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: |-
+                  $HOME/.gradle/caches -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
+                  $HOME/.gradle/wrapper -> $BITRISE_SOURCE_DIR/gradle/wrapper/gradle-wrapper.properties
   macos:
     default-macos-config: |
       format_version: "%s"
@@ -358,6 +528,7 @@ configs:
           - recreate-user-schemes@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
+          - cache-pull@%s: {}
           - cocoapods-install@%s: {}
           - xcode-test-mac@%s:
               inputs:
@@ -368,6 +539,9 @@ configs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock
         primary:
           steps:
           - activate-ssh-key@%s:
@@ -379,12 +553,16 @@ configs:
           - recreate-user-schemes@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
+          - cache-pull@%s: {}
           - cocoapods-install@%s: {}
           - xcode-test-mac@%s:
               inputs:
               - project_path: $BITRISE_PROJECT_PATH
               - scheme: $BITRISE_SCHEME
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock
   other:
     other-config: |
       format_version: "%s"
@@ -425,6 +603,7 @@ configs:
           - certificate-and-profile-installer@%s: {}
           - xamarin-user-management@%s:
               run_if: .IsCI
+          - cache-pull@%s: {}
           - nuget-restore@%s: {}
           - xamarin-components-restore@%s: {}
           - xamarin-archive@%s:
@@ -433,4 +612,7 @@ configs:
               - xamarin_configuration: $BITRISE_XAMARIN_CONFIGURATION
               - xamarin_platform: $BITRISE_XAMARIN_PLATFORM
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $HOME/.nuget/packages
 `, customConfigVersions...)
@@ -78,10 +78,12 @@ var xamarinSampleAppVersions = []interface{}{
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
 	steps.XamarinUserManagementVersion,
+	steps.CachePullVersion,
 	steps.NugetRestoreVersion,
 	steps.XamarinComponentsRestoreVersion,
 	steps.XamarinArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var xamarinSampleAppResultYML = fmt.Sprintf(`options:
@@ -135,6 +137,7 @@ configs:
           - certificate-and-profile-installer@%s: {}
           - xamarin-user-management@%s:
               run_if: .IsCI
+          - cache-pull@%s: {}
           - nuget-restore@%s: {}
           - xamarin-components-restore@%s: {}
           - xamarin-archive@%s:
@@ -143,6 +146,9 @@ configs:
               - xamarin_configuration: $BITRISE_XAMARIN_CONFIGURATION
               - xamarin_platform: $BITRISE_XAMARIN_PLATFORM
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $HOME/.nuget/packages
 warnings:
   xamarin: []
 `, xamarinSampleAppVersions...)
@@ -153,9 +159,11 @@ var sampleAppsXamarinIosVersions = []interface{}{
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
+	steps.CachePullVersion,
 	steps.NugetRestoreVersion,
 	steps.XamarinArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsXamarinIosResultYML = fmt.Sprintf(`options:
@@ -207,6 +215,7 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - certificate-and-profile-installer@%s: {}
+          - cache-pull@%s: {}
           - nuget-restore@%s: {}
           - xamarin-archive@%s:
               inputs:
@@ -214,6 +223,9 @@ configs:
               - xamarin_configuration: $BITRISE_XAMARIN_CONFIGURATION
               - xamarin_platform: $BITRISE_XAMARIN_PLATFORM
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $HOME/.nuget/packages
 warnings:
   xamarin: []
 `, sampleAppsXamarinIosVersions...)
@@ -224,9 +236,11 @@ var sampleAppsXamarinAndroidVersions = []interface{}{
 	steps.GitCloneVersion,
 	steps.ScriptVersion,
 	steps.CertificateAndProfileInstallerVersion,
+	steps.CachePullVersion,
 	steps.NugetRestoreVersion,
 	steps.XamarinArchiveVersion,
 	steps.DeployToBitriseIoVersion,
+	steps.CachePushVersion,
 }
 
 var sampleAppsXamarinAndroidResultYML = fmt.Sprintf(`options:
@@ -270,6 +284,7 @@ configs:
           - script@%s:
               title: Do anything with Script step
           - certificate-and-profile-installer@%s: {}
+          - cache-pull@%s: {}
           - nuget-restore@%s: {}
           - xamarin-archive@%s:
               inputs:
@@ -277,6 +292,9 @@ configs:
               - xamarin_configuration: $BITRISE_XAMARIN_CONFIGURATION
               - xamarin_platform: $BITRISE_XAMARIN_PLATFORM
           - deploy-to-bitrise-io@%s: {}
+          - cache-push@%s:
+              inputs:
+              - cache_paths: $HOME/.nuget/packages
 warnings:
   xamarin: []
 `, sampleAppsXamarinAndroidVersions...)
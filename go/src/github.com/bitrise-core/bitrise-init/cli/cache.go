@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const noCacheKey = "no-cache"
+
+var cacheFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:   noCacheKey,
+		Usage:  "Do not add cache-pull and cache-push steps for the detected dependency managers to the generated workflows.",
+		EnvVar: "BITRISE_INIT_NO_CACHE",
+	},
+}
+
+// setupCache enables or disables the cache steps of the generated workflows based on the cache flags.
+func setupCache(c *cli.Context) {
+	if c.Bool(noCacheKey) {
+		log.Infoft(colorstring.Yellow("dependency caching disabled"))
+	}
+	models.SetCacheEnabled(!c.Bool(noCacheKey))
+}
@@ -64,6 +64,8 @@ func Run() {
 		versionCommand,
 		configCommand,
 		manualConfigCommand,
+		submitCommand,
+		upgradeCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -3,14 +3,16 @@ package cli
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/output"
 	"github.com/bitrise-core/bitrise-init/scanner"
+	"github.com/bitrise-core/bitrise-init/scanners"
 	"github.com/bitrise-io/go-utils/colorstring"
-	"github.com/bitrise-io/go-utils/command"
 	"github.com/bitrise-io/go-utils/log"
 	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/urfave/cli"
@@ -30,7 +32,7 @@ var configCommand = cli.Command{
 		}
 		return nil
 	},
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		cli.StringFlag{
 			Name:  "dir",
 			Usage: "Directory to scan.",
@@ -46,7 +48,22 @@ var configCommand = cli.Command{
 			Usage: "Output format, options [json, yaml].",
 			Value: "yaml",
 		},
-	},
+		cli.StringFlag{
+			Name:   "scanners",
+			Usage:  "Comma separated list of the scanners to run, all scanners run if empty.",
+			EnvVar: "BITRISE_INIT_SCANNERS",
+		},
+		cli.StringFlag{
+			Name:   "skip-scanners",
+			Usage:  "Comma separated list of the scanners to skip.",
+			EnvVar: "BITRISE_INIT_SKIP_SCANNERS",
+		},
+		cli.BoolFlag{
+			Name:   "monorepo",
+			Usage:  "Scan every independent project root of the directory in isolation.",
+			EnvVar: "BITRISE_INIT_MONOREPO",
+		},
+	}, append(append(append(append(append(append(append(append(externalScannersFlags, eventsFlags...), timeoutFlags...), mergeFlags...), stepLibFlags...), configTemplateFlags...), triggerMapFlags...), cacheFlags...), secretsFlags...)...),
 }
 
 func writeScanResult(scanResult models.ScanResultModel, outputDir string, format output.Format) (string, error) {
@@ -54,12 +71,35 @@ func writeScanResult(scanResult models.ScanResultModel, outputDir string, format
 	return output.WriteToFile(scanResult, format, pth)
 }
 
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func initConfig(c *cli.Context) error {
 	// Config
 	isCI := c.GlobalBool("ci")
 	searchDir := c.String("dir")
 	outputDir := c.String("output-dir")
 	formatStr := c.String("format")
+	onlyScanners := splitList(c.String("scanners"))
+	skipScanners := splitList(c.String("skip-scanners"))
+	isMonorepo := c.Bool("monorepo")
+	scannerTimeout := c.Duration(scannerTimeoutKey)
+	mergeIntoPth := c.String(mergeIntoKey)
+	mergePrefix := c.String(mergePrefixKey)
+	secretsTemplatePth := c.String(secretsTemplateKey)
+
+	closeEvents, err := setupEvents(c)
+	if err != nil {
+		return err
+	}
+	defer closeEvents()
 
 	if isCI {
 		log.Infoft(colorstring.Yellow("CI mode"))
@@ -67,7 +107,32 @@ func initConfig(c *cli.Context) error {
 	log.Infoft(colorstring.Yellowf("scan dir: %s", searchDir))
 	log.Infoft(colorstring.Yellowf("output dir: %s", outputDir))
 	log.Infoft(colorstring.Yellowf("output format: %s", formatStr))
-	fmt.Println()
+	if len(onlyScanners) > 0 {
+		log.Infoft(colorstring.Yellowf("scanners: %s", strings.Join(onlyScanners, ", ")))
+	}
+	if len(skipScanners) > 0 {
+		log.Infoft(colorstring.Yellowf("skip scanners: %s", strings.Join(skipScanners, ", ")))
+	}
+	if isMonorepo {
+		log.Infoft(colorstring.Yellow("monorepo mode"))
+	}
+	if mergeIntoPth != "" {
+		log.Infoft(colorstring.Yellowf("merge into: %s", mergeIntoPth))
+	}
+	if timeout := c.Duration(timeoutKey); timeout > 0 {
+		log.Infoft(colorstring.Yellowf("timeout: %s", timeout))
+	}
+	if scannerTimeout > 0 {
+		log.Infoft(colorstring.Yellowf("scanner timeout: %s", scannerTimeout))
+	}
+	if err := setupStepLib(c); err != nil {
+		return err
+	}
+	if err := setupConfigTemplate(c); err != nil {
+		return err
+	}
+	setupCache(c)
+	log.Printf("")
 
 	currentDir, err := pathutil.AbsPath("./")
 	if err != nil {
@@ -107,9 +172,54 @@ func initConfig(c *cli.Context) error {
 	if format != output.JSONFormat && format != output.YAMLFormat {
 		return fmt.Errorf("Not allowed output format (%s), options: [%s, %s]", format.String(), output.YAMLFormat.String(), output.JSONFormat.String())
 	}
+
+	if mergeIntoPth != "" {
+		if isCI {
+			return fmt.Errorf("Merging into an existing bitrise.yml requires user inputs, it is not supported in CI mode")
+		}
+		if isMonorepo {
+			return fmt.Errorf("Merging into an existing bitrise.yml is not supported in monorepo mode")
+		}
+		absMergeIntoPth, err := pathutil.AbsPath(mergeIntoPth)
+		if err != nil {
+			return fmt.Errorf("Failed to expand path (%s), error: %s", mergeIntoPth, err)
+		}
+		mergeIntoPth = absMergeIntoPth
+
+		if _, err := readBitriseConfig(mergeIntoPth); err != nil {
+			return err
+		}
+	}
 	// ---
 
-	scanResult := scanner.Config(searchDir)
+	if err := setupTriggerMap(c, searchDir, isMonorepo); err != nil {
+		return err
+	}
+
+	externalScanners, err := externalScanners(c)
+	if err != nil {
+		return err
+	}
+
+	projectScanners, skippedScanners, err := scanner.SelectScanners(scanner.WithExternalScanners(scanners.ActiveScanners, externalScanners), onlyScanners, skipScanners)
+	if err != nil {
+		return fmt.Errorf("Failed to select scanners, error: %s", err)
+	}
+
+	ctx, cancel := scanContext(c)
+	defer cancel()
+
+	if isMonorepo {
+		newScanners := func() []scanners.ScannerInterface {
+			// the scanner names were validated by the first selection
+			projectScanners, _, _ := scanner.SelectScanners(scanner.WithExternalScanners(scanners.NewActiveScanners(), newExternalScanners(externalScanners)), onlyScanners, skipScanners)
+			return projectScanners
+		}
+		return initMonorepoConfig(ctx, isCI, searchDir, outputDir, secretsTemplatePth, format, newScanners, scannerTimeout, skippedScanners)
+	}
+
+	scanResult := scanner.ConfigWithScanners(ctx, searchDir, projectScanners, scannerTimeout)
+	scanResult.SkippedScanners = skippedScanners
 
 	platforms := []string{}
 	for platform := range scanResult.PlatformOptionMap {
@@ -117,14 +227,14 @@ func initConfig(c *cli.Context) error {
 	}
 
 	if len(platforms) == 0 {
-		cmd := command.New("which", "tree")
-		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
-		if err != nil || out == "" {
+		if _, err := exec.LookPath("tree"); err != nil {
 			log.Errorft("tree not installed, can not list files")
 		} else {
-			fmt.Println()
-			cmd := command.NewWithStandardOuts("tree", ".", "-L", "3")
-			log.Printft("$ %s", cmd.PrintableCommandArgs())
+			log.Printf("")
+			cmd := exec.CommandContext(ctx, "tree", ".", "-L", "3")
+			cmd.Stdout = logWriter
+			cmd.Stderr = os.Stderr
+			log.Printft("$ %s", strings.Join(cmd.Args, " "))
 			if err := cmd.Run(); err != nil {
 				log.Errorft("Failed to list files in current directory, error: %s", err)
 			}
@@ -172,13 +282,22 @@ func initConfig(c *cli.Context) error {
 		}
 	}
 
+	if err := writeSecretsTemplate(scanResult.SecretFindings, secretsTemplatePth); err != nil {
+		return err
+	}
+
+	if mergeIntoPth != "" {
+		log.Printf("")
+		return mergeConfig(config, mergeIntoPth, mergePrefix, outputDir)
+	}
+
 	pth := path.Join(outputDir, "bitrise.yml")
 	outputPth, err := output.WriteToFile(config, format, pth)
 	if err != nil {
 		return fmt.Errorf("Failed to print result, error: %s", err)
 	}
 	log.Infoft("  bitrise.yml template: %s", outputPth)
-	fmt.Println()
+	log.Printf("")
 	// ---
 
 	return nil
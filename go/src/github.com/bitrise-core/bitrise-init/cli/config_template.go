@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const configTemplateKey = "config-template"
+
+var configTemplateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   configTemplateKey,
+		Usage:  "Path of a config template (yml), defining the steps of the generated workflows per platform and workflow, instead of the default steps.",
+		EnvVar: "BITRISE_INIT_CONFIG_TEMPLATE",
+	},
+}
+
+// setupConfigTemplate loads the config template set by the config template flag, which is used to generate the workflows.
+func setupConfigTemplate(c *cli.Context) error {
+	pth := c.String(configTemplateKey)
+	if pth == "" {
+		return nil
+	}
+
+	configTemplate, err := models.ReadConfigTemplate(pth)
+	if err != nil {
+		return fmt.Errorf("Failed to read config template, error: %s", err)
+	}
+	models.SetConfigTemplate(&configTemplate)
+
+	log.Infoft(colorstring.Yellowf("config template: %s", pth))
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitrise-core/bitrise-init/events"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const (
+	eventsFormatKey = "events-format"
+	eventsOutputKey = "events-output"
+
+	eventsFormatJSONLines = "jsonl"
+)
+
+var eventsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   eventsFormatKey,
+		Usage:  "Format of the scanner lifecycle events emitted during the scan, options [jsonl]. No events are emitted if empty.",
+		EnvVar: "BITRISE_INIT_EVENTS_FORMAT",
+	},
+	cli.StringFlag{
+		Name:   eventsOutputKey,
+		Usage:  "Path of the file to write the events to. Events are written to the stdout (and the log to the stderr) if empty.",
+		EnvVar: "BITRISE_INIT_EVENTS_OUTPUT",
+	},
+}
+
+// logWriter is the writer of the log and of the commands' output,
+// it is the stderr if the events are written to the stdout.
+var logWriter io.Writer = os.Stdout
+
+// setupEvents sets the events emitter based on the events flags,
+// the returned function has to be called to close the events output.
+func setupEvents(c *cli.Context) (func(), error) {
+	format := c.String(eventsFormatKey)
+	outputPth := c.String(eventsOutputKey)
+
+	if format == "" {
+		return func() {}, nil
+	}
+	if format != eventsFormatJSONLines {
+		return func() {}, fmt.Errorf("Not allowed events format (%s), options: [%s]", format, eventsFormatJSONLines)
+	}
+
+	if outputPth == "" || outputPth == "-" {
+		logWriter = os.Stderr
+		log.SetOutWriter(logWriter)
+		events.SetEmitter(events.NewJSONLinesEmitter(os.Stdout))
+		return func() {}, nil
+	}
+
+	file, err := os.Create(outputPth)
+	if err != nil {
+		return func() {}, fmt.Errorf("Failed to create events file (%s), error: %s", outputPth, err)
+	}
+	events.SetEmitter(events.NewJSONLinesEmitter(file))
+
+	return func() {
+		events.SetEmitter(nil)
+		if err := file.Close(); err != nil {
+			log.Warnft("Failed to close events file (%s), error: %s", outputPth, err)
+		}
+	}, nil
+}
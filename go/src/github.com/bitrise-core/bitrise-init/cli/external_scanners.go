@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/scanners/external"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const (
+	externalScannersPathKey   = "external-scanners-path"
+	externalScannersConfigKey = "external-scanners-config"
+)
+
+var externalScannersFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   externalScannersPathKey,
+		Usage:  "List of directories (separated like PATH) to search for external scanner executables, named: " + external.ExecutablePrefix + "NAME.",
+		EnvVar: "BITRISE_INIT_EXTERNAL_SCANNERS_PATH",
+	},
+	cli.StringFlag{
+		Name:   externalScannersConfigKey,
+		Usage:  "Path of the config file (yml or json), which lists the external scanners.",
+		EnvVar: "BITRISE_INIT_EXTERNAL_SCANNERS_CONFIG",
+	},
+}
+
+func externalScanners(c *cli.Context) ([]scanners.ScannerInterface, error) {
+	reservedNames := []string{}
+	for _, scanner := range scanners.ActiveScanners {
+		reservedNames = append(reservedNames, scanner.Name())
+	}
+
+	externals, err := external.Discover(c.String(externalScannersPathKey), c.String(externalScannersConfigKey), reservedNames)
+	if err != nil {
+		return []scanners.ScannerInterface{}, fmt.Errorf("Failed to collect external scanners, error: %s", err)
+	}
+
+	projectScanners := []scanners.ScannerInterface{}
+	for _, scanner := range externals {
+		log.Infoft(colorstring.Yellowf("external scanner: %s (%s)", scanner.Name(), scanner.Path))
+		projectScanners = append(projectScanners, scanner)
+	}
+
+	return projectScanners, nil
+}
+
+// newExternalScanners returns new instances of the given external scanners,
+// as the scanners store the state of the scan they run.
+func newExternalScanners(externalScanners []scanners.ScannerInterface) []scanners.ScannerInterface {
+	projectScanners := []scanners.ScannerInterface{}
+	for _, scanner := range externalScanners {
+		if externalScanner, ok := scanner.(*external.Scanner); ok {
+			scanner = external.NewScanner(externalScanner.ScannerName, externalScanner.Path, externalScanner.Args...)
+		}
+		projectScanners = append(projectScanners, scanner)
+	}
+	return projectScanners
+}
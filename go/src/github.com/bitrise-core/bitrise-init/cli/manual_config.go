@@ -28,7 +28,7 @@ var manualConfigCommand = cli.Command{
 		}
 		return nil
 	},
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		cli.StringFlag{
 			Name:  "output-dir",
 			Usage: "Directory to save scan results.",
@@ -39,7 +39,7 @@ var manualConfigCommand = cli.Command{
 			Usage: "Output format, options [json, yaml].",
 			Value: "yaml",
 		},
-	},
+	}, append(append(append(externalScannersFlags, stepLibFlags...), configTemplateFlags...), cacheFlags...)...),
 }
 
 func initManualConfig(c *cli.Context) error {
@@ -53,7 +53,14 @@ func initManualConfig(c *cli.Context) error {
 	}
 	log.Infoft(colorstring.Yellowf("output dir: %s", outputDir))
 	log.Infoft(colorstring.Yellowf("output format: %s", formatStr))
-	fmt.Println()
+	if err := setupStepLib(c); err != nil {
+		return err
+	}
+	if err := setupConfigTemplate(c); err != nil {
+		return err
+	}
+	setupCache(c)
+	log.Printf("")
 
 	currentDir, err := pathutil.AbsPath("./")
 	if err != nil {
@@ -87,7 +94,12 @@ func initManualConfig(c *cli.Context) error {
 	}
 	// ---
 
-	scanResult, err := scanner.ManualConfig()
+	externalScanners, err := externalScanners(c)
+	if err != nil {
+		return err
+	}
+
+	scanResult, err := scanner.ManualConfig(externalScanners...)
 	if err != nil {
 		return err
 	}
@@ -125,7 +137,7 @@ func initManualConfig(c *cli.Context) error {
 		return fmt.Errorf("Failed to print result, error: %s", err)
 	}
 	log.Infoft("  bitrise.yml template: %s", colorstring.Blue(outputPth))
-	fmt.Println()
+	log.Printf("")
 	// ---
 
 	return nil
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitrise-core/bitrise-init/scanner"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/goinp/goinp"
+	"github.com/urfave/cli"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	mergeIntoKey   = "merge-into"
+	mergePrefixKey = "merge-prefix"
+)
+
+var mergeFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  mergeIntoKey,
+		Usage: "Path of an existing bitrise.yml to merge the generated workflows into, instead of writing a new bitrise.yml.",
+	},
+	cli.StringFlag{
+		Name:  mergePrefixKey,
+		Usage: "Prefix of the merged workflow ids (<prefix>-<workflow id>), the generated config's project type if empty.",
+	},
+}
+
+// readBitriseConfig returns the content of the bitrise.yml at pth, after checking that it is a valid bitrise config.
+func readBitriseConfig(pth string) (string, error) {
+	if exist, err := pathutil.IsPathExists(pth); err != nil {
+		return "", err
+	} else if !exist {
+		return "", fmt.Errorf("bitrise.yml not found at: %s", pth)
+	}
+
+	content, err := fileutil.ReadStringFromFile(pth)
+	if err != nil {
+		return "", err
+	}
+
+	var config bitriseModels.BitriseDataModel
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return "", fmt.Errorf("failed to parse bitrise.yml (%s), error: %s", pth, err)
+	}
+
+	return content, nil
+}
+
+// mergeConfig merges the generated config into the bitrise.yml at mergeIntoPth.
+// The diff of the change is written next to the scan results and printed, the bitrise.yml is only changed if the user accepts it.
+func mergeConfig(generated bitriseModels.BitriseDataModel, mergeIntoPth, prefix, outputDir string) error {
+	content, err := readBitriseConfig(mergeIntoPth)
+	if err != nil {
+		return err
+	}
+
+	// the existing config is merged as a yaml.MapSlice, to keep the keys unknown to the bitrise models and the order of the keys
+	var existing yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(content), &existing); err != nil {
+		return fmt.Errorf("Failed to parse bitrise.yml (%s), error: %s", mergeIntoPth, err)
+	}
+
+	if prefix == "" {
+		prefix = scanner.MergePrefix(generated)
+	}
+
+	merged, warnings, err := scanner.MergeBitriseConfig(existing, generated, prefix)
+	if err != nil {
+		if _, ok := err.(scanner.WorkflowCollisionError); ok {
+			return fmt.Errorf("Failed to merge configs, error: %s, provide a different prefix with --%s", err, mergePrefixKey)
+		}
+		return fmt.Errorf("Failed to merge configs, error: %s", err)
+	}
+
+	for _, warning := range warnings {
+		log.Warnft(warning)
+	}
+
+	mergedBytes, err := yaml.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize merged config, error: %s", err)
+	}
+
+	diff, err := scanner.ConfigDiff(filepath.Base(mergeIntoPth), content, string(mergedBytes))
+	if err != nil {
+		return fmt.Errorf("Failed to create diff, error: %s", err)
+	}
+
+	diffPth := filepath.Join(outputDir, "bitrise.yml.diff")
+	if err := fileutil.WriteStringToFile(diffPth, diff); err != nil {
+		return fmt.Errorf("Failed to write diff, error: %s", err)
+	}
+
+	log.Infoft("Changes of %s:", mergeIntoPth)
+	log.Printf("%s", diff)
+	log.Printft("  diff: %s", diffPth)
+	log.Printf("")
+
+	apply, err := goinp.AskForBool(fmt.Sprintf("Write the changes to %s?", mergeIntoPth))
+	if err != nil {
+		return err
+	}
+	if !apply {
+		log.Warnft("%s not changed", mergeIntoPth)
+		return nil
+	}
+
+	if err := fileutil.WriteBytesToFile(mergeIntoPth, mergedBytes); err != nil {
+		return fmt.Errorf("Failed to write merged config, error: %s", err)
+	}
+	log.Doneft("Merged into: %s", mergeIntoPth)
+
+	return nil
+}
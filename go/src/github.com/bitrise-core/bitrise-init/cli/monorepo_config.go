@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/output"
+	"github.com/bitrise-core/bitrise-init/scanner"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+)
+
+func initMonorepoConfig(ctx context.Context, isCI bool, searchDir, outputDir, secretsTemplatePth string, format output.Format, newScanners func() []scanners.ScannerInterface, scannerTimeout time.Duration, skippedScanners []string) error {
+	scanResult, err := scanner.MonorepoConfig(ctx, searchDir, newScanners, scannerTimeout)
+	if err != nil {
+		return fmt.Errorf("Failed to scan project roots, error: %s", err)
+	}
+	scanResult.SkippedScanners = skippedScanners
+
+	if len(scanResult.ProjectResultMap) == 0 {
+		scanResult.Errors = append(scanResult.Errors, "No known platform detected")
+	}
+
+	// Write output to files
+	if isCI || len(scanResult.ProjectResultMap) == 0 {
+		log.Infoft("Saving outputs:")
+
+		outputPth, err := output.WriteToFile(scanResult, format, path.Join(outputDir, "result"))
+		if err != nil {
+			return fmt.Errorf("Failed to write output, error: %s", err)
+		}
+
+		log.Printft("  scan result: %s", outputPth)
+
+		if len(scanResult.ProjectResultMap) == 0 {
+			return fmt.Errorf("No known platform detected")
+		}
+		return nil
+	}
+	// ---
+
+	// Select options
+	log.Infoft("Collecting inputs:")
+
+	roots := []string{}
+	for root := range scanResult.ProjectResultMap {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	projectConfigMap := map[string]bitriseModels.BitriseDataModel{}
+	secretFindings := []models.SecretFindingModel{}
+	for _, root := range roots {
+		projectResult := scanResult.ProjectResultMap[root]
+		for _, finding := range projectResult.SecretFindings {
+			finding.Path = filepath.Join(root, finding.Path)
+			secretFindings = append(secretFindings, finding)
+		}
+
+		if len(projectResult.PlatformOptionMap) == 0 {
+			log.Warnft("No config available for project root: %s", root)
+			continue
+		}
+
+		log.Printf("")
+		log.Infoft(colorstring.Bluef("Project root: %s", root))
+
+		config, err := scanner.AskForConfig(projectResult)
+		if err != nil {
+			return err
+		}
+		projectConfigMap[root] = config
+	}
+
+	config, err := scanner.MonorepoBitriseConfig(projectConfigMap)
+	if err != nil {
+		return fmt.Errorf("Failed to merge project configs, error: %s", err)
+	}
+
+	outputPth, err := output.WriteToFile(config, format, path.Join(outputDir, "bitrise.yml"))
+	if err != nil {
+		return fmt.Errorf("Failed to print result, error: %s", err)
+	}
+	log.Infoft("  bitrise.yml template: %s", outputPth)
+
+	if err := writeSecretsTemplate(secretFindings, secretsTemplatePth); err != nil {
+		return err
+	}
+	log.Printf("")
+	// ---
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanner"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const secretsTemplateKey = "secrets-template"
+
+var secretsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  secretsTemplateKey,
+		Usage: "Path to write the .bitrise.secrets.yml template of the committed secrets' suggested secret envs to, nothing is written if empty. Keep it out of the repository.",
+	},
+}
+
+// writeSecretsTemplate writes the .bitrise.secrets.yml template of the secret findings' suggested secret envs to the pth,
+// if the pth is empty only the suggested secret env keys are logged, nothing is written if there are no findings.
+func writeSecretsTemplate(findings []models.SecretFindingModel, pth string) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	template := scanner.SecretEnvsTemplate(findings)
+
+	if pth == "" {
+		keys := []string{}
+		for _, env := range template.Envs {
+			key, _, err := env.GetKeyValuePair()
+			if err != nil {
+				return fmt.Errorf("Failed to read secret env, error: %s", err)
+			}
+			keys = append(keys, key)
+		}
+		log.Warnft("Define the suggested secret envs as secrets: %s", strings.Join(keys, ", "))
+		log.Printft("  use --%s to write a .bitrise.secrets.yml template of them", secretsTemplateKey)
+		return nil
+	}
+
+	content, err := yaml.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize secrets template, error: %s", err)
+	}
+	if err := fileutil.WriteBytesToFileWithPermission(pth, content, 0600); err != nil {
+		return fmt.Errorf("Failed to write secrets template, error: %s", err)
+	}
+	log.Infoft("  .bitrise.secrets.yml template: %s", pth)
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const (
+	stepLibKey              = "steplib"
+	stepLibVersionPolicyKey = "steplib-version-policy"
+)
+
+var stepLibFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   stepLibKey,
+		Usage:  "Path of a local steplib spec (spec.json) or a cloned steplib dir, to resolve the step versions from and to validate the generated step inputs against. The built-in step versions are used without validation if empty.",
+		EnvVar: "BITRISE_INIT_STEPLIB",
+	},
+	cli.StringFlag{
+		Name:   stepLibVersionPolicyKey,
+		Usage:  "How the step versions are resolved from the steplib, options [pinned, latest-minor].",
+		Value:  string(steps.VersionPolicyPinned),
+		EnvVar: "BITRISE_INIT_STEPLIB_VERSION_POLICY",
+	},
+}
+
+// setupStepLib loads the steplib set by the steplib flags, which is used to resolve and validate the generated steps.
+func setupStepLib(c *cli.Context) error {
+	pth := c.String(stepLibKey)
+	if pth == "" {
+		return nil
+	}
+
+	policy, err := steps.ParseVersionPolicy(c.String(stepLibVersionPolicyKey))
+	if err != nil {
+		return fmt.Errorf("Failed to parse steplib version policy, error: %s", err)
+	}
+
+	stepLib, err := steps.OpenStepLib(pth)
+	if err != nil {
+		return fmt.Errorf("Failed to read steplib (%s), error: %s", pth, err)
+	}
+	steps.SetStepLib(&stepLib, policy)
+
+	log.Infoft(colorstring.Yellowf("steplib: %s (%s)", pth, policy))
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/output"
+	"github.com/bitrise-core/bitrise-init/submit"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+var submitCommand = cli.Command{
+	Name:  "submit",
+	Usage: "Submits the scan result to the given url.",
+	Action: func(c *cli.Context) error {
+		if err := submitResult(c); err != nil {
+			log.Errorft(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "result",
+			Usage: "Path of the scan result (json) to submit.",
+			Value: filepath.Join(defaultScanResultDir, "result.json"),
+		},
+		cli.StringFlag{
+			Name:   "url",
+			Usage:  "Url to POST the scan result to.",
+			EnvVar: "BITRISE_INIT_SUBMIT_URL",
+		},
+		cli.StringFlag{
+			Name:   "api-token",
+			Usage:  "API token sent in the Authorization header. Prefer the env var, to keep the token out of the process list.",
+			EnvVar: "BITRISE_INIT_SUBMIT_API_TOKEN",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Timeout of a single request.",
+			Value: 30 * time.Second,
+		},
+		cli.IntFlag{
+			Name:  "retries",
+			Usage: "Number of retries after a network error or a server (5xx) error.",
+			Value: 3,
+		},
+		cli.DurationFlag{
+			Name:  "retry-wait",
+			Usage: "Wait before the first retry, doubled before every further retry.",
+			Value: 2 * time.Second,
+		},
+		cli.BoolFlag{
+			Name:  "gzip",
+			Usage: "Compress the request body with gzip.",
+		},
+		cli.StringFlag{
+			Name:  "receipt",
+			Usage: "Path to write the submission receipt (json) to, including the server response. Written next to the scan result if empty.",
+		},
+	},
+}
+
+func submitResult(c *cli.Context) error {
+	resultPth := c.String("result")
+	receiptPth := c.String("receipt")
+	opts := submit.OptionsModel{
+		URL:       c.String("url"),
+		APIToken:  c.String("api-token"),
+		Timeout:   c.Duration("timeout"),
+		Retries:   c.Int("retries"),
+		RetryWait: c.Duration("retry-wait"),
+		Gzip:      c.Bool("gzip"),
+	}
+
+	if opts.URL == "" {
+		return fmt.Errorf("Submit url not provided")
+	}
+	if strings.Contains(opts.URL, "api_token=") {
+		log.Warnft("The submit url contains an api_token query parameter, use the --api-token flag instead")
+	}
+	if receiptPth == "" {
+		receiptPth = filepath.Join(filepath.Dir(resultPth), "receipt")
+	} else {
+		receiptPth = strings.TrimSuffix(receiptPth, filepath.Ext(receiptPth))
+	}
+
+	log.Infoft(colorstring.Yellowf("scan result: %s", resultPth))
+	log.Infoft(colorstring.Yellowf("submit url: %s", submitURLForLog(opts.URL)))
+	log.Printf("")
+
+	log.Infoft("Submitting scan result...")
+
+	receipt, submitErr := submit.ResultFile(context.Background(), resultPth, opts)
+	if receipt.Attempts > 0 {
+		pth, err := output.WriteToFile(receipt, output.JSONFormat, receiptPth)
+		if err != nil {
+			log.Warnft("Failed to write receipt, error: %s", err)
+		} else {
+			log.Printft("  receipt: %s", pth)
+		}
+	}
+
+	if submitErr != nil {
+		return fmt.Errorf("Failed to submit scan result, error: %s", submitErr)
+	}
+
+	log.Doneft("Submitted, status code: %d", receipt.StatusCode)
+
+	return nil
+}
+
+func submitURLForLog(url string) string {
+	if idx := strings.Index(url, "?"); idx != -1 {
+		return url[:idx] + "?[REDACTED]"
+	}
+	return url
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const (
+	timeoutKey        = "timeout"
+	scannerTimeoutKey = "scanner-timeout"
+)
+
+var timeoutFlags = []cli.Flag{
+	cli.DurationFlag{
+		Name:   timeoutKey,
+		Usage:  "Deadline of the whole scan (like: 10m), the scanners not finished by then are reported as timed out. No deadline if 0.",
+		EnvVar: "BITRISE_INIT_TIMEOUT",
+	},
+	cli.DurationFlag{
+		Name:   scannerTimeoutKey,
+		Usage:  "Timeout of a single scanner (like: 2m), a scanner not finished in time is reported as timed out. No timeout if 0.",
+		EnvVar: "BITRISE_INIT_SCANNER_TIMEOUT",
+	},
+}
+
+// scanContext returns the context of the scan based on the timeout flags,
+// the context is canceled on interrupt, so that the running scanner's commands are killed.
+// The returned function has to be called to release the context's resources.
+func scanContext(c *cli.Context) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout := c.Duration(timeoutKey); timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	interruptCh := make(chan os.Signal, 1)
+	signal.Notify(interruptCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-interruptCh:
+			log.Warnft("Interrupted, canceling the scan...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(interruptCh)
+		cancel()
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/utility"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/urfave/cli"
+)
+
+const (
+	deployBranchKey          = "deploy-branch"
+	deployTagPatternKey      = "deploy-tag-pattern"
+	detectReleaseBranchesKey = "detect-release-branches"
+
+	deployBranchAuto = "auto"
+)
+
+var triggerMapFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   deployBranchKey,
+		Usage:  "Pushes to this branch trigger the deploy workflow, [auto] reads the default branch from the scanned git repository (.git/refs/remotes/origin/HEAD or .git/HEAD).",
+		EnvVar: "BITRISE_INIT_DEPLOY_BRANCH",
+	},
+	cli.StringFlag{
+		Name:   deployTagPatternKey,
+		Usage:  "Tags matching this pattern (like: v*) trigger the deploy workflow.",
+		EnvVar: "BITRISE_INIT_DEPLOY_TAG_PATTERN",
+	},
+	cli.BoolFlag{
+		Name:   detectReleaseBranchesKey,
+		Usage:  "Pushes to the release branches trigger the deploy workflow, the release branch patterns (like: release/*) are detected from the branches of the scanned git repository.",
+		EnvVar: "BITRISE_INIT_DETECT_RELEASE_BRANCHES",
+	},
+}
+
+// setupTriggerMap sets the trigger map options of the generated configs based on the trigger map flags,
+// the git repository of the searchDir is read for the default and the release branches.
+func setupTriggerMap(c *cli.Context, searchDir string, isMonorepo bool) error {
+	options := models.TriggerMapOptionsModel{
+		DeployBranch:     c.String(deployBranchKey),
+		DeployTagPattern: c.String(deployTagPatternKey),
+	}
+	detectReleaseBranches := c.Bool(detectReleaseBranchesKey)
+
+	if options.DeployBranch == "" && options.DeployTagPattern == "" && !detectReleaseBranches {
+		return nil
+	}
+	if isMonorepo {
+		return fmt.Errorf("Triggering the deploy workflow is not supported in monorepo mode")
+	}
+
+	if options.DeployBranch == deployBranchAuto || detectReleaseBranches {
+		gitDir, err := utility.GitDir(searchDir)
+		if err != nil {
+			return fmt.Errorf("Failed to find git repository, error: %s", err)
+		}
+
+		if options.DeployBranch == deployBranchAuto {
+			branch, err := utility.GitDefaultBranch(gitDir)
+			if err != nil {
+				return fmt.Errorf("Failed to read default branch, error: %s", err)
+			}
+			options.DeployBranch = branch
+		}
+
+		if detectReleaseBranches {
+			branches, err := utility.GitBranches(gitDir)
+			if err != nil {
+				return fmt.Errorf("Failed to list branches, error: %s", err)
+			}
+			options.ReleaseBranchPatterns = utility.ReleaseBranchPatterns(branches)
+			if len(options.ReleaseBranchPatterns) == 0 {
+				log.Warnft("No release branch found")
+			}
+		}
+	}
+
+	if options.DeployBranch != "" {
+		log.Infoft(colorstring.Yellowf("deploy branch: %s", options.DeployBranch))
+	}
+	if len(options.ReleaseBranchPatterns) > 0 {
+		log.Infoft(colorstring.Yellowf("release branches: %s", strings.Join(options.ReleaseBranchPatterns, ", ")))
+	}
+	if options.DeployTagPattern != "" {
+		log.Infoft(colorstring.Yellowf("deploy tag pattern: %s", options.DeployTagPattern))
+	}
+	log.Printf("")
+
+	models.SetTriggerMapOptions(options)
+
+	return nil
+}
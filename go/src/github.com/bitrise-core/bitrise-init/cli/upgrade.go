@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/upgrade"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/urfave/cli"
+)
+
+var upgradeCommand = cli.Command{
+	Name:  "upgrade",
+	Usage: "Upgrades the pinned versions of the known steps in an existing bitrise.yml.",
+	Action: func(c *cli.Context) error {
+		if err := upgradeConfig(c); err != nil {
+			log.Errorft(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "config",
+			Usage: "Path of the bitrise.yml to upgrade.",
+			Value: "bitrise.yml",
+		},
+		cli.StringFlag{
+			Name:  "steplib",
+			Usage: "Path of a steplib spec (spec.json) or a cloned steplib dir, to upgrade the steps to its latest step versions, instead of the versions used in the generated configs.",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "Path to write the upgraded bitrise.yml to, <config>.upgraded.yml if empty.",
+		},
+		cli.BoolFlag{
+			Name:  "in-place",
+			Usage: "Overwrite the upgraded bitrise.yml, instead of writing the changes to the output.",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Only print the changes, do not write the upgraded bitrise.yml.",
+		},
+	},
+}
+
+func upgradeConfig(c *cli.Context) error {
+	configPth := c.String("config")
+	stepLibPth := c.String("steplib")
+	outputPth := c.String("output")
+	isInPlace := c.Bool("in-place")
+	isDryRun := c.Bool("dry-run")
+
+	if isInPlace && outputPth != "" {
+		return fmt.Errorf("Only one of --output and --in-place can be set")
+	}
+
+	absConfigPth, err := pathutil.AbsPath(configPth)
+	if err != nil {
+		return fmt.Errorf("Failed to expand path (%s), error: %s", configPth, err)
+	}
+	configPth = absConfigPth
+
+	if isInPlace {
+		outputPth = configPth
+	} else if outputPth == "" {
+		outputPth = strings.TrimSuffix(configPth, filepath.Ext(configPth)) + ".upgraded.yml"
+	}
+
+	log.Infoft(colorstring.Yellowf("config: %s", configPth))
+	if stepLibPth != "" {
+		log.Infoft(colorstring.Yellowf("steplib: %s", stepLibPth))
+	}
+	log.Printf("")
+
+	content, err := readBitriseConfig(configPth)
+	if err != nil {
+		return err
+	}
+
+	catalog := upgrade.DefaultCatalog()
+	if stepLibPth != "" {
+		if catalog, err = upgrade.CatalogFromStepLib(stepLibPth); err != nil {
+			return fmt.Errorf("Failed to read steplib, error: %s", err)
+		}
+	}
+
+	upgraded, changes, err := upgrade.Upgrade(content, catalog)
+	if err != nil {
+		return fmt.Errorf("Failed to upgrade config, error: %s", err)
+	}
+
+	if len(changes) == 0 {
+		log.Doneft("All the known steps are up to date")
+		return nil
+	}
+
+	log.Infoft("Changelog:")
+	for _, change := range changes {
+		log.Printft("- %s", change)
+	}
+	log.Printf("")
+
+	if isDryRun {
+		return nil
+	}
+
+	if err := fileutil.WriteStringToFile(outputPth, upgraded); err != nil {
+		return fmt.Errorf("Failed to write upgraded config, error: %s", err)
+	}
+	log.Doneft("Upgraded config: %s", outputPth)
+
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package events emits machine readable scanner lifecycle events while the scan is running.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/go-utils/log"
+)
+
+// Type ...
+type Type string
+
+// Event types
+const (
+	ScanStarted      Type = "scan_started"
+	ScannerStarted   Type = "scanner_started"
+	ScannerExcluded  Type = "scanner_excluded"
+	PlatformDetected Type = "platform_detected"
+	OptionsFound     Type = "options_found"
+	Warning          Type = "warning"
+	Error            Type = "error"
+	ScannerFinished  Type = "scanner_finished"
+	ScanFinished     Type = "scan_finished"
+)
+
+// Results of the scanner_finished event
+const (
+	ResultDetected    = "detected"
+	ResultNotDetected = "not_detected"
+	ResultFailed      = "failed"
+	ResultTimedOut    = "timed_out"
+	ResultCanceled    = "canceled"
+)
+
+// Event ...
+type Event struct {
+	Type      Type      `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	SearchDir string    `json:"search_dir,omitempty"`
+	Scanner   string    `json:"scanner,omitempty"`
+	// Configs are the configs reachable through the found options.
+	Configs []string `json:"configs,omitempty"`
+	// Message is the warning or error message.
+	Message string `json:"message,omitempty"`
+	// Result and DurationMS are set for the scanner_finished and scan_finished events.
+	Result     string  `json:"result,omitempty"`
+	DurationMS float64 `json:"duration_ms,omitempty"`
+}
+
+// Emitter ...
+type Emitter interface {
+	Emit(event Event) error
+}
+
+var (
+	emitter Emitter
+	now     = time.Now
+)
+
+// SetEmitter sets the emitter of the events, events are dropped if the emitter is nil.
+func SetEmitter(e Emitter) {
+	emitter = e
+}
+
+// Emit timestamps and emits the event with the emitter set by SetEmitter.
+func Emit(event Event) {
+	if emitter == nil {
+		return
+	}
+
+	event.Timestamp = now().UTC()
+	if err := emitter.Emit(event); err != nil {
+		log.Warnft("Failed to emit %s event, error: %s", event.Type, err)
+	}
+}
+
+// Since returns the milliseconds (with microsecond precision) elapsed since start, to use as an event's duration.
+func Since(start time.Time) float64 {
+	return float64(now().Sub(start)/time.Microsecond) / 1000
+}
+
+// Now returns the current time, to measure durations of the events.
+func Now() time.Time {
+	return now()
+}
+
+// JSONLinesEmitter writes the events as JSON lines: one JSON object per line.
+type JSONLinesEmitter struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+// NewJSONLinesEmitter ...
+func NewJSONLinesEmitter(writer io.Writer) *JSONLinesEmitter {
+	return &JSONLinesEmitter{writer: writer}
+}
+
+// Emit ...
+func (e *JSONLinesEmitter) Emit(event Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	_, err = fmt.Fprintln(e.writer, string(b))
+	return err
+}
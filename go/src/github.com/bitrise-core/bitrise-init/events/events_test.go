@@ -0,0 +1,63 @@
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Emit(event Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestEmit(t *testing.T) {
+	defer SetEmitter(nil)
+	defer func() { now = time.Now }()
+
+	currentTime := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return currentTime }
+
+	t.Log("no emitter")
+	{
+		SetEmitter(nil)
+		Emit(Event{Type: ScanStarted})
+	}
+
+	t.Log("timestamps the events")
+	{
+		r := &recorder{}
+		SetEmitter(r)
+
+		Emit(Event{Type: ScannerStarted, Scanner: "android"})
+		require.Equal(t, []Event{{Type: ScannerStarted, Scanner: "android", Timestamp: currentTime}}, r.events)
+	}
+
+	t.Log("duration")
+	{
+		start := Now()
+		currentTime = currentTime.Add(1500*time.Millisecond + 250*time.Microsecond)
+		require.Equal(t, 1500.25, Since(start))
+	}
+}
+
+func TestJSONLinesEmitter(t *testing.T) {
+	var buff bytes.Buffer
+	emitter := NewJSONLinesEmitter(&buff)
+
+	timestamp := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, emitter.Emit(Event{Type: ScannerStarted, Timestamp: timestamp, Scanner: "ios"}))
+	require.NoError(t, emitter.Emit(Event{Type: ScannerFinished, Timestamp: timestamp, Scanner: "ios", Result: ResultDetected, DurationMS: 42.5}))
+
+	require.Equal(t, []string{
+		`{"type":"scanner_started","timestamp":"2018-03-01T10:00:00Z","scanner":"ios"}`,
+		`{"type":"scanner_finished","timestamp":"2018-03-01T10:00:00Z","scanner":"ios","result":"detected","duration_ms":42.5}`,
+	}, strings.Split(strings.TrimSpace(buff.String()), "\n"))
+}
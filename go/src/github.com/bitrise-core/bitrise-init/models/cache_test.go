@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	stepmanModels "github.com/bitrise-io/stepman/models"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestAddCachePaths(t *testing.T) {
+	newConfigBuilder := func() *ConfigBuilderModel {
+		configBuilder := NewConfigBuilder(nil)
+		configBuilder.AppendDependencyStepList(bitriseModels.StepListItemModel{"cocoapods-install@1.7.2": stepmanModels.StepModel{}})
+		configBuilder.AppendDeployStepList(bitriseModels.StepListItemModel{"deploy-to-bitrise-io@1.3.10": stepmanModels.StepModel{}})
+		configBuilder.AddCachePaths("./Pods -> ./Podfile.lock", "./Carthage -> ./Cartfile.resolved")
+		configBuilder.AddCachePaths("./Pods -> ./Podfile.lock")
+		return configBuilder
+	}
+
+	t.Log("cache enabled")
+	{
+		config, err := newConfigBuilder().Generate("ios")
+		require.NoError(t, err)
+
+		workflowBytes, err := yaml.Marshal(config.Workflows["primary"])
+		require.NoError(t, err)
+		require.Equal(t, `steps:
+- cache-pull@2.0.1: {}
+- cocoapods-install@1.7.2: {}
+- deploy-to-bitrise-io@1.3.10: {}
+- cache-push@2.0.5:
+    inputs:
+    - cache_paths: |-
+        ./Pods -> ./Podfile.lock
+        ./Carthage -> ./Cartfile.resolved
+`, string(workflowBytes))
+	}
+
+	t.Log("cache disabled")
+	{
+		SetCacheEnabled(false)
+		defer SetCacheEnabled(true)
+
+		config, err := newConfigBuilder().Generate("ios")
+		require.NoError(t, err)
+
+		workflowBytes, err := yaml.Marshal(config.Workflows["primary"])
+		require.NoError(t, err)
+		require.Equal(t, `steps:
+- cocoapods-install@1.7.2: {}
+- deploy-to-bitrise-io@1.3.10: {}
+`, string(workflowBytes))
+	}
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// ConfigTemplateWildcard matches any platform (project type) or workflow in the config template.
+	ConfigTemplateWildcard = "*"
+	// ScannerStepsPlaceholder is the step list item of the config template, which is replaced with the scanner provided steps of the stage.
+	// The scanner provided steps are appended to the stage, if the stage has no placeholder.
+	ScannerStepsPlaceholder = "scanner-steps"
+)
+
+// WorkflowTemplateModel defines the steps of a workflow, the scanner provided steps are added to its stages.
+// The step input values are text/templates, see: ConfigTemplateDataModel.
+type WorkflowTemplateModel struct {
+	PrepareSteps    []bitriseModels.StepListItemModel `yaml:"prepare_steps,omitempty"`
+	DependencySteps []bitriseModels.StepListItemModel `yaml:"dependency_steps,omitempty"`
+	MainSteps       []bitriseModels.StepListItemModel `yaml:"main_steps,omitempty"`
+	DeploySteps     []bitriseModels.StepListItemModel `yaml:"deploy_steps,omitempty"`
+}
+
+// ConfigTemplateModel replaces the default steps of the generated workflows,
+// Platforms maps the project types to the workflow ids to the workflow templates, both keys can be ConfigTemplateWildcard.
+type ConfigTemplateModel struct {
+	Platforms map[string]map[string]WorkflowTemplateModel `yaml:"platforms"`
+}
+
+// ConfigTemplateDataModel is the data of the config template's input value templates,
+// the input function returns the value of a scanner provided step's input: {{ input "gradle-runner" "gradle_file" }}.
+type ConfigTemplateDataModel struct {
+	ProjectType string
+	WorkflowID  string
+}
+
+var configTemplate *ConfigTemplateModel
+
+// SetConfigTemplate sets the config template used by the default workflows of the ConfigBuilderModel,
+// the default steps are used if the template is nil.
+func SetConfigTemplate(template *ConfigTemplateModel) {
+	configTemplate = template
+}
+
+// ReadConfigTemplate reads and validates the config template (yml) file.
+func ReadConfigTemplate(pth string) (ConfigTemplateModel, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return ConfigTemplateModel{}, err
+	}
+
+	var configTemplate ConfigTemplateModel
+	if err := yaml.Unmarshal(content, &configTemplate); err != nil {
+		return ConfigTemplateModel{}, fmt.Errorf("failed to parse config template (%s), error: %s", pth, err)
+	}
+
+	if len(configTemplate.Platforms) == 0 {
+		return ConfigTemplateModel{}, fmt.Errorf("config template (%s) has no platforms", pth)
+	}
+
+	for platform, workflowTemplates := range configTemplate.Platforms {
+		for workflowID, workflowTemplate := range workflowTemplates {
+			for _, stepList := range workflowTemplate.stages() {
+				for _, stepListItem := range stepList {
+					if _, _, err := renderStepListItem(stepListItem, nil, nil); err != nil {
+						return ConfigTemplateModel{}, fmt.Errorf("invalid step in the config template (platform: %s, workflow: %s), error: %s", platform, workflowID, err)
+					}
+				}
+			}
+		}
+	}
+
+	return configTemplate, nil
+}
+
+// workflowTemplate returns the most specific workflow template of the project type and workflow.
+func (configTemplate ConfigTemplateModel) workflowTemplate(projectType, workflowID string) (WorkflowTemplateModel, bool) {
+	for _, platform := range []string{projectType, ConfigTemplateWildcard} {
+		for _, workflow := range []string{workflowID, ConfigTemplateWildcard} {
+			if workflowTemplate, ok := configTemplate.Platforms[platform][workflow]; ok {
+				return workflowTemplate, true
+			}
+		}
+	}
+	return WorkflowTemplateModel{}, false
+}
+
+func (workflowTemplate WorkflowTemplateModel) stages() [][]bitriseModels.StepListItemModel {
+	return [][]bitriseModels.StepListItemModel{
+		workflowTemplate.PrepareSteps,
+		workflowTemplate.DependencySteps,
+		workflowTemplate.MainSteps,
+		workflowTemplate.DeploySteps,
+	}
+}
+
+// render returns the step list of the template, with the scanner provided steps of the builder.
+func (workflowTemplate WorkflowTemplateModel) render(builder *workflowBuilderModel, data ConfigTemplateDataModel) ([]bitriseModels.StepListItemModel, error) {
+	funcs := template.FuncMap{
+		"input": func(stepID, key string) (string, error) {
+			return scannerStepInput(builder.stepList(), stepID, key)
+		},
+	}
+
+	scannerStages := builder.stages()
+
+	stepList := []bitriseModels.StepListItemModel{}
+	for i, stage := range workflowTemplate.stages() {
+		hasPlaceholder := false
+		for _, stepListItem := range stage {
+			rendered, isPlaceholder, err := renderStepListItem(stepListItem, funcs, data)
+			if err != nil {
+				return nil, err
+			}
+			if isPlaceholder {
+				hasPlaceholder = true
+				stepList = append(stepList, scannerStages[i]...)
+				continue
+			}
+			stepList = append(stepList, rendered)
+		}
+		if !hasPlaceholder {
+			stepList = append(stepList, scannerStages[i]...)
+		}
+	}
+
+	return stepList, nil
+}
+
+// renderStepListItem executes the input value templates of the step,
+// the templates are only parsed (to validate them) if the funcs are nil.
+func renderStepListItem(stepListItem bitriseModels.StepListItemModel, funcs template.FuncMap, data interface{}) (bitriseModels.StepListItemModel, bool, error) {
+	compositeID, step, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+	if err != nil {
+		return nil, false, err
+	}
+	if compositeID == ScannerStepsPlaceholder {
+		return nil, true, nil
+	}
+
+	parseFuncs := template.FuncMap{"input": func(stepID, key string) (string, error) { return "", nil }}
+
+	inputs := []envmanModels.EnvironmentItemModel{}
+	for _, input := range step.Inputs {
+		rendered := envmanModels.EnvironmentItemModel{}
+		for key, value := range input {
+			str, ok := value.(string)
+			if key == envmanModels.OptionsKey || !ok {
+				rendered[key] = value
+				continue
+			}
+
+			tmpl, err := template.New(key).Funcs(parseFuncs).Option("missingkey=error").Parse(str)
+			if err != nil {
+				return nil, false, fmt.Errorf("step (%s) has an invalid input (%s), error: %s", compositeID, key, err)
+			}
+			if funcs == nil {
+				rendered[key] = value
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := tmpl.Funcs(funcs).Execute(&buf, data); err != nil {
+				return nil, false, fmt.Errorf("failed to render input (%s) of step (%s), error: %s", key, compositeID, err)
+			}
+			rendered[key] = buf.String()
+		}
+		inputs = append(inputs, rendered)
+	}
+	if len(step.Inputs) > 0 {
+		step.Inputs = inputs
+	}
+
+	return bitriseModels.StepListItemModel{compositeID: step}, false, nil
+}
+
+// scannerStepInput returns the value of the input of the first scanner provided step with the given id.
+func scannerStepInput(stepList []bitriseModels.StepListItemModel, stepID, key string) (string, error) {
+	for _, stepListItem := range stepList {
+		compositeID, step, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+		if err != nil {
+			return "", err
+		}
+		if strings.Split(compositeID, "@")[0] != stepID {
+			continue
+		}
+
+		for _, input := range step.Inputs {
+			inputKey, value, err := input.GetKeyValuePair()
+			if err != nil {
+				return "", err
+			}
+			if inputKey == key {
+				return value, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no scanner provided step (%s) with input: %s", stepID, key)
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/steps"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	stepmanModels "github.com/bitrise-io/stepman/models"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const configTemplateContent = `platforms:
+  "*":
+    "*":
+      prepare_steps:
+      - git-clone@4.0.11: {}
+      - secrets-fetch@1.0.0: {}
+      deploy_steps:
+      - deploy-to-bitrise-io@1.3.10: {}
+      - scanner-steps: {}
+      - slack@2.7.0:
+          inputs:
+          - message: "{{ .ProjectType }} {{ .WorkflowID }} finished"
+  android:
+    deploy:
+      prepare_steps:
+      - git-clone@4.0.11: {}
+      dependency_steps:
+      - cache-pull@2.0.1: {}
+      main_steps:
+      - scanner-steps: {}
+      - script@1.1.5:
+          inputs:
+          - content: echo {{ input "gradle-runner" "gradle_file" }}
+            opts:
+              is_expand: false
+`
+
+func writeConfigTemplate(t *testing.T, content string) string {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("config_template")
+	require.NoError(t, err)
+
+	pth := filepath.Join(tmpDir, "template.yml")
+	require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	return pth
+}
+
+func TestReadConfigTemplate(t *testing.T) {
+	t.Log("valid template")
+	{
+		configTemplate, err := ReadConfigTemplate(writeConfigTemplate(t, configTemplateContent))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(configTemplate.Platforms))
+		require.Equal(t, 3, len(configTemplate.Platforms["*"]["*"].DeploySteps))
+	}
+
+	t.Log("invalid input template")
+	{
+		_, err := ReadConfigTemplate(writeConfigTemplate(t, `platforms:
+  "*":
+    "*":
+      prepare_steps:
+      - script@1.1.5:
+          inputs:
+          - content: "{{ .ProjectType"
+`))
+		require.Error(t, err)
+	}
+
+	t.Log("no platforms")
+	{
+		_, err := ReadConfigTemplate(writeConfigTemplate(t, "platforms: {}\n"))
+		require.Error(t, err)
+	}
+}
+
+func TestGenerateWithConfigTemplate(t *testing.T) {
+	configTemplate, err := ReadConfigTemplate(writeConfigTemplate(t, configTemplateContent))
+	require.NoError(t, err)
+
+	SetConfigTemplate(&configTemplate)
+	defer SetConfigTemplate(nil)
+
+	configBuilder := NewDefaultConfigBuilder()
+	configBuilder.AppendMainStepList(bitriseModels.StepListItemModel{"gradle-runner@1.8.0": stepmanModels.StepModel{}})
+	configBuilder.AddDefaultWorkflowBuilder(DeployWorkflowID)
+	configBuilder.AppendMainStepListTo(DeployWorkflowID, bitriseModels.StepListItemModel{"gradle-runner@1.8.0": stepmanModels.StepModel{
+		Inputs: []envmanModels.EnvironmentItemModel{{"gradle_file": "$GRADLE_BUILD_FILE_PATH"}},
+	}})
+	configBuilder.AppendDeployStepListTo(DeployWorkflowID, bitriseModels.StepListItemModel{"cache-push@2.0.5": stepmanModels.StepModel{}})
+
+	config, err := configBuilder.Generate("android")
+	require.NoError(t, err)
+
+	workflowsBytes, err := yaml.Marshal(config.Workflows)
+	require.NoError(t, err)
+	require.Equal(t, `deploy:
+  steps:
+  - git-clone@4.0.11: {}
+  - cache-pull@2.0.1: {}
+  - gradle-runner@1.8.0:
+      inputs:
+      - gradle_file: $GRADLE_BUILD_FILE_PATH
+  - script@1.1.5:
+      inputs:
+      - content: echo $GRADLE_BUILD_FILE_PATH
+        opts:
+          is_expand: false
+  - cache-push@2.0.5: {}
+primary:
+  steps:
+  - git-clone@4.0.11: {}
+  - secrets-fetch@1.0.0: {}
+  - gradle-runner@1.8.0: {}
+  - deploy-to-bitrise-io@1.3.10: {}
+  - slack@2.7.0:
+      inputs:
+      - message: android primary finished
+`, string(workflowsBytes))
+
+	t.Log("missing scanner provided input")
+	{
+		configBuilder := NewDefaultConfigBuilder()
+		configBuilder.AddDefaultWorkflowBuilder(DeployWorkflowID)
+
+		_, err := configBuilder.Generate("android")
+		require.EqualError(t, err, `failed to generate workflow (deploy), error: failed to render input (content) of step (script@1.1.5), error: template: content:1:8: executing "content" at <input "gradle-runner" "gradle_file">: error calling input: no scanner provided step (gradle-runner) with input: gradle_file`)
+	}
+}
+
+func TestGenerateWithConfigTemplateAndStepLib(t *testing.T) {
+	configTemplate, err := ReadConfigTemplate(writeConfigTemplate(t, `platforms:
+  "*":
+    "*":
+      prepare_steps:
+      - git-clone@4.0.11: {}
+      - secrets-fetch@1.0.0: {}
+      - git::https://github.com/bitrise-io/steps-custom.git@main: {}
+      - path::./steps/local: {}
+      deploy_steps:
+      - scanner-steps: {}
+      - slack@2.7.0:
+          inputs:
+          - message: "{{ .ProjectType }} finished"
+`))
+	require.NoError(t, err)
+
+	SetConfigTemplate(&configTemplate)
+	defer SetConfigTemplate(nil)
+
+	// the steplib spec lists the template's slack step, but only the steps used by the scanners are loaded
+	specPth := filepath.Join(filepath.Dir(writeConfigTemplate(t, "")), "spec.json")
+	require.NoError(t, fileutil.WriteStringToFile(specPth, `{
+  "format_version": "1.0.0",
+  "steps": {
+    "git-clone": {"latest_version_number": "4.0.11", "versions": {"4.0.11": {}}},
+    "gradle-runner": {"latest_version_number": "1.8.0", "versions": {"1.8.0": {"inputs": [{"gradle_file": ""}]}}},
+    "slack": {"latest_version_number": "2.7.0", "versions": {"2.7.0": {"inputs": [{"webhook_url": ""}]}}}
+  }
+}`))
+	stepLib, err := steps.OpenStepLib(specPth)
+	require.NoError(t, err)
+
+	steps.SetStepLib(&stepLib, steps.VersionPolicyPinned)
+	defer steps.SetStepLib(nil, steps.VersionPolicyPinned)
+
+	t.Log("the template's steps, not loaded into the steplib, are not validated")
+	{
+		configBuilder := NewDefaultConfigBuilder()
+		configBuilder.AppendMainStepList(bitriseModels.StepListItemModel{"gradle-runner@1.8.0": stepmanModels.StepModel{
+			Inputs: []envmanModels.EnvironmentItemModel{{"gradle_file": "build.gradle"}},
+		}})
+
+		config, err := configBuilder.Generate("android")
+		require.NoError(t, err)
+		require.Equal(t, 6, len(config.Workflows["primary"].Steps))
+	}
+
+	t.Log("the steplib's steps are validated")
+	{
+		configBuilder := NewDefaultConfigBuilder()
+		configBuilder.AppendMainStepList(bitriseModels.StepListItemModel{"gradle-runner@1.8.0": stepmanModels.StepModel{
+			Inputs: []envmanModels.EnvironmentItemModel{{"gradle_task": "assemble"}},
+		}})
+
+		_, err := configBuilder.Generate("android")
+		require.EqualError(t, err, "invalid step in workflow (primary), error: step (gradle-runner@1.8.0) has no input: gradle_task")
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bitrise-core/bitrise-init/steps"
 	bitriseModels "github.com/bitrise-io/bitrise/models"
 	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/sliceutil"
 )
 
 const (
@@ -15,8 +17,17 @@ const (
 	FormatVersion = bitriseModels.Version
 
 	defaultSteplibSource = "https://github.com/bitrise-io/bitrise-steplib.git"
+
+	cachePathsInputKey = "cache_paths"
 )
 
+var isCacheEnabled = true
+
+// SetCacheEnabled enables or disables the cache steps of the generated workflows, caching is enabled by default.
+func SetCacheEnabled(enabled bool) {
+	isCacheEnabled = enabled
+}
+
 // ---
 // OptionModel
 
@@ -86,6 +97,18 @@ func (option *OptionModel) AddConfig(forValue string, newConfigOption *OptionMod
 	}
 }
 
+// AddValueDescription sets the human readable description of an option value,
+// it is displayed next to the value when selecting from the values.
+func (option *OptionModel) AddValueDescription(forValue, description string) {
+	if description == "" {
+		return
+	}
+	if option.ValueDescriptionMap == nil {
+		option.ValueDescriptionMap = map[string]string{}
+	}
+	option.ValueDescriptionMap[forValue] = description
+}
+
 // Parent ...
 func (option *OptionModel) Parent() (*OptionModel, string, bool) {
 	if option.Head == nil {
@@ -177,10 +200,11 @@ func (option *OptionModel) GetValues() []string {
 
 func newDefaultWorkflowBuilder() *workflowBuilderModel {
 	return &workflowBuilderModel{
-		PrepareSteps:    steps.DefaultPrepareStepList(),
+		PrepareSteps:    []bitriseModels.StepListItemModel{},
 		DependencySteps: []bitriseModels.StepListItemModel{},
 		MainSteps:       []bitriseModels.StepListItemModel{},
-		DeploySteps:     steps.DefaultDeployStepList(),
+		DeploySteps:     []bitriseModels.StepListItemModel{},
+		isDefault:       true,
 	}
 }
 
@@ -212,17 +236,75 @@ func (builder *workflowBuilderModel) stepList() []bitriseModels.StepListItemMode
 	}
 
 	stepList := []bitriseModels.StepListItemModel{}
-	stepList = append(stepList, builder.PrepareSteps...)
-	stepList = append(stepList, builder.DependencySteps...)
-	stepList = append(stepList, builder.MainSteps...)
-	stepList = append(stepList, builder.DeploySteps...)
+	for _, stage := range builder.stages() {
+		stepList = append(stepList, stage...)
+	}
 	return stepList
 }
 
-func (builder *workflowBuilderModel) generate() bitriseModels.WorkflowModel {
-	return bitriseModels.WorkflowModel{
-		Steps: builder.stepList(),
+// stages returns the prepare, dependency, main and deploy step lists of the builder,
+// with the cache steps of the cache paths: cache-pull starts the dependency and cache-push ends the deploy stage.
+func (builder *workflowBuilderModel) stages() [][]bitriseModels.StepListItemModel {
+	dependencySteps := builder.DependencySteps
+	deploySteps := builder.DeploySteps
+
+	if isCacheEnabled && len(builder.cachePaths) > 0 {
+		dependencySteps = append([]bitriseModels.StepListItemModel{steps.CachePullStepListItem()}, dependencySteps...)
+		deploySteps = append(append([]bitriseModels.StepListItemModel{}, deploySteps...), steps.CachePushStepListItem(
+			envmanModels.EnvironmentItemModel{cachePathsInputKey: strings.Join(builder.cachePaths, "\n")},
+		))
+	}
+
+	return [][]bitriseModels.StepListItemModel{
+		builder.PrepareSteps,
+		dependencySteps,
+		builder.MainSteps,
+		deploySteps,
+	}
+}
+
+func (builder *workflowBuilderModel) addCachePaths(cachePaths ...string) {
+	for _, cachePath := range cachePaths {
+		if !sliceutil.IsStringInSlice(cachePath, builder.cachePaths) {
+			builder.cachePaths = append(builder.cachePaths, cachePath)
+		}
+	}
+}
+
+func (builder *workflowBuilderModel) generate(projectType string, workflowID WorkflowID) (bitriseModels.WorkflowModel, error) {
+	if !builder.isDefault {
+		return bitriseModels.WorkflowModel{
+			Steps: builder.stepList(),
+		}, nil
+	}
+
+	if configTemplate != nil {
+		if workflowTemplate, ok := configTemplate.workflowTemplate(projectType, string(workflowID)); ok {
+			stepList, err := workflowTemplate.render(builder, ConfigTemplateDataModel{ProjectType: projectType, WorkflowID: string(workflowID)})
+			if err != nil {
+				return bitriseModels.WorkflowModel{}, err
+			}
+			return bitriseModels.WorkflowModel{
+				Steps: stepList,
+			}, nil
+		}
 	}
+
+	stages := builder.stages()
+	stepList := []bitriseModels.StepListItemModel{}
+	stepList = append(stepList, steps.DefaultPrepareStepList()...)
+	stepList = append(stepList, stages[0]...)
+	stepList = append(stepList, stages[1]...)
+	stepList = append(stepList, stages[2]...)
+	if len(builder.deployInputs) > 0 {
+		stepList = append(stepList, steps.DeployToBitriseIoStepListItem(builder.deployInputs...))
+	} else {
+		stepList = append(stepList, steps.DefaultDeployStepList()...)
+	}
+	stepList = append(stepList, stages[3]...)
+	return bitriseModels.WorkflowModel{
+		Steps: stepList,
+	}, nil
 }
 
 // NewDefaultConfigBuilder ...
@@ -278,6 +360,17 @@ func (builder *ConfigBuilderModel) AppendMainStepListTo(workflow WorkflowID, ite
 	workflowBuilder.appendMainStepList(items...)
 }
 
+// SetDeployInputsTo sets the inputs of the workflow's default deploy step,
+// the config template's workflows define their own deploy steps.
+func (builder *ConfigBuilderModel) SetDeployInputsTo(workflow WorkflowID, inputs ...envmanModels.EnvironmentItemModel) {
+	workflowBuilder := builder.workflowBuilderMap[workflow]
+	if workflowBuilder == nil {
+		workflowBuilder = &workflowBuilderModel{}
+		builder.workflowBuilderMap[workflow] = workflowBuilder
+	}
+	workflowBuilder.deployInputs = inputs
+}
+
 // AppendDeployStepListTo ...
 func (builder *ConfigBuilderModel) AppendDeployStepListTo(workflow WorkflowID, items ...bitriseModels.StepListItemModel) {
 	workflowBuilder := builder.workflowBuilderMap[workflow]
@@ -288,6 +381,22 @@ func (builder *ConfigBuilderModel) AppendDeployStepListTo(workflow WorkflowID, i
 	workflowBuilder.appendDeployStepList(items...)
 }
 
+// AddCachePathsTo adds the dependency cache paths (in the cache-push step's path -> indicator format) to the workflow,
+// the workflow pulls the cache before installing the dependencies and pushes it at the end, unless caching is disabled.
+func (builder *ConfigBuilderModel) AddCachePathsTo(workflow WorkflowID, cachePaths ...string) {
+	workflowBuilder := builder.workflowBuilderMap[workflow]
+	if workflowBuilder == nil {
+		workflowBuilder = &workflowBuilderModel{}
+		builder.workflowBuilderMap[workflow] = workflowBuilder
+	}
+	workflowBuilder.addCachePaths(cachePaths...)
+}
+
+// AddCachePaths ...
+func (builder *ConfigBuilderModel) AddCachePaths(cachePaths ...string) {
+	builder.AddCachePathsTo(PrimaryWorkflowID, cachePaths...)
+}
+
 // AppendPreparStepList ...
 func (builder *ConfigBuilderModel) AppendPreparStepList(items ...bitriseModels.StepListItemModel) {
 	workflowBuilder := builder.workflowBuilderMap[PrimaryWorkflowID]
@@ -332,16 +441,27 @@ func (builder *ConfigBuilderModel) AppendDeployStepList(items ...bitriseModels.S
 // Generate ...
 func (builder *ConfigBuilderModel) Generate(projectType string, appEnvs ...envmanModels.EnvironmentItemModel) (bitriseModels.BitriseDataModel, error) {
 	primaryWorkflowBuilder, ok := builder.workflowBuilderMap[PrimaryWorkflowID]
-	if !ok || primaryWorkflowBuilder == nil || len(primaryWorkflowBuilder.stepList()) == 0 {
+	if !ok || primaryWorkflowBuilder == nil || (!primaryWorkflowBuilder.isDefault && len(primaryWorkflowBuilder.stepList()) == 0) {
 		return bitriseModels.BitriseDataModel{}, errors.New("primary workflow not defined")
 	}
 
 	workflows := map[string]bitriseModels.WorkflowModel{}
 	for workflowID, workflowBuilder := range builder.workflowBuilderMap {
-		workflows[string(workflowID)] = workflowBuilder.generate()
+		workflow, err := workflowBuilder.generate(projectType, workflowID)
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, fmt.Errorf("failed to generate workflow (%s), error: %s", workflowID, err)
+		}
+		if err := steps.ValidateStepList(workflow.Steps); err != nil {
+			return bitriseModels.BitriseDataModel{}, fmt.Errorf("invalid step in workflow (%s), error: %s", workflowID, err)
+		}
+		workflows[string(workflowID)] = workflow
 	}
 
-	triggerMap := []bitriseModels.TriggerMapItemModel{
+	triggerMap := []bitriseModels.TriggerMapItemModel{}
+	if _, ok := workflows[string(DeployWorkflowID)]; ok {
+		triggerMap = append(triggerMap, triggerMapOptions.deployTriggerMapItems()...)
+	}
+	triggerMap = append(triggerMap,
 		bitriseModels.TriggerMapItemModel{
 			PushBranch: "*",
 			WorkflowID: string(PrimaryWorkflowID),
@@ -350,7 +470,7 @@ func (builder *ConfigBuilderModel) Generate(projectType string, appEnvs ...envma
 			PullRequestSourceBranch: "*",
 			WorkflowID:              string(PrimaryWorkflowID),
 		},
-	}
+	)
 
 	app := bitriseModels.AppModel{
 		Environments: appEnvs,
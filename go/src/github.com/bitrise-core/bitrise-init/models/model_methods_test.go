@@ -42,6 +42,18 @@ func TestGetValues(t *testing.T) {
 	require.Equal(t, 0, len(expectedMap))
 }
 
+func TestAddValueDescription(t *testing.T) {
+	option := NewOption("Fastlane lane", "FASTLANE_LANE")
+	option.AddConfig("ios beta", NewConfigOption("fastlane-config"))
+	option.AddValueDescription("ios beta", "Submit a new build to TestFlight")
+	option.AddValueDescription("ios test", "")
+
+	require.Equal(t, map[string]string{"ios beta": "Submit a new build to TestFlight"}, option.ValueDescriptionMap)
+
+	optionCopy := option.Copy()
+	require.Equal(t, option.ValueDescriptionMap, optionCopy.ValueDescriptionMap)
+}
+
 func TestLastOptions(t *testing.T) {
 	// 1. level
 	opt0 := NewOption("OPT0", "OPT0_KEY")
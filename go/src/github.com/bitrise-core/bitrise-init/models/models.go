@@ -1,6 +1,9 @@
 package models
 
-import bitriseModels "github.com/bitrise-io/bitrise/models"
+import (
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
 
 // OptionModel ...
 type OptionModel struct {
@@ -10,6 +13,8 @@ type OptionModel struct {
 	ChildOptionMap map[string]*OptionModel `json:"value_map,omitempty" yaml:"value_map,omitempty"`
 	Config         string                  `json:"config,omitempty" yaml:"config,omitempty"`
 
+	ValueDescriptionMap map[string]string `json:"value_descriptions,omitempty" yaml:"value_descriptions,omitempty"`
+
 	Components []string     `json:"-" yaml:"-"`
 	Head       *OptionModel `json:"-" yaml:"-"`
 }
@@ -23,12 +28,131 @@ type Warnings []string
 // Errors ...
 type Errors []string
 
+// ScanErrorCode ...
+type ScanErrorCode string
+
+const (
+	// ScanErrorCodeTimedOut means the scanner did not finish within its (or the whole scan's) timeout.
+	ScanErrorCodeTimedOut ScanErrorCode = "timed_out"
+	// ScanErrorCodeCanceled means the scan was canceled before the scanner finished.
+	ScanErrorCodeCanceled ScanErrorCode = "canceled"
+)
+
+// ScanErrorModel is a machine readable scanner error.
+type ScanErrorModel struct {
+	Code    ScanErrorCode `json:"code" yaml:"code"`
+	Message string        `json:"message" yaml:"message"`
+}
+
+// ScanWarningCode ...
+type ScanWarningCode string
+
+// ScanWarningCodeScannerDropped means the timed out (or canceled) scanner did not return within its grace period,
+// the scan went on without waiting for it.
+const ScanWarningCodeScannerDropped ScanWarningCode = "scanner_dropped"
+
+// ScanWarningModel is a machine readable scanner warning,
+// its Message is listed in the platform's warnings, too.
+type ScanWarningModel struct {
+	Code    ScanWarningCode `json:"code" yaml:"code"`
+	Message string          `json:"message" yaml:"message"`
+}
+
+// StackModel is the build stack recommended for the project, the empty fields have no recommendation.
+type StackModel struct {
+	JDKVersion                 string `json:"jdk_version,omitempty" yaml:"jdk_version,omitempty"`
+	GradleVersion              string `json:"gradle_version,omitempty" yaml:"gradle_version,omitempty"`
+	AndroidGradlePluginVersion string `json:"android_gradle_plugin_version,omitempty" yaml:"android_gradle_plugin_version,omitempty"`
+}
+
+// SecretFindingKind ...
+type SecretFindingKind string
+
+const (
+	// SecretFindingKindAndroidKeystore is a committed Android keystore (*.jks, *.keystore).
+	SecretFindingKindAndroidKeystore SecretFindingKind = "android_keystore"
+	// SecretFindingKindAndroidKeystoreProperties is a committed keystore.properties, holding the keystore passwords.
+	SecretFindingKindAndroidKeystoreProperties SecretFindingKind = "android_keystore_properties"
+	// SecretFindingKindCertificate is a committed code signing certificate (*.p12).
+	SecretFindingKindCertificate SecretFindingKind = "certificate"
+	// SecretFindingKindProvisioningProfile is a committed provisioning profile (*.mobileprovision).
+	SecretFindingKindProvisioningProfile SecretFindingKind = "provisioning_profile"
+	// SecretFindingKindGoogleServices is a committed google-services.json with an API key.
+	SecretFindingKindGoogleServices SecretFindingKind = "google_services"
+	// SecretFindingKindEnvFile is a committed .env file (including fastlane/.env).
+	SecretFindingKindEnvFile SecretFindingKind = "env_file"
+)
+
+// SecretFindingModel is a machine readable warning about a credential committed to the repository,
+// SecretEnvs are the suggested secret env keys to replace the committed file with.
+type SecretFindingModel struct {
+	Kind       SecretFindingKind `json:"kind" yaml:"kind"`
+	Path       string            `json:"path" yaml:"path"`
+	Message    string            `json:"message" yaml:"message"`
+	SecretEnvs []string          `json:"secret_envs,omitempty" yaml:"secret_envs,omitempty"`
+}
+
 // ScanResultModel ...
 type ScanResultModel struct {
-	PlatformOptionMap    map[string]OptionModel      `json:"options,omitempty" yaml:"options,omitempty"`
-	PlatformConfigMapMap map[string]BitriseConfigMap `json:"configs,omitempty" yaml:"configs,omitempty"`
-	PlatformWarningsMap  map[string]Warnings         `json:"warnings,omitempty" yaml:"warnings,omitempty"`
-	PlatformErrorsMap    map[string]Errors           `json:"errors,omitempty" yaml:"errors,omitempty"`
+	PlatformOptionMap       map[string]OptionModel              `json:"options,omitempty" yaml:"options,omitempty"`
+	PlatformConfigMapMap    map[string]BitriseConfigMap         `json:"configs,omitempty" yaml:"configs,omitempty"`
+	PlatformWarningsMap     map[string]Warnings                 `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	PlatformErrorsMap       map[string]Errors                   `json:"errors,omitempty" yaml:"errors,omitempty"`
+	PlatformScanErrorsMap   map[string][]ScanErrorModel         `json:"scan_errors,omitempty" yaml:"scan_errors,omitempty"`
+	PlatformScanWarningsMap map[string][]ScanWarningModel       `json:"scan_warnings,omitempty" yaml:"scan_warnings,omitempty"`
+	PlatformStackMap        map[string]StackModel               `json:"recommended_stacks,omitempty" yaml:"recommended_stacks,omitempty"`
+	SkippedScanners         []string                            `json:"skipped_scanners,omitempty" yaml:"skipped_scanners,omitempty"`
+	SecretFindings          []SecretFindingModel                `json:"secret_findings,omitempty" yaml:"secret_findings,omitempty"`
+	SecretEnvs              []envmanModels.EnvironmentItemModel `json:"secret_envs,omitempty" yaml:"secret_envs,omitempty"`
+}
+
+// MonorepoScanResultModel is the scan result of a repository with multiple independent projects,
+// ProjectResultMap's keys are the (scanned dir relative) project roots.
+type MonorepoScanResultModel struct {
+	ProjectResultMap map[string]ScanResultModel `json:"projects,omitempty" yaml:"projects,omitempty"`
+	SkippedScanners  []string                   `json:"skipped_scanners,omitempty" yaml:"skipped_scanners,omitempty"`
+	Errors           Errors                     `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// MonorepoPushTriggerModel triggers the workflow on a push to the Branch, if the changed files match the ChangedFiles pattern.
+type MonorepoPushTriggerModel struct {
+	Branch       string `json:"branch" yaml:"branch"`
+	ChangedFiles string `json:"changed_files,omitempty" yaml:"changed_files,omitempty"`
+}
+
+// MonorepoPullRequestTriggerModel triggers the workflow on a pull request, if the changed files match the ChangedFiles pattern.
+type MonorepoPullRequestTriggerModel struct {
+	SourceBranch string `json:"source_branch,omitempty" yaml:"source_branch,omitempty"`
+	TargetBranch string `json:"target_branch,omitempty" yaml:"target_branch,omitempty"`
+	ChangedFiles string `json:"changed_files,omitempty" yaml:"changed_files,omitempty"`
+}
+
+// MonorepoTagTriggerModel triggers the workflow on a tag matching the Name pattern.
+type MonorepoTagTriggerModel struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// MonorepoWorkflowTriggersModel are the target-based triggers of a workflow,
+// unlike the trigger_map's first matching item, every workflow with a matching trigger is triggered.
+type MonorepoWorkflowTriggersModel struct {
+	Push        []MonorepoPushTriggerModel        `json:"push,omitempty" yaml:"push,omitempty"`
+	PullRequest []MonorepoPullRequestTriggerModel `json:"pull_request,omitempty" yaml:"pull_request,omitempty"`
+	Tag         []MonorepoTagTriggerModel         `json:"tag,omitempty" yaml:"tag,omitempty"`
+}
+
+// MonorepoWorkflowModel is a workflow of the monorepo config, with its own triggers.
+type MonorepoWorkflowModel struct {
+	Triggers                    *MonorepoWorkflowTriggersModel `json:"triggers,omitempty" yaml:"triggers,omitempty"`
+	bitriseModels.WorkflowModel `yaml:",inline"`
+}
+
+// MonorepoBitriseDataModel is the bitrise config of a repository with multiple independent projects,
+// it contains one workflow set per project, the workflows are triggered by their own triggers, instead of a trigger_map.
+type MonorepoBitriseDataModel struct {
+	FormatVersion        string                           `json:"format_version" yaml:"format_version"`
+	DefaultStepLibSource string                           `json:"default_step_lib_source,omitempty" yaml:"default_step_lib_source,omitempty"`
+	ProjectType          string                           `json:"project_type" yaml:"project_type"`
+	Workflows            map[string]MonorepoWorkflowModel `json:"workflows,omitempty" yaml:"workflows,omitempty"`
 }
 
 type workflowBuilderModel struct {
@@ -37,6 +161,16 @@ type workflowBuilderModel struct {
 	MainSteps       []bitriseModels.StepListItemModel
 	DeploySteps     []bitriseModels.StepListItemModel
 
+	// isDefault marks the workflows seeded with the default (or the config template's) steps,
+	// the stage step lists hold the scanner provided steps only.
+	isDefault bool
+
+	// cachePaths are the dependency cache paths of the workflow, see: ConfigBuilderModel.AddCachePathsTo.
+	cachePaths []string
+
+	// deployInputs are the inputs of the default deploy step, see: ConfigBuilderModel.SetDeployInputsTo.
+	deployInputs []envmanModels.EnvironmentItemModel
+
 	steps []bitriseModels.StepListItemModel
 }
 
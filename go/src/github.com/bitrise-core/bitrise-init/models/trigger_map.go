@@ -0,0 +1,50 @@
+package models
+
+import bitriseModels "github.com/bitrise-io/bitrise/models"
+
+// TriggerMapOptionsModel describes the pushes and tags triggering the deploy workflow of the generated configs,
+// every other push and the pull requests trigger the primary workflow.
+type TriggerMapOptionsModel struct {
+	// DeployBranch is the branch (usually the default branch of the repository), the pushes of which trigger the deploy workflow.
+	DeployBranch string
+	// ReleaseBranchPatterns are the branch patterns (like: release/*), the pushes of which trigger the deploy workflow.
+	ReleaseBranchPatterns []string
+	// DeployTagPattern is the tag pattern (like: v*), the matching tags of which trigger the deploy workflow.
+	DeployTagPattern string
+}
+
+var triggerMapOptions TriggerMapOptionsModel
+
+// SetTriggerMapOptions sets the options of the generated trigger maps,
+// the configs without a deploy workflow are not affected.
+func SetTriggerMapOptions(options TriggerMapOptionsModel) {
+	triggerMapOptions = options
+}
+
+// deployTriggerMapItems returns the trigger map items of the deploy workflow,
+// they precede the primary workflow's items, as the first matching item is used.
+func (options TriggerMapOptionsModel) deployTriggerMapItems() []bitriseModels.TriggerMapItemModel {
+	items := []bitriseModels.TriggerMapItemModel{}
+	if options.DeployBranch != "" {
+		items = append(items, bitriseModels.TriggerMapItemModel{
+			PushBranch: options.DeployBranch,
+			WorkflowID: string(DeployWorkflowID),
+		})
+	}
+	for _, pattern := range options.ReleaseBranchPatterns {
+		if pattern == options.DeployBranch {
+			continue
+		}
+		items = append(items, bitriseModels.TriggerMapItemModel{
+			PushBranch: pattern,
+			WorkflowID: string(DeployWorkflowID),
+		})
+	}
+	if options.DeployTagPattern != "" {
+		items = append(items, bitriseModels.TriggerMapItemModel{
+			Tag:        options.DeployTagPattern,
+			WorkflowID: string(DeployWorkflowID),
+		})
+	}
+	return items
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	stepmanModels "github.com/bitrise-io/stepman/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTriggerMap(t *testing.T) {
+	SetTriggerMapOptions(TriggerMapOptionsModel{
+		DeployBranch:          "master",
+		ReleaseBranchPatterns: []string{"release/*"},
+		DeployTagPattern:      "v*",
+	})
+	defer SetTriggerMapOptions(TriggerMapOptionsModel{})
+
+	t.Log("config with deploy workflow")
+	{
+		configBuilder := NewDefaultConfigBuilder()
+		configBuilder.AddDefaultWorkflowBuilder(DeployWorkflowID)
+
+		config, err := configBuilder.Generate("android")
+		require.NoError(t, err)
+		require.Equal(t, bitriseModels.TriggerMapModel{
+			{PushBranch: "master", WorkflowID: "deploy"},
+			{PushBranch: "release/*", WorkflowID: "deploy"},
+			{Tag: "v*", WorkflowID: "deploy"},
+			{PushBranch: "*", WorkflowID: "primary"},
+			{PullRequestSourceBranch: "*", WorkflowID: "primary"},
+		}, config.TriggerMap)
+	}
+
+	t.Log("config without deploy workflow")
+	{
+		configBuilder := NewConfigBuilder([]bitriseModels.StepListItemModel{{"script": stepmanModels.StepModel{}}})
+
+		config, err := configBuilder.Generate("other")
+		require.NoError(t, err)
+		require.Equal(t, bitriseModels.TriggerMapModel{
+			{PushBranch: "*", WorkflowID: "primary"},
+			{PullRequestSourceBranch: "*", WorkflowID: "primary"},
+		}, config.TriggerMap)
+	}
+}
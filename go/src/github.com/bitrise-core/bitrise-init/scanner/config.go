@@ -1,9 +1,13 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
+	"github.com/bitrise-core/bitrise-init/events"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners"
 	"github.com/bitrise-io/go-utils/colorstring"
@@ -12,8 +16,24 @@ import (
 	"github.com/bitrise-io/go-utils/sliceutil"
 )
 
-// Config ...
-func Config(searchDir string) models.ScanResultModel {
+// Config runs the active scanners on the searchDir.
+// The externalScanners run before the built-in ones, so they can exclude built-in scanners.
+func Config(ctx context.Context, searchDir string, externalScanners ...scanners.ScannerInterface) models.ScanResultModel {
+	return ConfigWithScanners(ctx, searchDir, WithExternalScanners(scanners.ActiveScanners, externalScanners), 0)
+}
+
+// WithExternalScanners returns the builtInScanners prepended with the externalScanners.
+func WithExternalScanners(builtInScanners, externalScanners []scanners.ScannerInterface) []scanners.ScannerInterface {
+	projectScanners := append([]scanners.ScannerInterface{}, externalScanners...)
+	return append(projectScanners, builtInScanners...)
+}
+
+// ConfigWithScanners runs the given scanners on the searchDir.
+// Every scanner runs with a scannerTimeout (0 means no timeout) limited ctx.
+// If a scanner does not finish in time, or the ctx is done, the scanner's platform gets a timed out (or canceled) scan error,
+// and the scan goes on with the next scanner (or reports the rest of the scanners the same way, if the ctx is done),
+// once the abandoned scanner returns, or its grace period expires and the scanner is dropped.
+func ConfigWithScanners(ctx context.Context, searchDir string, projectScanners []scanners.ScannerInterface, scannerTimeout time.Duration) models.ScanResultModel {
 	result := models.ScanResultModel{}
 
 	//
@@ -50,19 +70,44 @@ func Config(searchDir string) models.ScanResultModel {
 
 	//
 	// Scan
-	projectScanners := scanners.ActiveScanners
-
 	projectTypeErrorMap := map[string]models.Errors{}
+	projectTypeScanErrorMap := map[string][]models.ScanErrorModel{}
+	projectTypeScanWarningMap := map[string][]models.ScanWarningModel{}
+	projectTypeStackMap := map[string]models.StackModel{}
 	projectTypeWarningMap := map[string]models.Warnings{}
 	projectTypeOptionMap := map[string]models.OptionModel{}
 	projectTypeConfigMap := map[string]models.BitriseConfigMap{}
 
 	excludedScannerNames := []string{}
 
+	scanStart := events.Now()
+	events.Emit(events.Event{Type: events.ScanStarted, SearchDir: searchDir})
+
 	log.Infoft(colorstring.Blue("Running scanners:"))
-	fmt.Println()
+	log.Printf("")
+
+	// abandonedScanner is a timed out (or canceled) scanner, still running on its goroutine.
+	// The next scanner does not start until the abandoned scanner returns, or its grace period expires.
+	// A dropped scanner keeps running, but it only shares the (unchanged) current dir with the next scanners,
+	// its output is discarded.
+	var abandoned *abandonedScanner
+	dropAbandonedScanner := func() {
+		if abandoned == nil || waitForAbandonedScanner(abandoned.outputCh, abandonedScannerGracePeriod) {
+			abandoned = nil
+			return
+		}
+
+		message := fmt.Sprintf("scanner did not return within %s after it was abandoned, dropped it", abandonedScannerGracePeriod)
+		log.Warnft("Scanner: %s %s", abandoned.name, message)
+		projectTypeWarningMap[abandoned.name] = append(projectTypeWarningMap[abandoned.name], message)
+		projectTypeScanWarningMap[abandoned.name] = append(projectTypeScanWarningMap[abandoned.name], models.ScanWarningModel{Code: models.ScanWarningCodeScannerDropped, Message: message})
+		emitWarnings(searchDir, abandoned.name, message)
+		abandoned = nil
+	}
 
 	for _, detector := range projectScanners {
+		dropAbandonedScanner()
+
 		detectorName := detector.Name()
 		detectorWarnings := []string{}
 		detectorErrors := []string{}
@@ -71,55 +116,113 @@ func Config(searchDir string) models.ScanResultModel {
 
 		if sliceutil.IsStringInSlice(detectorName, excludedScannerNames) {
 			log.Warnft("scanner is marked as excluded, skipping...")
-			fmt.Println()
+			log.Printf("")
+			events.Emit(events.Event{Type: events.ScannerExcluded, SearchDir: searchDir, Scanner: detectorName})
 			continue
 		}
 
+		detectorStart := events.Now()
+		events.Emit(events.Event{Type: events.ScannerStarted, SearchDir: searchDir, Scanner: detectorName})
+		finished := func(result string) {
+			events.Emit(events.Event{Type: events.ScannerFinished, SearchDir: searchDir, Scanner: detectorName, Result: result, DurationMS: events.Since(detectorStart)})
+		}
+
 		log.Printft("+------------------------------------------------------------------------------+")
 		log.Printft("|                                                                              |")
 
-		detected, err := detector.DetectPlatform(searchDir)
+		output, scanErr, pending := runScanner(ctx, detector, searchDir, scannerTimeout)
+		if scanErr != nil {
+			if pending != nil {
+				abandoned = &abandonedScanner{name: detectorName, outputCh: pending}
+			}
+			log.Errorft("Scanner failed, error: %s", scanErr.Message)
+			projectTypeScanErrorMap[detectorName] = append(projectTypeScanErrorMap[detectorName], *scanErr)
+			projectTypeErrorMap[detectorName] = append(detectorErrors, scanErr.Message)
+
+			if output.detected {
+				events.Emit(events.Event{Type: events.PlatformDetected, SearchDir: searchDir, Scanner: detectorName})
+			}
+			events.Emit(events.Event{Type: events.Error, SearchDir: searchDir, Scanner: detectorName, Message: scanErr.Message})
+			if scanErr.Code == models.ScanErrorCodeTimedOut {
+				finished(events.ResultTimedOut)
+			} else {
+				finished(events.ResultCanceled)
+			}
+
+			log.Printft("|                                                                              |")
+			log.Printft("+------------------------------------------------------------------------------+")
+			log.Printf("")
+			continue
+		}
+
+		detected, err := output.detected, output.detectErr
 		if err != nil {
 			log.Errorft("Scanner failed, error: %s", err)
 			detectorWarnings = append(detectorWarnings, err.Error())
 			projectTypeWarningMap[detectorName] = detectorWarnings
 			detected = false
+
+			emitWarnings(searchDir, detectorName, err.Error())
+			finished(events.ResultFailed)
+		} else if !detected {
+			finished(events.ResultNotDetected)
 		}
 
 		if !detected {
 			log.Printft("|                                                                              |")
 			log.Printft("+------------------------------------------------------------------------------+")
-			fmt.Println()
+			log.Printf("")
 			continue
 		}
 
-		options, projectWarnings, err := detector.Options()
+		events.Emit(events.Event{Type: events.PlatformDetected, SearchDir: searchDir, Scanner: detectorName})
+
+		options, projectWarnings, err := output.options, output.warnings, output.optionsErr
+		for _, scanWarning := range output.scanWarnings {
+			projectWarnings = append(projectWarnings, scanWarning.Message)
+		}
+		if len(output.scanWarnings) > 0 {
+			projectTypeScanWarningMap[detectorName] = output.scanWarnings
+		}
+		if output.stack != nil {
+			projectTypeStackMap[detectorName] = *output.stack
+		}
 		detectorWarnings = append(detectorWarnings, projectWarnings...)
+		emitWarnings(searchDir, detectorName, projectWarnings...)
 
 		if err != nil {
 			log.Errorft("Analyzer failed, error: %s", err)
 			detectorWarnings = append(detectorWarnings, err.Error())
 			projectTypeWarningMap[detectorName] = detectorWarnings
 
+			emitWarnings(searchDir, detectorName, err.Error())
+			finished(events.ResultFailed)
+
 			log.Printft("|                                                                              |")
 			log.Printft("+------------------------------------------------------------------------------+")
-			fmt.Println()
+			log.Printf("")
 			continue
 		}
 
 		projectTypeWarningMap[detectorName] = detectorWarnings
 		projectTypeOptionMap[detectorName] = options
 
+		events.Emit(events.Event{Type: events.OptionsFound, SearchDir: searchDir, Scanner: detectorName, Configs: optionConfigNames(options)})
+
 		// Generate configs
-		configs, err := detector.Configs()
+		configs, err := output.configs, output.configsErr
 		if err != nil {
 			log.Errorft("Failed to generate config, error: %s", err)
 			detectorErrors = append(detectorErrors, err.Error())
 			projectTypeErrorMap[detectorName] = detectorErrors
+
+			events.Emit(events.Event{Type: events.Error, SearchDir: searchDir, Scanner: detectorName, Message: err.Error()})
+			finished(events.ResultFailed)
 			continue
 		}
 
 		projectTypeConfigMap[detectorName] = configs
+		finished(events.ResultDetected)
 
 		log.Printft("|                                                                              |")
 		log.Printft("+------------------------------------------------------------------------------+")
@@ -130,14 +233,178 @@ func Config(searchDir string) models.ScanResultModel {
 			excludedScannerNames = append(excludedScannerNames, exludedScanners...)
 		}
 
-		fmt.Println()
+		log.Printf("")
 	}
+
+	dropAbandonedScanner()
+
+	secretFindings := scanSecrets(ctx, searchDir)
+
+	events.Emit(events.Event{Type: events.ScanFinished, SearchDir: searchDir, DurationMS: events.Since(scanStart)})
 	// ---
 
+	if len(projectTypeScanErrorMap) == 0 {
+		projectTypeScanErrorMap = nil
+	}
+	if len(projectTypeScanWarningMap) == 0 {
+		projectTypeScanWarningMap = nil
+	}
+	if len(projectTypeStackMap) == 0 {
+		projectTypeStackMap = nil
+	}
+
 	return models.ScanResultModel{
-		PlatformOptionMap:    projectTypeOptionMap,
-		PlatformConfigMapMap: projectTypeConfigMap,
-		PlatformWarningsMap:  projectTypeWarningMap,
-		PlatformErrorsMap:    projectTypeErrorMap,
+		PlatformOptionMap:       projectTypeOptionMap,
+		PlatformConfigMapMap:    projectTypeConfigMap,
+		PlatformWarningsMap:     projectTypeWarningMap,
+		PlatformErrorsMap:       projectTypeErrorMap,
+		PlatformScanErrorsMap:   projectTypeScanErrorMap,
+		PlatformScanWarningsMap: projectTypeScanWarningMap,
+		PlatformStackMap:        projectTypeStackMap,
+		SecretFindings:          secretFindings,
+		SecretEnvs:              SecretEnvsTemplate(secretFindings).Envs,
+	}
+}
+
+// scannerOutput holds the outputs of a scanner's DetectPlatform, Options and Configs calls.
+type scannerOutput struct {
+	detected  bool
+	detectErr error
+
+	options      models.OptionModel
+	warnings     models.Warnings
+	scanWarnings []models.ScanWarningModel
+	stack        *models.StackModel
+	optionsErr   error
+
+	configs    models.BitriseConfigMap
+	configsErr error
+}
+
+// runScanner runs the detector with a timeout limited ctx.
+// The detector runs on its own goroutine, if the ctx is done before the detector finishes, a scan error is returned
+// along with the channel the abandoned detector sends its output to, once it returns.
+func runScanner(ctx context.Context, detector scanners.ScannerInterface, searchDir string, timeout time.Duration) (scannerOutput, *models.ScanErrorModel, <-chan scannerOutput) {
+	if err := ctx.Err(); err != nil {
+		return scannerOutput{}, newScanError(ctx, err, timeout), nil
+	}
+
+	var scannerCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		scannerCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		scannerCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	detectedCh := make(chan bool, 1)
+	outputCh := make(chan scannerOutput, 1)
+	go func() {
+		output := scannerOutput{}
+		defer func() {
+			outputCh <- output
+		}()
+
+		output.detected, output.detectErr = detector.DetectPlatform(scannerCtx, searchDir)
+		detectedCh <- output.detected
+		if output.detectErr != nil || !output.detected {
+			return
+		}
+
+		output.options, output.warnings, output.optionsErr = detector.Options(scannerCtx)
+		if provider, ok := detector.(scanners.ScanWarningsProvider); ok {
+			output.scanWarnings = provider.ScanWarnings()
+		}
+		if recommender, ok := detector.(scanners.StackRecommender); ok {
+			output.stack = recommender.RecommendedStack()
+		}
+		if output.optionsErr != nil {
+			return
+		}
+
+		output.configs, output.configsErr = detector.Configs()
+	}()
+
+	select {
+	case output := <-outputCh:
+		// the scanner may return a failure caused by the killed commands, before the ctx's done channel is selected
+		if err := scannerCtx.Err(); err != nil {
+			return scannerOutput{detected: output.detected}, newScanError(ctx, err, timeout), nil
+		}
+		return output, nil, nil
+	case <-scannerCtx.Done():
+		detected := false
+		select {
+		case detected = <-detectedCh:
+		default:
+		}
+		return scannerOutput{detected: detected}, newScanError(ctx, scannerCtx.Err(), timeout), outputCh
 	}
 }
+
+// abandonedScannerGracePeriod is the time an abandoned scanner gets to return, after its ctx is done.
+var abandonedScannerGracePeriod = 5 * time.Second
+
+// abandonedScanner is a timed out (or canceled) scanner, outputCh receives its output once it returns.
+type abandonedScanner struct {
+	name     string
+	outputCh <-chan scannerOutput
+}
+
+// waitForAbandonedScanner waits for the abandoned scanner to return, at most for the gracePeriod,
+// the commands it started are already killed by the done ctx.
+// Returns false if the scanner did not return in time.
+func waitForAbandonedScanner(outputCh <-chan scannerOutput, gracePeriod time.Duration) bool {
+	select {
+	case <-outputCh:
+		return true
+	default:
+	}
+
+	log.Warnft("Waiting for the abandoned scanner to return...")
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-outputCh:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func newScanError(ctx context.Context, err error, scannerTimeout time.Duration) *models.ScanErrorModel {
+	if err == context.Canceled {
+		return &models.ScanErrorModel{Code: models.ScanErrorCodeCanceled, Message: "scan was canceled before the scanner finished"}
+	}
+	if ctx.Err() != nil {
+		return &models.ScanErrorModel{Code: models.ScanErrorCodeTimedOut, Message: "scan timed out before the scanner finished"}
+	}
+	return &models.ScanErrorModel{Code: models.ScanErrorCodeTimedOut, Message: fmt.Sprintf("scanner timed out after %s", scannerTimeout)}
+}
+
+func emitWarnings(searchDir, scannerName string, warnings ...string) {
+	for _, warning := range warnings {
+		events.Emit(events.Event{Type: events.Warning, SearchDir: searchDir, Scanner: scannerName, Message: warning})
+	}
+}
+
+// optionConfigNames returns the sorted, unique config names reachable through the option.
+func optionConfigNames(option models.OptionModel) []string {
+	nameMap := map[string]bool{}
+	for _, lastOption := range option.LastChilds() {
+		if lastOption.Config != "" {
+			nameMap[lastOption.Config] = true
+		}
+	}
+
+	names := []string{}
+	for name := range nameMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
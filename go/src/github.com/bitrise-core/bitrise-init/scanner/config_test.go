@@ -0,0 +1,268 @@
+package scanner
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/events"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/scanners/ios"
+	"github.com/bitrise-core/bitrise-init/scanners/scannertest"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const (
+	fixturesDir = "testdata/fixtures"
+	goldenDir   = "testdata/golden"
+)
+
+// TestConfigFixtures runs every scanner on the project trees placed in testdata/fixtures
+// and compares the scan results with the golden files placed in testdata/golden.
+// Run the tests with the -update flag to regenerate the golden files.
+func TestConfigFixtures(t *testing.T) {
+	fixtures, err := ioutil.ReadDir(fixturesDir)
+	require.NoError(t, err)
+
+	for _, fixture := range fixtures {
+		if !fixture.IsDir() {
+			continue
+		}
+
+		name := fixture.Name()
+		t.Run(name, func(t *testing.T) {
+			fixtureDir, err := pathutil.AbsPath(filepath.Join(fixturesDir, name))
+			require.NoError(t, err)
+
+			goldenPth, err := pathutil.AbsPath(filepath.Join(goldenDir, name+".yml"))
+			require.NoError(t, err)
+
+			result := ConfigWithScanners(context.Background(), fixtureDir, scanners.NewActiveScanners(), 0)
+
+			resultBytes, err := yaml.Marshal(result)
+			require.NoError(t, err)
+
+			// scanners may report absolute paths, make the results independent of the checkout location
+			actual := strings.Replace(string(resultBytes), fixtureDir, "$FIXTURE_DIR", -1)
+
+			if *update {
+				require.NoError(t, fileutil.WriteStringToFile(goldenPth, actual))
+				return
+			}
+
+			expected, err := fileutil.ReadStringFromFile(goldenPth)
+			require.NoError(t, err, "golden file not found, run the tests with -update to generate it")
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+// unsupportedFixtureScanners maps the fixture projects to the scanner, which detects its platform in them, but does not support them.
+var unsupportedFixtureScanners = map[string]string{
+	"android-missing-gradlew": android.ScannerName,
+}
+
+// TestScannerConformance runs the scanner conformance checks on every fixture project.
+func TestScannerConformance(t *testing.T) {
+	require.NoError(t, scannertest.CheckUniqueNames(scanners.NewActiveScanners()))
+
+	fixtures, err := ioutil.ReadDir(fixturesDir)
+	require.NoError(t, err)
+
+	for _, fixture := range fixtures {
+		if !fixture.IsDir() {
+			continue
+		}
+
+		name := fixture.Name()
+		t.Run(name, func(t *testing.T) {
+			for _, projectScanner := range scanners.NewActiveScanners() {
+				if unsupportedFixtureScanners[name] == projectScanner.Name() {
+					scannertest.RunUnsupported(t, projectScanner, filepath.Join(fixturesDir, name))
+					continue
+				}
+				scannertest.Run(t, projectScanner, filepath.Join(fixturesDir, name))
+			}
+		})
+	}
+}
+
+type eventRecorder struct {
+	events []events.Event
+}
+
+func (r *eventRecorder) Emit(event events.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestConfigEvents(t *testing.T) {
+	recorder := &eventRecorder{}
+	events.SetEmitter(recorder)
+	defer events.SetEmitter(nil)
+
+	fixtureDir, err := pathutil.AbsPath(filepath.Join(fixturesDir, "android-missing-gradlew"))
+	require.NoError(t, err)
+
+	ConfigWithScanners(context.Background(), fixtureDir, []scanners.ScannerInterface{ios.NewScanner(), android.NewScanner()}, 0)
+
+	eventTypes := []string{}
+	for _, event := range recorder.events {
+		require.Equal(t, fixtureDir, event.SearchDir)
+		eventTypes = append(eventTypes, strings.Join([]string{string(event.Type), event.Scanner, event.Result}, " "))
+	}
+
+	require.Equal(t, []string{
+		"scan_started  ",
+		"scanner_started ios ",
+		"scanner_finished ios not_detected",
+		"scanner_started android ",
+		"platform_detected android ",
+		"warning android ",
+		"scanner_finished android failed",
+		"scan_finished  ",
+	}, eventTypes)
+}
+
+// hangingScanner detects its platform, but its Options hangs for the given duration, ignoring the ctx,
+// then it checks if the current dir is still the search dir.
+type hangingScanner struct {
+	hang        time.Duration
+	inSearchDir *bool
+}
+
+func (scanner hangingScanner) Name() string { return "hanging" }
+func (scanner hangingScanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
+	return true, nil
+}
+func (scanner hangingScanner) ExcludedScannerNames() []string { return nil }
+func (scanner hangingScanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
+	time.Sleep(scanner.hang)
+	exist, err := pathutil.IsPathExists("gradlew")
+	*scanner.inSearchDir = exist && err == nil
+	return models.OptionModel{}, nil, nil
+}
+func (scanner hangingScanner) DefaultOptions() models.OptionModel { return models.OptionModel{} }
+func (scanner hangingScanner) Configs() (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{}, nil
+}
+func (scanner hangingScanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{}, nil
+}
+
+// blockedScanner detects its platform, but its Options does not return until the release channel is closed, ignoring the ctx.
+type blockedScanner struct {
+	hangingScanner
+	release <-chan bool
+}
+
+func (scanner blockedScanner) Name() string { return "blocked" }
+func (scanner blockedScanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
+	<-scanner.release
+	return models.OptionModel{}, nil, nil
+}
+
+func TestConfigTimeout(t *testing.T) {
+	fixtureDir, err := pathutil.AbsPath(filepath.Join(fixturesDir, "android-simple"))
+	require.NoError(t, err)
+
+	t.Log("scanner timeout")
+	{
+		inSearchDir := false
+
+		recorder := &eventRecorder{}
+		events.SetEmitter(recorder)
+		defer events.SetEmitter(nil)
+
+		result := ConfigWithScanners(context.Background(), fixtureDir, []scanners.ScannerInterface{hangingScanner{hang: 400 * time.Millisecond, inSearchDir: &inSearchDir}, android.NewScanner()}, 200*time.Millisecond)
+
+		// the abandoned scanner returned before the current dir was restored
+		require.True(t, inSearchDir)
+
+		require.Equal(t, map[string][]models.ScanErrorModel{
+			"hanging": {{Code: models.ScanErrorCodeTimedOut, Message: "scanner timed out after 200ms"}},
+		}, result.PlatformScanErrorsMap)
+		require.Equal(t, models.Errors{"scanner timed out after 200ms"}, result.PlatformErrorsMap["hanging"])
+
+		// the other platforms are still reported
+		require.Equal(t, 1, len(result.PlatformOptionMap))
+		require.Equal(t, 1, len(result.PlatformConfigMapMap["android"]))
+
+		results := []string{}
+		for _, event := range recorder.events {
+			if event.Type == events.ScannerFinished {
+				results = append(results, event.Scanner+" "+event.Result)
+			}
+		}
+		require.Equal(t, []string{"hanging timed_out", "android detected"}, results)
+	}
+
+	t.Log("scan deadline exceeded")
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		inSearchDir := false
+
+		result := ConfigWithScanners(ctx, fixtureDir, []scanners.ScannerInterface{hangingScanner{hang: 400 * time.Millisecond, inSearchDir: &inSearchDir}, android.NewScanner()}, 0)
+
+		require.True(t, inSearchDir)
+
+		require.Equal(t, map[string][]models.ScanErrorModel{
+			"hanging": {{Code: models.ScanErrorCodeTimedOut, Message: "scan timed out before the scanner finished"}},
+			"android": {{Code: models.ScanErrorCodeTimedOut, Message: "scan timed out before the scanner finished"}},
+		}, result.PlatformScanErrorsMap)
+		require.Equal(t, 0, len(result.PlatformOptionMap))
+	}
+
+	t.Log("abandoned scanner never returns")
+	{
+		gracePeriod := abandonedScannerGracePeriod
+		abandonedScannerGracePeriod = 100 * time.Millisecond
+		defer func() { abandonedScannerGracePeriod = gracePeriod }()
+
+		release := make(chan bool)
+		defer close(release)
+
+		done := make(chan models.ScanResultModel, 1)
+		go func() {
+			done <- ConfigWithScanners(context.Background(), fixtureDir, []scanners.ScannerInterface{blockedScanner{release: release}, android.NewScanner()}, 200*time.Millisecond)
+		}()
+
+		var result models.ScanResultModel
+		select {
+		case result = <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("scan waits for the blocked scanner")
+		}
+
+		require.Equal(t, []models.ScanErrorModel{{Code: models.ScanErrorCodeTimedOut, Message: "scanner timed out after 200ms"}}, result.PlatformScanErrorsMap["blocked"])
+		require.Equal(t, []models.ScanWarningModel{{Code: models.ScanWarningCodeScannerDropped, Message: "scanner did not return within 100ms after it was abandoned, dropped it"}}, result.PlatformScanWarningsMap["blocked"])
+
+		// the next scanners still run
+		require.Equal(t, 1, len(result.PlatformConfigMapMap["android"]))
+	}
+
+	t.Log("scan canceled")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := ConfigWithScanners(ctx, fixtureDir, []scanners.ScannerInterface{android.NewScanner()}, 0)
+
+		require.Equal(t, map[string][]models.ScanErrorModel{
+			"android": {{Code: models.ScanErrorCodeCanceled, Message: "scan was canceled before the scanner finished"}},
+		}, result.PlatformScanErrorsMap)
+	}
+}
@@ -8,8 +8,8 @@ import (
 )
 
 // ManualConfig ...
-func ManualConfig() (models.ScanResultModel, error) {
-	projectScanners := scanners.ActiveScanners
+func ManualConfig(externalScanners ...scanners.ScannerInterface) (models.ScanResultModel, error) {
+	projectScanners := WithExternalScanners(scanners.ActiveScanners, externalScanners)
 	projectTypeOptionMap := map[string]models.OptionModel{}
 	projectTypeConfigMap := map[string]models.BitriseConfigMap{}
 
@@ -0,0 +1,290 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/pmezard/go-difflib/difflib"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// WorkflowCollisionError is returned by MergeBitriseConfig, if the existing config already contains some of the prefixed generated workflow ids.
+type WorkflowCollisionError struct {
+	WorkflowIDs []string
+}
+
+// Error ...
+func (err WorkflowCollisionError) Error() string {
+	return fmt.Sprintf("workflow id collision, the existing config already contains: %s", strings.Join(err.WorkflowIDs, ", "))
+}
+
+// MergePrefix returns the default workflow id prefix of the generated config: its project type.
+func MergePrefix(generated bitriseModels.BitriseDataModel) string {
+	if generated.ProjectType != "" {
+		return generated.ProjectType
+	}
+	return "generated"
+}
+
+// MergeBitriseConfig merges the generated config into the existing one:
+// the generated workflows are added with prefixed ids (<prefix>-<workflow id>),
+// the app envs are unioned (existing values win) and the existing trigger map items are kept,
+// the generated trigger map items are appended, unless an existing item already handles the same event.
+// The existing config is merged as a yaml.MapSlice, so the keys unknown to the bitrise models
+// (like meta, pipelines, stages, step_bundles or tools) and the order of the keys are kept.
+// The returned warnings describe the conflicts resolved in favour of the existing config.
+func MergeBitriseConfig(existing yaml.MapSlice, generated bitriseModels.BitriseDataModel, prefix string) (yaml.MapSlice, []string, error) {
+	if prefix == "" {
+		return nil, nil, fmt.Errorf("workflow id prefix not provided")
+	}
+
+	merged := append(yaml.MapSlice{}, existing...)
+	warnings := []string{}
+
+	// the existing header keys are kept in place, the missing generated ones are appended
+	for _, item := range []yaml.MapItem{
+		{Key: "format_version", Value: generated.FormatVersion},
+		{Key: "default_step_lib_source", Value: generated.DefaultStepLibSource},
+		{Key: "project_type", Value: generated.ProjectType},
+	} {
+		if _, ok := mapSliceValue(existing, item.Key.(string)); !ok && item.Value != "" {
+			merged = append(merged, item)
+		}
+	}
+
+	//
+	// Workflows
+	workflowIDs := []string{}
+	for workflowID := range generated.Workflows {
+		workflowIDs = append(workflowIDs, workflowID)
+	}
+	sort.Strings(workflowIDs)
+
+	existingWorkflows, err := mapSliceOf(existing, "workflows")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collisions := []string{}
+	for _, workflowID := range workflowIDs {
+		if _, ok := mapSliceValue(existingWorkflows, prefix+"-"+workflowID); ok {
+			collisions = append(collisions, prefix+"-"+workflowID)
+		}
+	}
+	if len(collisions) > 0 {
+		return nil, nil, WorkflowCollisionError{WorkflowIDs: collisions}
+	}
+
+	workflows := append(yaml.MapSlice{}, existingWorkflows...)
+	for _, workflowID := range workflowIDs {
+		workflow := generated.Workflows[workflowID]
+		workflow.BeforeRun = renameWorkflowIDs(workflow.BeforeRun, prefix)
+		workflow.AfterRun = renameWorkflowIDs(workflow.AfterRun, prefix)
+
+		value, err := toMapSlice(workflow)
+		if err != nil {
+			return nil, nil, err
+		}
+		workflows = append(workflows, yaml.MapItem{Key: prefix + "-" + workflowID, Value: value})
+	}
+	merged = setMapSliceValue(merged, "workflows", workflows)
+	// ---
+
+	//
+	// App envs
+	existingApp, err := mapSliceOf(existing, "app")
+	if err != nil {
+		return nil, nil, err
+	}
+	existingEnvs, _ := mapSliceValue(existingApp, "envs")
+	envs, envWarnings, err := unionEnvs(existingEnvs, generated.App.Environments)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(envs) > 0 {
+		merged = setMapSliceValue(merged, "app", setMapSliceValue(append(yaml.MapSlice{}, existingApp...), "envs", envs))
+	}
+	warnings = append(warnings, envWarnings...)
+	// ---
+
+	//
+	// Trigger map
+	existingTriggerMapValue, _ := mapSliceValue(existing, "trigger_map")
+	var existingTriggerMap bitriseModels.TriggerMapModel
+	if err := convertYAML(existingTriggerMapValue, &existingTriggerMap); err != nil {
+		return nil, nil, fmt.Errorf("invalid trigger map in the existing config, error: %s", err)
+	}
+
+	triggerMap, _ := existingTriggerMapValue.([]interface{})
+	triggerMap = append([]interface{}{}, triggerMap...)
+	for _, item := range generated.TriggerMap {
+		item.WorkflowID = prefix + "-" + item.WorkflowID
+
+		if existingItem, ok := findTriggerMapItem(existingTriggerMap, item); ok {
+			warnings = append(warnings, fmt.Sprintf("trigger map item (%s) is already handled by workflow: %s, workflow %s is not triggered", triggerMapItemString(item), existingItem.WorkflowID, item.WorkflowID))
+			continue
+		}
+
+		value, err := toMapSlice(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		triggerMap = append(triggerMap, value)
+	}
+	if len(triggerMap) > 0 {
+		merged = setMapSliceValue(merged, "trigger_map", triggerMap)
+	}
+	// ---
+
+	return merged, warnings, nil
+}
+
+// unionEnvs appends the generated envs to the existing ones (the items of an envs list), if their key is not yet defined.
+func unionEnvs(existingValue interface{}, generated []envmanModels.EnvironmentItemModel) ([]interface{}, []string, error) {
+	existing, _ := existingValue.([]interface{})
+	envs := append([]interface{}{}, existing...)
+	warnings := []string{}
+
+	valueByKey := map[string]string{}
+	for _, item := range existing {
+		var env envmanModels.EnvironmentItemModel
+		if err := convertYAML(item, &env); err != nil {
+			return nil, nil, fmt.Errorf("invalid app env in the existing config, error: %s", err)
+		}
+		key, value, err := env.GetKeyValuePair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid app env in the existing config, error: %s", err)
+		}
+		valueByKey[key] = value
+	}
+
+	for _, env := range generated {
+		key, value, err := env.GetKeyValuePair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid app env in the generated config, error: %s", err)
+		}
+
+		existingValue, ok := valueByKey[key]
+		if !ok {
+			item, err := toMapSlice(env)
+			if err != nil {
+				return nil, nil, err
+			}
+			envs = append(envs, item)
+			valueByKey[key] = value
+		} else if existingValue != value {
+			warnings = append(warnings, fmt.Sprintf("app env %s is kept with the existing value (%s), the generated value is: %s", key, existingValue, value))
+		}
+	}
+
+	return envs, warnings, nil
+}
+
+// mapSliceValue returns the value of the key.
+func mapSliceValue(mapSlice yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range mapSlice {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// mapSliceOf returns the mapping value of the key, an empty one if the key is not set.
+func mapSliceOf(mapSlice yaml.MapSlice, key string) (yaml.MapSlice, error) {
+	value, ok := mapSliceValue(mapSlice, key)
+	if !ok || value == nil {
+		return yaml.MapSlice{}, nil
+	}
+	mapping, ok := value.(yaml.MapSlice)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s in the existing config, mapping expected", key)
+	}
+	return mapping, nil
+}
+
+// setMapSliceValue replaces the value of the key in place, or appends the key, if it is not set.
+func setMapSliceValue(mapSlice yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range mapSlice {
+		if item.Key == key {
+			mapSlice[i].Value = value
+			return mapSlice
+		}
+	}
+	return append(mapSlice, yaml.MapItem{Key: key, Value: value})
+}
+
+// toMapSlice converts the model to a yaml.MapSlice, keeping the order of the model's fields.
+func toMapSlice(model interface{}) (yaml.MapSlice, error) {
+	var mapSlice yaml.MapSlice
+	if err := convertYAML(model, &mapSlice); err != nil {
+		return nil, fmt.Errorf("failed to convert generated config, error: %s", err)
+	}
+	return mapSlice, nil
+}
+
+// convertYAML converts the value to the out model by serializing it.
+func convertYAML(value, out interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(bytes, out)
+}
+
+func findTriggerMapItem(triggerMap bitriseModels.TriggerMapModel, item bitriseModels.TriggerMapItemModel) (bitriseModels.TriggerMapItemModel, bool) {
+	for _, existingItem := range triggerMap {
+		if existingItem.PushBranch == item.PushBranch &&
+			existingItem.PullRequestSourceBranch == item.PullRequestSourceBranch &&
+			existingItem.PullRequestTargetBranch == item.PullRequestTargetBranch &&
+			existingItem.Tag == item.Tag &&
+			existingItem.Pattern == item.Pattern {
+			return existingItem, true
+		}
+	}
+	return bitriseModels.TriggerMapItemModel{}, false
+}
+
+func triggerMapItemString(item bitriseModels.TriggerMapItemModel) string {
+	fields := []string{}
+	for _, field := range []struct{ key, value string }{
+		{"push_branch", item.PushBranch},
+		{"pull_request_source_branch", item.PullRequestSourceBranch},
+		{"pull_request_target_branch", item.PullRequestTargetBranch},
+		{"tag", item.Tag},
+		{"pattern", item.Pattern},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.key+": "+field.value)
+		}
+	}
+	return strings.Join(fields, ", ")
+}
+
+// ConfigDiff returns the unified diff of the original and the changed config content.
+func ConfigDiff(name, original, changed string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(original),
+		B:        splitLines(changed),
+		FromFile: name,
+		ToFile:   name + " (merged)",
+		Context:  3,
+	})
+}
+
+// splitLines splits the content into lines, keeping the line endings (difflib.SplitLines adds an extra empty line).
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
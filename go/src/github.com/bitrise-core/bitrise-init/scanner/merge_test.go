@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const existingConfig = `format_version: "1.3.1"
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+project_type: ios
+app:
+  envs:
+  - BITRISE_PROJECT_PATH: Sample.xcodeproj
+  - BITRISE_SCHEME: Sample
+trigger_map:
+- push_branch: '*'
+  workflow: primary
+- tag: '*'
+  workflow: deploy
+workflows:
+  deploy:
+    steps:
+    - git-clone: {}
+  primary:
+    steps:
+    - git-clone: {}
+`
+
+const generatedConfig = `format_version: "1.3.1"
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+project_type: android
+app:
+  envs:
+  - PROJECT_LOCATION: .
+  - BITRISE_SCHEME: Other
+trigger_map:
+- push_branch: '*'
+  workflow: primary
+- pull_request_source_branch: '*'
+  workflow: primary
+workflows:
+  _setup:
+    steps:
+    - activate-ssh-key: {}
+  primary:
+    before_run:
+    - _setup
+    steps:
+    - gradle-runner: {}
+`
+
+func parseConfig(t *testing.T, content string) bitriseModels.BitriseDataModel {
+	var config bitriseModels.BitriseDataModel
+	require.NoError(t, yaml.Unmarshal([]byte(content), &config))
+	return config
+}
+
+func parseMapSlice(t *testing.T, content string) yaml.MapSlice {
+	var config yaml.MapSlice
+	require.NoError(t, yaml.Unmarshal([]byte(content), &config))
+	return config
+}
+
+func mapSliceToConfig(t *testing.T, mapSlice yaml.MapSlice) bitriseModels.BitriseDataModel {
+	content, err := yaml.Marshal(mapSlice)
+	require.NoError(t, err)
+	return parseConfig(t, string(content))
+}
+
+func TestMergeBitriseConfig(t *testing.T) {
+	existing := parseMapSlice(t, existingConfig)
+	generated := parseConfig(t, generatedConfig)
+
+	t.Log("merges")
+	{
+		mergedMapSlice, warnings, err := MergeBitriseConfig(existing, generated, MergePrefix(generated))
+		require.NoError(t, err)
+		merged := mapSliceToConfig(t, mergedMapSlice)
+
+		require.Equal(t, "ios", merged.ProjectType)
+
+		workflowIDs := []string{}
+		for workflowID := range merged.Workflows {
+			workflowIDs = append(workflowIDs, workflowID)
+		}
+		require.Equal(t, 4, len(workflowIDs))
+		require.Equal(t, []string{"android-_setup"}, merged.Workflows["android-primary"].BeforeRun)
+		require.Equal(t, parseConfig(t, existingConfig).Workflows["primary"], merged.Workflows["primary"])
+
+		envKeys := []string{}
+		for _, env := range merged.App.Environments {
+			key, _, err := env.GetKeyValuePair()
+			require.NoError(t, err)
+			envKeys = append(envKeys, key)
+		}
+		require.Equal(t, []string{"BITRISE_PROJECT_PATH", "BITRISE_SCHEME", "PROJECT_LOCATION"}, envKeys)
+
+		require.Equal(t, bitriseModels.TriggerMapModel{
+			{PushBranch: "*", WorkflowID: "primary"},
+			{Tag: "*", WorkflowID: "deploy"},
+			{PullRequestSourceBranch: "*", WorkflowID: "android-primary"},
+		}, merged.TriggerMap)
+
+		require.Equal(t, 2, len(warnings))
+		require.True(t, strings.Contains(warnings[0], "BITRISE_SCHEME"))
+		require.True(t, strings.Contains(warnings[1], "push_branch: *"))
+
+		// the existing config is not changed
+		require.Equal(t, parseMapSlice(t, existingConfig), existing)
+	}
+
+	t.Log("workflow id collision")
+	{
+		merged, _, err := MergeBitriseConfig(existing, generated, MergePrefix(generated))
+		require.NoError(t, err)
+
+		_, _, err = MergeBitriseConfig(merged, generated, MergePrefix(generated))
+		require.Error(t, err)
+		collisionErr, ok := err.(WorkflowCollisionError)
+		require.True(t, ok)
+		require.Equal(t, []string{"android-_setup", "android-primary"}, collisionErr.WorkflowIDs)
+
+		_, _, err = MergeBitriseConfig(merged, generated, "android2")
+		require.NoError(t, err)
+	}
+
+	t.Log("keeps the unknown keys and the order of the keys")
+	{
+		existing := parseMapSlice(t, `format_version: "13"
+meta:
+  bitrise.io:
+    stack: linux-docker-android-22.04
+workflows:
+  primary:
+    steps:
+    - git-clone: {}
+pipelines:
+  ci:
+    stages:
+    - test: {}
+stages:
+  test:
+    workflows:
+    - primary: {}
+app:
+  envs:
+  - BITRISE_SCHEME: Sample
+`)
+
+		merged, _, err := MergeBitriseConfig(existing, generated, MergePrefix(generated))
+		require.NoError(t, err)
+
+		keys := []string{}
+		for _, item := range merged {
+			keys = append(keys, item.Key.(string))
+		}
+		require.Equal(t, []string{"format_version", "meta", "workflows", "pipelines", "stages", "app", "default_step_lib_source", "project_type", "trigger_map"}, keys)
+
+		content, err := yaml.Marshal(merged)
+		require.NoError(t, err)
+		require.True(t, strings.Contains(string(content), `meta:
+  bitrise.io:
+    stack: linux-docker-android-22.04
+workflows:
+  primary:
+    steps:
+    - git-clone: {}
+  android-_setup:
+`), string(content))
+		require.True(t, strings.Contains(string(content), `pipelines:
+  ci:
+    stages:
+    - test: {}
+stages:
+  test:
+    workflows:
+    - primary: {}
+app:
+  envs:
+  - BITRISE_SCHEME: Sample
+  - PROJECT_LOCATION: .
+`), string(content))
+	}
+}
+
+func TestConfigDiff(t *testing.T) {
+	diff, err := ConfigDiff("bitrise.yml", "a: 1\nb: 2\n", "a: 1\nb: 3\n")
+	require.NoError(t, err)
+	require.Equal(t, `--- bitrise.yml
++++ bitrise.yml (merged)
+@@ -1,2 +1,2 @@
+ a: 1
+-b: 2
++b: 3
+`, diff)
+}
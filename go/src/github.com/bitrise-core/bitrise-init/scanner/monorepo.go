@@ -0,0 +1,298 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+)
+
+const monorepoProjectType = "other"
+
+// directories, which never contain independent project roots
+var forbiddenProjectRootComponents = []string{".git", "node_modules", "Pods", "Carthage", "CordovaLib", "platforms", "build"}
+
+// isProjectRootMarker returns if the given (searchDir relative) file marks the root of an independent project:
+// Xcode project or workspace, gradle wrapper, Cordova config.xml, ionic.config.json or Xamarin solution.
+func isProjectRootMarker(searchDir, pth string) bool {
+	base := filepath.Base(pth)
+	ext := filepath.Ext(pth)
+
+	switch {
+	case ext == ".xcodeproj":
+		return true
+	case ext == ".xcworkspace":
+		// embedded workspaces of Xcode projects are not independent ones
+		return filepath.Ext(filepath.Dir(pth)) != ".xcodeproj"
+	case ext == ".sln":
+		return true
+	case base == "gradlew", base == "ionic.config.json":
+		return true
+	case base == "config.xml":
+		widget, err := utility.ParseConfigXML(filepath.Join(searchDir, pth))
+		return err == nil && strings.Contains(widget.XMLNSCDV, "cordova.apache.org")
+	}
+	return false
+}
+
+// ProjectRoots returns the (searchDir relative) roots of the independent projects in the searchDir.
+// Projects embedded into an other project (like the native projects of a Cordova or Capacitor app)
+// belong to the outermost project root.
+// A project in the searchDir itself (".") only embeds the nested projects, if it is a Cordova or ionic project.
+func ProjectRoots(searchDir string) ([]string, error) {
+	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
+	}
+
+	filters := []utility.FilterFunc{}
+	for _, component := range forbiddenProjectRootComponents {
+		filters = append(filters, utility.ComponentFilter(component, false))
+	}
+	filters = append(filters, utility.ComponentWithExtensionFilter(".framework", false))
+
+	fileList, err = utility.FilterPaths(fileList, filters...)
+	if err != nil {
+		return []string{}, err
+	}
+
+	// fileList is sorted by components, outer roots are found first
+	roots := []string{}
+	// the scanned dir is an other project's root too, only a hybrid app embeds the nested projects
+	embeddingRootDir := false
+	for _, pth := range fileList {
+		if !isProjectRootMarker(searchDir, pth) {
+			continue
+		}
+
+		root := filepath.Dir(pth)
+		if root == "." && isHybridProjectRootMarker(pth) {
+			embeddingRootDir = true
+		}
+		if isInProjectRoot(root, roots) || (root != "." && embeddingRootDir) {
+			continue
+		}
+		roots = append(roots, root)
+	}
+
+	sort.Strings(roots)
+
+	return roots, nil
+}
+
+// isHybridProjectRootMarker returns if the given project root marker marks a Cordova or ionic project,
+// which embeds its native projects.
+func isHybridProjectRootMarker(pth string) bool {
+	base := filepath.Base(pth)
+	return base == "config.xml" || base == "ionic.config.json"
+}
+
+// isInProjectRoot returns if the pth is one of the roots or it is nested into one of them,
+// the scanned dir (".") only contains the nested roots, see: ProjectRoots.
+func isInProjectRoot(pth string, roots []string) bool {
+	for _, root := range roots {
+		if pth == root || (root != "." && strings.HasPrefix(pth, root+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// MonorepoConfig discovers the independent project roots in the searchDir and scans each of them in isolation.
+// newScanners has to return new scanner instances, as the scanners store the state of the scan they run.
+// The ctx and the scannerTimeout limit the scans the same way as in ConfigWithScanners.
+func MonorepoConfig(ctx context.Context, searchDir string, newScanners func() []scanners.ScannerInterface, scannerTimeout time.Duration) (models.MonorepoScanResultModel, error) {
+	result := models.MonorepoScanResultModel{
+		ProjectResultMap: map[string]models.ScanResultModel{},
+	}
+
+	log.Infoft(colorstring.Blue("Searching for project roots:"))
+
+	roots, err := ProjectRoots(searchDir)
+	if err != nil {
+		return models.MonorepoScanResultModel{}, err
+	}
+
+	log.Printft("%d project roots found", len(roots))
+	for _, root := range roots {
+		log.Printft("- %s", root)
+	}
+	log.Printf("")
+
+	for _, root := range roots {
+		log.Infoft(colorstring.Bluef("Scanning project root: %s", root))
+		log.Printf("")
+
+		projectResult := ConfigWithScanners(ctx, filepath.Join(searchDir, root), newScanners(), scannerTimeout)
+		if len(projectResult.PlatformOptionMap) == 0 && len(projectResult.PlatformWarningsMap) == 0 && len(projectResult.PlatformErrorsMap) == 0 {
+			log.Warnft("No known platform detected in project root: %s", root)
+			log.Printf("")
+			continue
+		}
+
+		result.ProjectResultMap[root] = projectResult
+	}
+
+	return result, nil
+}
+
+// projectIDs returns the ids of the given project roots, used to prefix the projects' workflows,
+// the root's base name is used if it is unique, otherwise the whole root path.
+func projectIDs(roots []string) map[string]string {
+	baseCountMap := map[string]int{}
+	for _, root := range roots {
+		baseCountMap[filepath.Base(root)]++
+	}
+
+	ids := map[string]string{}
+	for _, root := range roots {
+		id := filepath.Base(root)
+		if baseCountMap[id] > 1 {
+			id = strings.Replace(filepath.ToSlash(root), "/", "-", -1)
+		}
+		if root == "." {
+			id = "app"
+		}
+		ids[root] = id
+	}
+	return ids
+}
+
+func renameWorkflowIDs(ids []string, projectID string) []string {
+	renamed := []string{}
+	for _, id := range ids {
+		renamed = append(renamed, projectID+"-"+id)
+	}
+	return renamed
+}
+
+// MonorepoBitriseConfig merges the configs of the project roots into one bitrise config.
+// Every project gets its own workflow set (prefixed with the project id), which starts in the project root.
+// The project's trigger map items become the triggers of its workflows, the push and pull request triggers
+// only trigger the workflow if files changed in the project root.
+func MonorepoBitriseConfig(projectConfigMap map[string]bitriseModels.BitriseDataModel) (models.MonorepoBitriseDataModel, error) {
+	roots := []string{}
+	for root := range projectConfigMap {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	ids := projectIDs(roots)
+
+	config := models.MonorepoBitriseDataModel{
+		FormatVersion: models.FormatVersion,
+		ProjectType:   monorepoProjectType,
+		Workflows:     map[string]models.MonorepoWorkflowModel{},
+	}
+
+	for _, root := range roots {
+		projectConfig := projectConfigMap[root]
+		projectID := ids[root]
+
+		if config.DefaultStepLibSource == "" {
+			config.DefaultStepLibSource = projectConfig.DefaultStepLibSource
+		}
+
+		workflowIDs := []string{}
+		for workflowID := range projectConfig.Workflows {
+			workflowIDs = append(workflowIDs, workflowID)
+		}
+		sort.Strings(workflowIDs)
+
+		for _, workflowID := range workflowIDs {
+			workflow := projectConfig.Workflows[workflowID]
+
+			// app envs of the projects would collide, they are moved to the project's workflows
+			workflow.Environments = append(append([]envmanModels.EnvironmentItemModel{}, projectConfig.App.Environments...), workflow.Environments...)
+			workflow.BeforeRun = renameWorkflowIDs(workflow.BeforeRun, projectID)
+			workflow.AfterRun = renameWorkflowIDs(workflow.AfterRun, projectID)
+
+			if root != "." {
+				stepList, err := changeWorkDirAfterGitClone(workflow.Steps, "$BITRISE_SOURCE_DIR/"+filepath.ToSlash(root))
+				if err != nil {
+					return models.MonorepoBitriseDataModel{}, fmt.Errorf("project (%s) workflow (%s): %s", root, workflowID, err)
+				}
+				workflow.Steps = stepList
+			}
+
+			config.Workflows[projectID+"-"+workflowID] = models.MonorepoWorkflowModel{WorkflowModel: workflow}
+		}
+
+		changedFiles := ""
+		if root != "." {
+			changedFiles = filepath.ToSlash(root) + "/**"
+		}
+
+		for _, item := range projectConfig.TriggerMap {
+			workflowID := projectID + "-" + item.WorkflowID
+			workflow, ok := config.Workflows[workflowID]
+			if !ok {
+				return models.MonorepoBitriseDataModel{}, fmt.Errorf("project (%s) trigger map: workflow (%s) not found", root, item.WorkflowID)
+			}
+			if workflow.Triggers == nil {
+				workflow.Triggers = &models.MonorepoWorkflowTriggersModel{}
+			}
+
+			switch {
+			case item.PushBranch != "":
+				workflow.Triggers.Push = append(workflow.Triggers.Push, models.MonorepoPushTriggerModel{
+					Branch:       item.PushBranch,
+					ChangedFiles: changedFiles,
+				})
+			case item.PullRequestSourceBranch != "" || item.PullRequestTargetBranch != "":
+				workflow.Triggers.PullRequest = append(workflow.Triggers.PullRequest, models.MonorepoPullRequestTriggerModel{
+					SourceBranch: item.PullRequestSourceBranch,
+					TargetBranch: item.PullRequestTargetBranch,
+					ChangedFiles: changedFiles,
+				})
+			case item.Tag != "":
+				workflow.Triggers.Tag = append(workflow.Triggers.Tag, models.MonorepoTagTriggerModel{
+					Name: item.Tag,
+				})
+			}
+
+			config.Workflows[workflowID] = workflow
+		}
+	}
+
+	return config, nil
+}
+
+// changeWorkDirAfterGitClone inserts a change-workdir step to the dir after the git-clone step of the stepList,
+// so that the project's own steps run in its root.
+func changeWorkDirAfterGitClone(stepList []bitriseModels.StepListItemModel, dir string) ([]bitriseModels.StepListItemModel, error) {
+	changeWorkDirStep := steps.ChangeWorkDirStepListItem(envmanModels.EnvironmentItemModel{steps.ChangeWorkDirInputPathKey: dir})
+
+	newStepList := []bitriseModels.StepListItemModel{}
+	inserted := false
+	for _, stepListItem := range stepList {
+		newStepList = append(newStepList, stepListItem)
+
+		stepID, _, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+		if err != nil {
+			return []bitriseModels.StepListItemModel{}, err
+		}
+
+		if !inserted && strings.HasPrefix(stepID, steps.GitCloneID+"@") {
+			newStepList = append(newStepList, changeWorkDirStep)
+			inserted = true
+		}
+	}
+
+	if !inserted {
+		newStepList = append([]bitriseModels.StepListItemModel{changeWorkDirStep}, newStepList...)
+	}
+
+	return newStepList, nil
+}
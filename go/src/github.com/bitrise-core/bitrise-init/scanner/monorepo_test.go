@@ -0,0 +1,191 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/steps"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const monorepoFixtureDir = "testdata/monorepo"
+
+func TestProjectRoots(t *testing.T) {
+	t.Log("independent project roots")
+	{
+		roots, err := ProjectRoots(monorepoFixtureDir)
+		require.NoError(t, err)
+		require.Equal(t, []string{"apps/admin", "apps/shop-android", "apps/shop-ios"}, roots)
+	}
+
+	t.Log("project in the scanned dir")
+	{
+		roots, err := ProjectRoots(fixturesDir + "/nested-projects")
+		require.NoError(t, err)
+		require.Equal(t, []string{"android", "ios"}, roots)
+
+		roots, err = ProjectRoots(fixturesDir + "/android-nested")
+		require.NoError(t, err)
+		require.Equal(t, []string{"android"}, roots)
+
+		roots, err = ProjectRoots(fixturesDir + "/ionic-capacitor")
+		require.NoError(t, err)
+		require.Equal(t, []string{"."}, roots)
+	}
+
+	t.Log("project in the scanned dir, next to nested projects")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__monorepo__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		for _, pth := range []string{"gradlew", "apps/shop-ios/Shop.xcodeproj/project.pbxproj"} {
+			pth = filepath.Join(tmpDir, pth)
+			require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0777))
+			require.NoError(t, fileutil.WriteStringToFile(pth, ""))
+		}
+
+		roots, err := ProjectRoots(tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, []string{".", "apps/shop-ios"}, roots)
+	}
+
+	t.Log("no project")
+	{
+		roots, err := ProjectRoots(fixturesDir + "/no-platform")
+		require.NoError(t, err)
+		require.Equal(t, []string{}, roots)
+	}
+}
+
+func TestMonorepoConfig(t *testing.T) {
+	searchDir, err := pathutil.AbsPath(monorepoFixtureDir)
+	require.NoError(t, err)
+
+	result, err := MonorepoConfig(context.Background(), searchDir, scanners.NewActiveScanners, 0)
+	require.NoError(t, err)
+
+	platformsMap := map[string][]string{}
+	for root, projectResult := range result.ProjectResultMap {
+		for platform := range projectResult.PlatformOptionMap {
+			platformsMap[root] = append(platformsMap[root], platform)
+		}
+	}
+
+	require.Equal(t, map[string][]string{
+		"apps/admin":        []string{"cordova"},
+		"apps/shop-android": []string{"android"},
+		"apps/shop-ios":     []string{"ios"},
+	}, platformsMap)
+}
+
+func testProjectConfig(t *testing.T, projectType string, appEnvs ...envmanModels.EnvironmentItemModel) bitriseModels.BitriseDataModel {
+	configBuilder := models.NewDefaultConfigBuilder()
+	configBuilder.AppendMainStepList(steps.ScriptSteplistItem("Build " + projectType))
+	configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+
+	config, err := configBuilder.Generate(projectType, appEnvs...)
+	require.NoError(t, err)
+
+	// use the config the way it is read back from the scan result
+	data, err := yaml.Marshal(config)
+	require.NoError(t, err)
+
+	var projectConfig bitriseModels.BitriseDataModel
+	require.NoError(t, yaml.Unmarshal(data, &projectConfig))
+	return projectConfig
+}
+
+func TestMonorepoBitriseConfig(t *testing.T) {
+	t.Log("one workflow set per project")
+	{
+		config, err := MonorepoBitriseConfig(map[string]bitriseModels.BitriseDataModel{
+			"apps/shop-android": testProjectConfig(t, "android", envmanModels.EnvironmentItemModel{"GRADLE_TASK": "assembleRelease"}),
+			"apps/shop-ios":     testProjectConfig(t, "ios"),
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "other", config.ProjectType)
+		require.Equal(t, &models.MonorepoWorkflowTriggersModel{
+			Push:        []models.MonorepoPushTriggerModel{{Branch: "*", ChangedFiles: "apps/shop-android/**"}},
+			PullRequest: []models.MonorepoPullRequestTriggerModel{{SourceBranch: "*", ChangedFiles: "apps/shop-android/**"}},
+		}, config.Workflows["shop-android-primary"].Triggers)
+		require.Equal(t, &models.MonorepoWorkflowTriggersModel{
+			Push:        []models.MonorepoPushTriggerModel{{Branch: "*", ChangedFiles: "apps/shop-ios/**"}},
+			PullRequest: []models.MonorepoPullRequestTriggerModel{{SourceBranch: "*", ChangedFiles: "apps/shop-ios/**"}},
+		}, config.Workflows["shop-ios-primary"].Triggers)
+		require.Nil(t, config.Workflows["shop-ios-deploy"].Triggers)
+
+		// the workflows' triggers are serialized next to their steps, there is no trigger_map
+		data, err := yaml.Marshal(config)
+		require.NoError(t, err)
+		require.False(t, strings.Contains(string(data), "trigger_map"))
+		require.True(t, strings.Contains(string(data), `
+  shop-ios-primary:
+    triggers:
+      push:
+      - branch: '*'
+        changed_files: apps/shop-ios/**
+      pull_request:
+      - source_branch: '*'
+        changed_files: apps/shop-ios/**
+    steps:
+`), string(data))
+
+		workflowIDs := []string{}
+		for workflowID := range config.Workflows {
+			workflowIDs = append(workflowIDs, workflowID)
+		}
+		sort.Strings(workflowIDs)
+		require.Equal(t, []string{"shop-android-deploy", "shop-android-primary", "shop-ios-deploy", "shop-ios-primary"}, workflowIDs)
+
+		workflow := config.Workflows["shop-android-primary"]
+		require.Equal(t, []envmanModels.EnvironmentItemModel{{"GRADLE_TASK": "assembleRelease"}}, workflow.Environments)
+
+		stepIDs := []string{}
+		for _, stepListItem := range workflow.Steps {
+			stepID, _, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+			require.NoError(t, err)
+			stepIDs = append(stepIDs, stepID)
+		}
+		require.Equal(t, []string{
+			steps.ActivateSSHKeyID + "@" + steps.ActivateSSHKeyVersion,
+			steps.GitCloneID + "@" + steps.GitCloneVersion,
+			steps.ChangeWorkDirID + "@" + steps.ChangeWorkDirVersion,
+			steps.ScriptID + "@" + steps.ScriptVersion,
+			steps.ScriptID + "@" + steps.ScriptVersion,
+			steps.DeployToBitriseIoID + "@" + steps.DeployToBitriseIoVersion,
+		}, stepIDs)
+
+		_, changeWorkDirStep, err := bitriseModels.GetStepIDStepDataPair(workflow.Steps[2])
+		require.NoError(t, err)
+		require.Equal(t, envmanModels.EnvironmentItemModel{steps.ChangeWorkDirInputPathKey: "$BITRISE_SOURCE_DIR/apps/shop-android"}, changeWorkDirStep.Inputs[0])
+	}
+
+	t.Log("projects with the same base name")
+	{
+		config, err := MonorepoBitriseConfig(map[string]bitriseModels.BitriseDataModel{
+			"shop/android":  testProjectConfig(t, "android"),
+			"admin/android": testProjectConfig(t, "android"),
+		})
+		require.NoError(t, err)
+
+		_, ok := config.Workflows["shop-android-primary"]
+		require.True(t, ok)
+		_, ok = config.Workflows["admin-android-primary"]
+		require.True(t, ok)
+	}
+}
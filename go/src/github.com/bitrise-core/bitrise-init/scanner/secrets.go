@@ -0,0 +1,277 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pointers"
+	"github.com/bitrise-io/go-utils/sliceutil"
+)
+
+// SecretsScannerName is the scanner name of the secret findings' warning events.
+const SecretsScannerName = "secrets"
+
+const (
+	androidKeystoreURLEnvKey                = "BITRISEIO_ANDROID_KEYSTORE_URL"
+	androidKeystorePasswordEnvKey           = "BITRISEIO_ANDROID_KEYSTORE_PASSWORD"
+	androidKeystoreAliasEnvKey              = "BITRISEIO_ANDROID_KEYSTORE_ALIAS"
+	androidKeystorePrivateKeyPasswordEnvKey = "BITRISEIO_ANDROID_KEYSTORE_PRIVATE_KEY_PASSWORD"
+	certificateURLEnvKey                    = "BITRISE_CERTIFICATE_URL"
+	certificatePassphraseEnvKey             = "BITRISE_CERTIFICATE_PASSPHRASE"
+	provisionURLEnvKey                      = "BITRISE_PROVISION_URL"
+	googleServicesJSONURLEnvKey             = "BITRISEIO_GOOGLE_SERVICES_JSON_URL"
+)
+
+// secretSkipDirs are not walked by the secret scan, they hold dependencies or build outputs, not committed credentials.
+var secretSkipDirs = []string{".git", "node_modules", "Pods", "Carthage", ".gradle", "build"}
+
+// envFileTemplateSuffixes mark the .env files documenting the envs, without real values.
+var envFileTemplateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+var envFileLineRegexp = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// ScanSecrets walks the searchDir and returns the credentials committed to the repository,
+// the findings' paths are searchDir relative. The unreadable files and dirs are logged and skipped,
+// if the scan is canceled the findings collected so far are returned with the error.
+func ScanSecrets(ctx context.Context, searchDir string) ([]models.SecretFindingModel, error) {
+	findings := []models.SecretFindingModel{}
+
+	if err := filepath.Walk(searchDir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			if pth == searchDir {
+				return err
+			}
+			log.Warnft("Failed to read (%s), skipping it, error: %s", pth, err)
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if pth != searchDir && sliceutil.IsStringInSlice(info.Name(), secretSkipDirs) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPth, err := filepath.Rel(searchDir, pth)
+		if err != nil {
+			return err
+		}
+
+		finding, err := secretFinding(pth, relPth)
+		if err != nil {
+			log.Warnft("Failed to check (%s), skipping it, error: %s", relPth, err)
+			return nil
+		}
+		if finding != nil {
+			findings = append(findings, *finding)
+		}
+		return nil
+	}); err != nil {
+		return findings, err
+	}
+
+	return findings, nil
+}
+
+// secretFinding returns the finding of the file at pth, or nil if the file does not hold credentials.
+func secretFinding(pth, relPth string) (*models.SecretFindingModel, error) {
+	base := filepath.Base(pth)
+	ext := strings.ToLower(filepath.Ext(pth))
+
+	switch {
+	case base == "debug.keystore":
+		// the Android SDK generated debug keystore has well known credentials
+		return nil, nil
+	case ext == ".jks" || ext == ".keystore":
+		return &models.SecretFindingModel{
+			Kind:       models.SecretFindingKindAndroidKeystore,
+			Path:       relPth,
+			Message:    "Android keystore committed to the repository, upload it to the Code Signing tab and remove it from the repository",
+			SecretEnvs: []string{androidKeystoreURLEnvKey, androidKeystorePasswordEnvKey, androidKeystoreAliasEnvKey, androidKeystorePrivateKeyPasswordEnvKey},
+		}, nil
+	case base == "keystore.properties":
+		return &models.SecretFindingModel{
+			Kind:       models.SecretFindingKindAndroidKeystoreProperties,
+			Path:       relPth,
+			Message:    "Android keystore passwords committed to the repository, read them from secret envs instead",
+			SecretEnvs: []string{androidKeystorePasswordEnvKey, androidKeystoreAliasEnvKey, androidKeystorePrivateKeyPasswordEnvKey},
+		}, nil
+	case ext == ".p12":
+		return &models.SecretFindingModel{
+			Kind:       models.SecretFindingKindCertificate,
+			Path:       relPth,
+			Message:    "Code signing certificate committed to the repository, upload it to the Code Signing tab and remove it from the repository",
+			SecretEnvs: []string{certificateURLEnvKey, certificatePassphraseEnvKey},
+		}, nil
+	case ext == ".mobileprovision":
+		return &models.SecretFindingModel{
+			Kind:       models.SecretFindingKindProvisioningProfile,
+			Path:       relPth,
+			Message:    "Provisioning profile committed to the repository, upload it to the Code Signing tab and remove it from the repository",
+			SecretEnvs: []string{provisionURLEnvKey},
+		}, nil
+	case base == "google-services.json":
+		hasAPIKey, err := googleServicesHasAPIKey(pth)
+		if err != nil || !hasAPIKey {
+			return nil, err
+		}
+		return &models.SecretFindingModel{
+			Kind:       models.SecretFindingKindGoogleServices,
+			Path:       relPth,
+			Message:    "Google services config with an API key committed to the repository, upload it to the Generic File Storage and download it during the build",
+			SecretEnvs: []string{googleServicesJSONURLEnvKey},
+		}, nil
+	case isEnvFile(base):
+		envKeys, err := envFileKeys(pth)
+		if err != nil || len(envKeys) == 0 {
+			return nil, err
+		}
+		return &models.SecretFindingModel{
+			Kind:       models.SecretFindingKindEnvFile,
+			Path:       relPth,
+			Message:    "Env file committed to the repository, define its envs as secrets instead",
+			SecretEnvs: envKeys,
+		}, nil
+	}
+	return nil, nil
+}
+
+// isEnvFile reports whether the base is a .env file (.env, .env.production), skipping the env templates (.env.example).
+func isEnvFile(base string) bool {
+	if base != ".env" && !strings.HasPrefix(base, ".env.") {
+		return false
+	}
+	for _, suffix := range envFileTemplateSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// envFileKeys returns the keys of the .env file, which have a value.
+func envFileKeys(pth string) ([]string, error) {
+	content, err := fileutil.ReadStringFromFile(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := envFileLineRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		key, value := match[1], strings.Trim(strings.TrimSpace(match[2]), `"'`)
+		if value == "" || sliceutil.IsStringInSlice(key, keys) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+// googleServicesHasAPIKey reports whether the google-services.json at pth holds a client API key.
+func googleServicesHasAPIKey(pth string) (bool, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return false, err
+	}
+
+	var config struct {
+		Client []struct {
+			APIKey []struct {
+				CurrentKey string `json:"current_key"`
+			} `json:"api_key"`
+		} `json:"client"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		// not a valid google services config, nothing to report
+		return false, nil
+	}
+
+	for _, client := range config.Client {
+		for _, apiKey := range client.APIKey {
+			if apiKey.CurrentKey != "" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// SecretEnvsTemplate returns the .bitrise.secrets.yml template of the findings' secret envs,
+// every secret env is listed once, with the committed files it replaces.
+func SecretEnvsTemplate(findings []models.SecretFindingModel) envmanModels.EnvsSerializeModel {
+	keys := []string{}
+	keyPathsMap := map[string][]string{}
+	for _, finding := range findings {
+		for _, key := range finding.SecretEnvs {
+			if _, ok := keyPathsMap[key]; !ok {
+				keys = append(keys, key)
+			}
+			keyPathsMap[key] = append(keyPathsMap[key], finding.Path)
+		}
+	}
+
+	envs := []envmanModels.EnvironmentItemModel{}
+	for _, key := range keys {
+		envs = append(envs, envmanModels.EnvironmentItemModel{
+			key: "",
+			envmanModels.OptionsKey: envmanModels.EnvironmentItemOptionsModel{
+				Description: pointers.NewStringPtr(fmt.Sprintf("Replaces the committed: %s", strings.Join(keyPathsMap[key], ", "))),
+			},
+		})
+	}
+	return envmanModels.EnvsSerializeModel{Envs: envs}
+}
+
+// scanSecrets runs the secret scan on the searchDir, logs and emits the findings as warnings.
+func scanSecrets(ctx context.Context, searchDir string) []models.SecretFindingModel {
+	log.Infoft("Scanning for committed secrets:")
+
+	findings, err := ScanSecrets(ctx, searchDir)
+	if err != nil {
+		log.Warnft("Failed to scan for secrets, error: %s", err)
+	} else if len(findings) == 0 {
+		log.Printft("no secrets found")
+	}
+	for _, finding := range findings {
+		log.Warnft("%s: %s", finding.Path, finding.Message)
+		log.Printft("  suggested secret envs: %s", colorstring.Yellow(strings.Join(finding.SecretEnvs, ", ")))
+
+		emitWarnings(searchDir, SecretsScannerName, fmt.Sprintf("%s: %s", finding.Path, finding.Message))
+	}
+	log.Printf("")
+
+	if len(findings) == 0 {
+		return nil
+	}
+	return findings
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecretTestFiles(t *testing.T, dir string, fileContentMap map[string]string) {
+	for pth, content := range fileContentMap {
+		pth = filepath.Join(dir, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0777))
+		require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	}
+}
+
+func TestScanSecrets(t *testing.T) {
+	t.Log("committed credentials")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__secrets__")
+		require.NoError(t, err)
+
+		writeSecretTestFiles(t, tmpDir, map[string]string{
+			"app/release.jks":                            "keystore",
+			"app/debug.keystore":                         "debug keystore",
+			"keystore.properties":                        "storePassword=pass",
+			"ios/dist.p12":                               "certificate",
+			"ios/app.mobileprovision":                    "profile",
+			"app/google-services.json":                   `{"client":[{"api_key":[{"current_key":"AIza"}]}]}`,
+			"fastlane/.env":                              "# comment\nexport MATCH_PASSWORD=secret\nEMPTY=\nAPI_TOKEN=\"token\"\n",
+			".env.example":                               "API_TOKEN=placeholder",
+			"node_modules/dep/release.jks":               "dependency",
+			"android/build/outputs/google-services.json": `{"client":[{"api_key":[{"current_key":"AIza"}]}]}`,
+		})
+
+		findings, err := ScanSecrets(context.Background(), tmpDir)
+		require.NoError(t, err)
+
+		kindPathMap := map[models.SecretFindingKind]string{}
+		for _, finding := range findings {
+			kindPathMap[finding.Kind] = finding.Path
+		}
+		require.Equal(t, map[models.SecretFindingKind]string{
+			models.SecretFindingKindAndroidKeystore:           "app/release.jks",
+			models.SecretFindingKindAndroidKeystoreProperties: "keystore.properties",
+			models.SecretFindingKindCertificate:               "ios/dist.p12",
+			models.SecretFindingKindProvisioningProfile:       "ios/app.mobileprovision",
+			models.SecretFindingKindGoogleServices:            "app/google-services.json",
+			models.SecretFindingKindEnvFile:                   "fastlane/.env",
+		}, kindPathMap)
+
+		for _, finding := range findings {
+			if finding.Kind == models.SecretFindingKindEnvFile {
+				require.Equal(t, []string{"MATCH_PASSWORD", "API_TOKEN"}, finding.SecretEnvs)
+			}
+		}
+	}
+
+	t.Log("google-services.json without api key")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__secrets__")
+		require.NoError(t, err)
+
+		writeSecretTestFiles(t, tmpDir, map[string]string{
+			"app/google-services.json": `{"client":[{"api_key":[]}]}`,
+		})
+
+		findings, err := ScanSecrets(context.Background(), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(findings))
+	}
+
+	t.Log("unreadable file is skipped")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__secrets__")
+		require.NoError(t, err)
+
+		writeSecretTestFiles(t, tmpDir, map[string]string{
+			"app/release.jks": "keystore",
+			// the line is longer than the max token size of the .env reader
+			".env": "API_TOKEN=" + strings.Repeat("x", bufio.MaxScanTokenSize),
+		})
+
+		findings, err := ScanSecrets(context.Background(), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(findings))
+		require.Equal(t, "app/release.jks", findings[0].Path)
+	}
+
+	t.Log("canceled scan")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := ScanSecrets(ctx, fixturesDir)
+		require.Error(t, err)
+	}
+}
+
+func TestSecretEnvsTemplate(t *testing.T) {
+	template := SecretEnvsTemplate([]models.SecretFindingModel{
+		{Path: "release.jks", SecretEnvs: []string{"BITRISEIO_ANDROID_KEYSTORE_URL", "BITRISEIO_ANDROID_KEYSTORE_PASSWORD"}},
+		{Path: "keystore.properties", SecretEnvs: []string{"BITRISEIO_ANDROID_KEYSTORE_PASSWORD"}},
+	})
+
+	require.Equal(t, 2, len(template.Envs))
+
+	key, value, err := template.Envs[0].GetKeyValuePair()
+	require.NoError(t, err)
+	require.Equal(t, "BITRISEIO_ANDROID_KEYSTORE_URL", key)
+	require.Equal(t, "", value)
+
+	key, _, err = template.Envs[1].GetKeyValuePair()
+	require.NoError(t, err)
+	require.Equal(t, "BITRISEIO_ANDROID_KEYSTORE_PASSWORD", key)
+
+	options, err := template.Envs[1].GetOptions()
+	require.NoError(t, err)
+	require.Equal(t, "Replaces the committed: release.jks, keystore.properties", *options.Description)
+
+}
+
+func TestConfigSecretEnvs(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__secrets__")
+	require.NoError(t, err)
+
+	writeSecretTestFiles(t, tmpDir, map[string]string{
+		"app/release.jks": "keystore",
+	})
+
+	result := ConfigWithScanners(context.Background(), tmpDir, nil, 0)
+	require.Equal(t, 1, len(result.SecretFindings))
+
+	keys := []string{}
+	for _, env := range result.SecretEnvs {
+		key, _, err := env.GetKeyValuePair()
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	require.Equal(t, []string{"BITRISEIO_ANDROID_KEYSTORE_URL", "BITRISEIO_ANDROID_KEYSTORE_PASSWORD", "BITRISEIO_ANDROID_KEYSTORE_ALIAS", "BITRISEIO_ANDROID_KEYSTORE_PRIVATE_KEY_PASSWORD"}, keys)
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-io/go-utils/sliceutil"
+)
+
+// SelectScanners filters the projectScanners by name:
+// if onlyNames is not empty only the listed scanners are kept, the scanners listed in skipNames are dropped.
+// Returns the selected scanners and the names of the deliberately skipped ones.
+func SelectScanners(projectScanners []scanners.ScannerInterface, onlyNames, skipNames []string) ([]scanners.ScannerInterface, []string, error) {
+	names := []string{}
+	for _, scanner := range projectScanners {
+		names = append(names, scanner.Name())
+	}
+
+	for _, name := range append(append([]string{}, onlyNames...), skipNames...) {
+		if !sliceutil.IsStringInSlice(name, names) {
+			return []scanners.ScannerInterface{}, []string{}, fmt.Errorf("unknown scanner: %s, available scanners: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	selectedScanners := []scanners.ScannerInterface{}
+	skippedScanners := []string{}
+	for _, scanner := range projectScanners {
+		name := scanner.Name()
+
+		if (len(onlyNames) > 0 && !sliceutil.IsStringInSlice(name, onlyNames)) || sliceutil.IsStringInSlice(name, skipNames) {
+			skippedScanners = append(skippedScanners, name)
+			continue
+		}
+
+		selectedScanners = append(selectedScanners, scanner)
+	}
+
+	return selectedScanners, skippedScanners, nil
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/stretchr/testify/require"
+)
+
+func scannerNames(projectScanners []scanners.ScannerInterface) []string {
+	names := []string{}
+	for _, scanner := range projectScanners {
+		names = append(names, scanner.Name())
+	}
+	return names
+}
+
+func TestSelectScanners(t *testing.T) {
+	t.Log("no filter")
+	{
+		selected, skipped, err := SelectScanners(scanners.NewActiveScanners(), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, scannerNames(scanners.NewActiveScanners()), scannerNames(selected))
+		require.Equal(t, []string{}, skipped)
+	}
+
+	t.Log("only the listed scanners")
+	{
+		selected, skipped, err := SelectScanners(scanners.NewActiveScanners(), []string{"android", "ios"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"ios", "android"}, scannerNames(selected))
+		require.Equal(t, []string{"cordova", "kotlin-multiplatform", "macos", "xamarin", "fastlane", "ionic"}, skipped)
+	}
+
+	t.Log("skip the listed scanners")
+	{
+		selected, skipped, err := SelectScanners(scanners.NewActiveScanners(), nil, []string{"fastlane"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"cordova", "kotlin-multiplatform", "ios", "macos", "android", "xamarin", "ionic"}, scannerNames(selected))
+		require.Equal(t, []string{"fastlane"}, skipped)
+	}
+
+	t.Log("only and skip")
+	{
+		selected, skipped, err := SelectScanners(scanners.NewActiveScanners(), []string{"ios", "fastlane"}, []string{"fastlane"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"ios"}, scannerNames(selected))
+		require.Equal(t, []string{"cordova", "kotlin-multiplatform", "macos", "android", "xamarin", "fastlane", "ionic"}, skipped)
+	}
+
+	t.Log("unknown scanner")
+	{
+		_, _, err := SelectScanners(scanners.NewActiveScanners(), []string{"unity"}, nil)
+		require.EqualError(t, err, "unknown scanner: unity, available scanners: cordova, kotlin-multiplatform, ios, macos, android, xamarin, fastlane, ionic")
+	}
+}
@@ -0,0 +1,3 @@
+package io.bitrise.sample
+
+class ExampleUnitTest
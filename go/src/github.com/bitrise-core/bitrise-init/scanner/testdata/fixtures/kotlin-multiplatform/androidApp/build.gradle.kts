@@ -0,0 +1,20 @@
+plugins {
+    id("com.android.application")
+    kotlin("android")
+}
+
+android {
+    namespace = "io.bitrise.sample.android"
+    compileSdk = 34
+    defaultConfig {
+        applicationId = "io.bitrise.sample.android"
+        minSdk = 24
+        targetSdk = 34
+        versionCode = 1
+        versionName = "1.0"
+    }
+}
+
+dependencies {
+    implementation(project(":shared"))
+}
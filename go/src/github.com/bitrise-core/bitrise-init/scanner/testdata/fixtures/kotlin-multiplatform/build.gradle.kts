@@ -0,0 +1,6 @@
+plugins {
+    kotlin("multiplatform") version "2.0.21" apply false
+    kotlin("android") version "2.0.21" apply false
+    id("com.android.application") version "8.7.0" apply false
+    id("com.android.library") version "8.7.0" apply false
+}
@@ -0,0 +1,8 @@
+import SwiftUI
+import shared
+
+struct ContentView: View {
+    var body: some View {
+        Text(Greeting().greet())
+    }
+}
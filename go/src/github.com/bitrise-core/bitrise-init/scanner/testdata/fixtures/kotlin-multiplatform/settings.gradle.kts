@@ -0,0 +1,4 @@
+rootProject.name = "KotlinMultiplatformSample"
+
+include(":androidApp")
+include(":shared")
@@ -0,0 +1,9 @@
+import kotlin.test.Test
+import kotlin.test.assertTrue
+
+class GreetingTest {
+    @Test
+    fun testGreeting() {
+        assertTrue(Greeting().greet().contains("Hello"))
+    }
+}
@@ -31,14 +31,25 @@ func askForOptionValue(option models.OptionModel) (string, string, error) {
 			selectedValue = optionValues[0]
 		}
 	} else {
-		// select from values
+		// select from values, described values are displayed as: value — description
+		valueByItem := map[string]string{}
+		items := []string{}
+		for _, value := range optionValues {
+			item := value
+			if description := option.ValueDescriptionMap[value]; description != "" {
+				item = fmt.Sprintf("%s — %s", value, description)
+			}
+			valueByItem[item] = value
+			items = append(items, item)
+		}
+
 		question := fmt.Sprintf("Select: %s", option.Title)
-		answer, err := goinp.SelectFromStrings(question, optionValues)
+		answer, err := goinp.SelectFromStrings(question, items)
 		if err != nil {
 			return "", "", err
 		}
 
-		selectedValue = answer
+		selectedValue = valueByItem[answer]
 	}
 
 	return option.EnvKey, selectedValue, nil
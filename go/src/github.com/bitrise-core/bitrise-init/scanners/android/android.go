@@ -1,7 +1,9 @@
 package android
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
@@ -10,7 +12,9 @@ import (
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
 	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
 	"github.com/bitrise-io/go-utils/log"
 )
 
@@ -18,10 +22,13 @@ import (
 const ScannerName = "android"
 
 const (
-	configName        = "android-config"
-	defaultConfigName = "default-android-config"
+	configName                = "android-config"
+	qualifiedConfigNameFormat = "android-in-%s-config"
+	defaultConfigName         = "default-android-config"
 )
 
+const sourceDirEnv = "$BITRISE_SOURCE_DIR"
+
 // Step Inputs
 const (
 	gradlewPathInputKey    = "gradlew_path"
@@ -53,16 +60,81 @@ var defaultGradleTasks = []string{
 	"assembleRelease",
 }
 
+// The deploy workflow's gradle tasks, building the release APK or App Bundle.
+const (
+	assembleReleaseGradleTask = "assembleRelease"
+	bundleReleaseGradleTask   = "bundleRelease"
+)
+
+// The deploy workflow's signing and deploy step inputs.
+const (
+	androidAppInputKey = "android_app"
+	deployPathInputKey = "deploy_path"
+)
+
+// The APK and App Bundle outputs of the gradle-runner and sign-apk steps.
+const (
+	apkPathEnv       = "$BITRISE_APK_PATH"
+	aabPathEnv       = "$BITRISE_AAB_PATH"
+	signedAPKPathEnv = "$BITRISE_SIGNED_APK_PATH"
+	signedAABPathEnv = "$BITRISE_SIGNED_AAB_PATH"
+)
+
+// The lint gradle tasks of the detekt and ktlint gradle plugins.
+const (
+	detektGradleTask = "detekt"
+	ktlintGradleTask = "ktlintCheck"
+)
+
+// instrumentedTestWorkflowID is only generated if the project has instrumented tests (androidTest sources).
+const instrumentedTestWorkflowID models.WorkflowID = "instrumented-test"
+
+const setJavaVersionInputKey = "set_java_version"
+
+const (
+	moduleInputKey          = "module"
+	variantInputKey         = "variant"
+	instrumentedTestVariant = "debug"
+	testTypeInputKey        = "test_type"
+	instrumentationTestType = "instrumentation"
+)
+
+const keystoreSecretsDescription = "the deploy workflow signs the build with the BITRISEIO_ANDROID_KEYSTORE_* secrets"
+
 //------------------
 // ScannerInterface
 //------------------
 
+// ConfigDescriptor describes the config of a gradle wrapper's project.
+type ConfigDescriptor struct {
+	// RelGradlewDir is the (scanned dir relative) directory of the gradle wrapper, it is empty for the scanned dir.
+	RelGradlewDir    string
+	DeployGradleTask string
+	JDKVersion       string
+
+	HasUnitTests           bool
+	InstrumentedTestModule string
+	LintTools              []utility.AndroidLintTool
+}
+
+// ConfigName returns the name of the descriptor's config,
+// the config names are qualified with the gradle wrapper dir, if the scanned dir has multiple gradle wrapper projects.
+func (descriptor ConfigDescriptor) ConfigName(qualified bool) string {
+	if !qualified || descriptor.RelGradlewDir == "" {
+		return configName
+	}
+	return fmt.Sprintf(qualifiedConfigNameFormat, strings.Replace(filepath.ToSlash(descriptor.RelGradlewDir), "/", "-", -1))
+}
+
 // Scanner ...
 type Scanner struct {
 	FileList         []string
 	BuildGradleFiles []string
 	SearchDir        string
-	RelGradlewDir    string
+
+	configDescriptors []ConfigDescriptor
+	scanWarnings      []models.ScanWarningModel
+	stack             *models.StackModel
 }
 
 // NewScanner ...
@@ -76,7 +148,7 @@ func (scanner Scanner) Name() string {
 }
 
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
 	scanner.SearchDir = searchDir
 
 	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
@@ -115,7 +187,7 @@ func (scanner *Scanner) ExcludedScannerNames() []string {
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
 	// Search for gradle wrapper
 	log.Infoft("Searching for gradlew files")
 
@@ -130,71 +202,261 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 		log.Printft("- %s", file)
 	}
 
-	rootGradlewPath := ""
-	gradlewFilesCount := len(gradlewFiles)
-	switch {
-	case gradlewFilesCount == 0:
+	if len(gradlewFiles) == 0 {
 		log.Errorft("No gradle wrapper (gradlew) found")
 		return models.OptionModel{}, warnings, fmt.Errorf(`<b>No Gradle Wrapper (gradlew) found.</b> 
 Using a Gradle Wrapper (gradlew) is required, as the wrapper is what makes sure
 that the right Gradle version is installed and used for the build. More info/guide: <a>https://docs.gradle.org/current/userguide/gradle_wrapper.html</a>`)
-	case gradlewFilesCount == 1:
-		rootGradlewPath = gradlewFiles[0]
-	case gradlewFilesCount > 1:
-		rootGradlewPath = gradlewFiles[0]
-		log.Warnft("Multiple gradlew file, detected:")
-		for _, gradlewPth := range gradlewFiles {
-			log.Warnft("- %s", gradlewPth)
+	}
+	// ---
+
+	// Assign the build.gradle files to their gradle wrapper
+	projectGradlewFiles := []string{}
+	gradlewGradleFilesMap := map[string][]string{}
+	for _, gradlewPth := range gradlewFiles {
+		gradleFiles, err := utility.GradlewRootBuildGradleFiles(scanner.FileList, gradlewPth, gradlewFiles)
+		if err != nil {
+			return models.OptionModel{}, warnings, fmt.Errorf("Failed to search for the build.gradle files of (%s), error: %s", gradlewPth, err)
 		}
-		log.Warnft("Using: %s", rootGradlewPath)
+		if len(gradleFiles) == 0 {
+			log.Warnft("No build.gradle file found for gradle wrapper: %s, skipping", gradlewPth)
+			continue
+		}
+
+		projectGradlewFiles = append(projectGradlewFiles, gradlewPth)
+		gradlewGradleFilesMap[gradlewPth] = gradleFiles
+	}
+
+	if len(projectGradlewFiles) == 0 {
+		log.Errorft("No build.gradle file found for the gradle wrappers")
+		return models.OptionModel{}, warnings, fmt.Errorf("No build.gradle file found next to or under the gradle wrappers")
 	}
 	// ---
 
+	gradlewPthOption := models.NewOption(gradlewPathInputTitle, gradlewPathInputEnvKey)
+
+	scanner.configDescriptors = []ConfigDescriptor{}
+	scanner.scanWarnings = []models.ScanWarningModel{}
+	scanner.stack = nil
+
+	qualifiedConfigNames := len(projectGradlewFiles) > 1
+	for _, gradlewPth := range projectGradlewFiles {
+		descriptor, projectWarnings, err := scanner.inspectProject(gradlewPth, gradlewGradleFilesMap[gradlewPth])
+		if err != nil {
+			return models.OptionModel{}, warnings, err
+		}
+		warnings = append(warnings, projectWarnings...)
+		scanner.configDescriptors = append(scanner.configDescriptors, descriptor)
+
+		// Options
+		gradleFileOption := models.NewOption(gradleFileInputTitle, gradleFileInputEnvKey)
+
+		if descriptor.RelGradlewDir != "" {
+			gradlewDirOption := models.NewOption(gradlewDirInputTitle, gradlewDirInputEnvKey)
+			gradlewPthOption.AddOption(gradlewPth, gradlewDirOption)
+
+			gradlewDirOption.AddOption(descriptor.RelGradlewDir, gradleFileOption)
+		} else {
+			gradlewPthOption.AddOption(gradlewPth, gradleFileOption)
+		}
+
+		for _, gradleFile := range gradlewGradleFilesMap[gradlewPth] {
+			gradleTaskOption := models.NewOption(gradleTaskInputTitle, gradleTaskInputEnvKey)
+			gradleFileOption.AddOption(gradleFile, gradleTaskOption)
+
+			log.Printft("%d gradle tasks", len(defaultGradleTasks))
+
+			for _, gradleTask := range defaultGradleTasks {
+				log.Printft("- %s", gradleTask)
+
+				configOption := models.NewConfigOption(descriptor.ConfigName(qualifiedConfigNames))
+				gradleTaskOption.AddConfig(gradleTask, configOption)
+			}
+		}
+		// ---
+	}
+
+	for _, scanWarning := range scanner.scanWarnings {
+		log.Warnft(scanWarning.Message)
+	}
+
+	return *gradlewPthOption, warnings, nil
+}
+
+// inspectProject inspects the gradle wrapper's project: the gradle wrapper, the modules of its gradle files and the build toolchain,
+// the recommended stack of the scanner is the first project's stack, as the gradle wrappers are sorted by their depth.
+func (scanner *Scanner) inspectProject(gradlewPth string, gradleFiles []string) (ConfigDescriptor, models.Warnings, error) {
+	warnings := models.Warnings{}
+
 	// Get relative gradle wrapper dir
-	gradlewDir := filepath.Dir(rootGradlewPath)
+	gradlewDir := filepath.Dir(gradlewPth)
 	relGradlewDir, err := utility.RelPath(scanner.SearchDir, gradlewDir)
 	if err != nil {
-		return models.OptionModel{}, warnings, fmt.Errorf("Failed to get relative gradle wrapper dir path, error: %s", err)
+		return ConfigDescriptor{}, warnings, fmt.Errorf("Failed to get relative gradle wrapper dir path, error: %s", err)
 	}
 	if relGradlewDir == "." {
 		// gradlew placed in the search dir, no need to change-dir in the workflows
 		relGradlewDir = ""
 	}
-	scanner.RelGradlewDir = relGradlewDir
+
+	descriptor := ConfigDescriptor{
+		RelGradlewDir:    relGradlewDir,
+		DeployGradleTask: assembleReleaseGradleTask,
+	}
 	// ---
 
-	// Options
-	gradlewPthOption := models.NewOption(gradlewPathInputTitle, gradlewPathInputEnvKey)
+	// Inspect gradle wrapper
+	log.Infoft("Inspecting gradle wrapper: %s", gradlewPth)
 
-	gradleFileOption := models.NewOption(gradleFileInputTitle, gradleFileInputEnvKey)
+	wrapper, err := utility.InspectGradleWrapper(gradlewPth)
+	if err != nil {
+		return ConfigDescriptor{}, warnings, fmt.Errorf("Failed to inspect gradle wrapper, error: %s", err)
+	}
+	log.Printft("gradle version: %s", wrapper.GradleVersion)
+
+	scanner.scanWarnings = append(scanner.scanWarnings, gradleWrapperWarnings(gradlewPth, wrapper)...)
+	// ---
+
+	agpVersion := ""
+	for _, gradleFile := range gradleFiles {
+		log.Infoft("Inspecting gradle file: %s", gradleFile)
 
-	if relGradlewDir != "" {
-		gradlewDirOption := models.NewOption(gradlewDirInputTitle, gradlewDirInputEnvKey)
-		gradlewPthOption.AddOption(rootGradlewPath, gradlewDirOption)
+		if agpVersion == "" {
+			if agpVersion, err = utility.AndroidGradlePluginVersion(gradleFile); err != nil {
+				return ConfigDescriptor{}, warnings, fmt.Errorf("Failed to read the Android Gradle Plugin version of (%s), error: %s", gradleFile, err)
+			}
+			log.Printft("Android Gradle Plugin version: %s", agpVersion)
+		}
 
-		gradlewDirOption.AddOption(relGradlewDir, gradleFileOption)
-	} else {
-		gradlewPthOption.AddOption(rootGradlewPath, gradleFileOption)
+		moduleWarnings, err := inspectModules(scanner.FileList, gradleFile, &descriptor)
+		if err != nil {
+			return ConfigDescriptor{}, warnings, fmt.Errorf("Failed to inspect the modules of (%s), error: %s", gradleFile, err)
+		}
+		warnings = append(warnings, moduleWarnings...)
 	}
 
-	for _, gradleFile := range scanner.BuildGradleFiles {
-		log.Infoft("Inspecting gradle file: %s", gradleFile)
+	stack, stackWarnings := recommendedStack(wrapper.GradleVersion, agpVersion)
+	if stack != nil {
+		log.Printft("recommended JDK version: %s", stack.JDKVersion)
+		descriptor.JDKVersion = stack.JDKVersion
+
+		if scanner.stack == nil {
+			scanner.stack = stack
+		}
+	}
+	scanner.scanWarnings = append(scanner.scanWarnings, stackWarnings...)
+
+	return descriptor, warnings, nil
+}
+
+// ScanWarnings ...
+func (scanner *Scanner) ScanWarnings() []models.ScanWarningModel {
+	return scanner.scanWarnings
+}
+
+// RecommendedStack ...
+func (scanner *Scanner) RecommendedStack() *models.StackModel {
+	return scanner.stack
+}
+
+// inspectModules inspects the modules of the rootGradleFile's project,
+// it sets the descriptor's deploy gradle task, the detected test sources and lint tools,
+// and returns the warnings about the app modules' signing configs.
+func inspectModules(fileList []string, rootGradleFile string, descriptor *ConfigDescriptor) (models.Warnings, error) {
+	modules, err := utility.AndroidModules(fileList, rootGradleFile)
+	if err != nil {
+		return nil, err
+	}
 
-		gradleTaskOption := models.NewOption(gradleTaskInputTitle, gradleTaskInputEnvKey)
-		gradleFileOption.AddOption(gradleFile, gradleTaskOption)
+	hasInstrumentedTests := false
+	appModule := ""
 
-		log.Printft("%d gradle tasks", len(defaultGradleTasks))
+	warnings := models.Warnings{}
+	for _, module := range modules {
+		log.Printft("module: %s (app: %v, unit tests: %v, instrumented tests: %v)", module.Name, module.IsApp, module.HasUnitTests, module.HasInstrumentedTests)
 
-		for _, gradleTask := range defaultGradleTasks {
-			log.Printft("- %s", gradleTask)
+		if module.HasUnitTests {
+			descriptor.HasUnitTests = true
+		}
+		if module.HasInstrumentedTests {
+			hasInstrumentedTests = true
+		}
 
-			configOption := models.NewConfigOption(configName)
-			gradleTaskOption.AddConfig(gradleTask, configOption)
+		if !module.IsApp {
+			continue
+		}
+		if appModule == "" {
+			appModule = module.Name
+		}
+
+		content, err := fileutil.ReadStringFromFile(module.GradleFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if utility.HasAndroidBundleConfig(content) {
+			descriptor.DeployGradleTask = bundleReleaseGradleTask
+		}
+
+		signingConfigs := utility.ParseAndroidSigningConfigs(content)
+
+		log.Printft("%d signing configs in app module: %s", len(signingConfigs), module.GradleFile)
+		for _, signingConfig := range signingConfigs {
+			sources := []string{}
+			for _, source := range signingConfig.Sources {
+				sources = append(sources, string(source))
+			}
+			log.Printft("- %s (%s)", signingConfig.Name, strings.Join(sources, ", "))
+
+			warnings = append(warnings, signingConfigWarnings(module.GradleFile, signingConfig)...)
 		}
 	}
-	// ---
 
-	return *gradlewPthOption, warnings, nil
+	// the instrumented tests run against the app module's build
+	if hasInstrumentedTests && appModule != "" && descriptor.InstrumentedTestModule == "" {
+		descriptor.InstrumentedTestModule = appModule
+	}
+
+	lintTools, err := utility.AndroidLintTools(fileList, rootGradleFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range lintTools {
+		log.Printft("lint tool: %s", tool)
+		if !hasLintTool(descriptor.LintTools, tool) {
+			descriptor.LintTools = append(descriptor.LintTools, tool)
+		}
+	}
+
+	return warnings, nil
+}
+
+func hasLintTool(tools []utility.AndroidLintTool, tool utility.AndroidLintTool) bool {
+	for _, t := range tools {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// signingConfigWarnings returns the warnings about where the signing config reads the keystore credentials from.
+func signingConfigWarnings(gradleFile string, signingConfig utility.AndroidSigningConfigModel) models.Warnings {
+	warnings := models.Warnings{}
+	for _, source := range signingConfig.Sources {
+		switch source {
+		case utility.AndroidSigningConfigSourceEnv:
+			warnings = append(warnings, fmt.Sprintf("The %s signing config (%s) reads the keystore credentials from the %s envs, define them as secrets",
+				signingConfig.Name, gradleFile, strings.Join(signingConfig.EnvKeys, ", ")))
+		case utility.AndroidSigningConfigSourceKeystoreProperties:
+			warnings = append(warnings, fmt.Sprintf("The %s signing config (%s) reads the keystore credentials from a properties file, do not commit it, %s",
+				signingConfig.Name, gradleFile, keystoreSecretsDescription))
+		case utility.AndroidSigningConfigSourceLiteral:
+			warnings = append(warnings, fmt.Sprintf("The %s signing config (%s) has hardcoded keystore credentials, remove them, %s",
+				signingConfig.Name, gradleFile, keystoreSecretsDescription))
+		}
+	}
+	return warnings
 }
 
 // DefaultOptions ...
@@ -218,12 +480,41 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 
 // Configs ...
 func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+	bitriseDataMap := models.BitriseConfigMap{}
+
+	qualifiedConfigNames := len(scanner.configDescriptors) > 1
+	for _, descriptor := range scanner.configDescriptors {
+		configBuilder := generateConfigBuilder(descriptor)
+
+		config, err := configBuilder.Generate(ScannerName)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		bitriseDataMap[descriptor.ConfigName(qualifiedConfigNames)] = string(data)
+	}
+
+	return bitriseDataMap, nil
+}
+
+// generateConfigBuilder returns the config builder of the descriptor's gradle wrapper project.
+func generateConfigBuilder(descriptor ConfigDescriptor) *models.ConfigBuilderModel {
 	configBuilder := models.NewDefaultConfigBuilder()
 
-	configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
+	changeWorkDir := descriptor.RelGradlewDir != ""
+	appendPrepareStepListTo(configBuilder, models.PrimaryWorkflowID, changeWorkDir, descriptor.JDKVersion)
 
-	if scanner.RelGradlewDir != "" {
-		configBuilder.AppendPreparStepList(steps.ChangeWorkDirStepListItem(envmanModels.EnvironmentItemModel{pathInputKey: "$" + gradlewDirInputEnvKey}))
+	for _, tool := range descriptor.LintTools {
+		configBuilder.AppendMainStepList(lintStepListItem(tool))
+	}
+
+	if descriptor.HasUnitTests {
+		configBuilder.AppendMainStepList(steps.AndroidUnitTestStepListItem())
 	}
 
 	configBuilder.AppendMainStepList(steps.GradleRunnerStepListItem(
@@ -232,33 +523,105 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
 	))
 
-	config, err := configBuilder.Generate(ScannerName)
-	if err != nil {
-		return models.BitriseConfigMap{}, err
+	appendDeployWorkflow(configBuilder, changeWorkDir, descriptor.JDKVersion, descriptor.DeployGradleTask)
+
+	if descriptor.InstrumentedTestModule != "" {
+		appendInstrumentedTestWorkflow(configBuilder, changeWorkDir, descriptor.JDKVersion, descriptor.InstrumentedTestModule)
 	}
 
-	data, err := yaml.Marshal(config)
-	if err != nil {
-		return models.BitriseConfigMap{}, err
+	return configBuilder
+}
+
+// appendPrepareStepListTo adds the steps preparing the android build to the workflow:
+// the recommended JDK (if known), the missing android tools, the gradle wrapper dir as working dir and the gradle caches.
+func appendPrepareStepListTo(configBuilder *models.ConfigBuilderModel, workflow models.WorkflowID, changeWorkDir bool, jdkVersion string) {
+	if jdkVersion != "" {
+		configBuilder.AppendPreparStepListTo(workflow, steps.SetJavaVersionStepListItem(envmanModels.EnvironmentItemModel{setJavaVersionInputKey: jdkVersion}))
 	}
 
-	return models.BitriseConfigMap{
-		configName: string(data),
-	}, nil
+	configBuilder.AppendPreparStepListTo(workflow, steps.InstallMissingAndroidToolsStepListItem())
+
+	if changeWorkDir {
+		configBuilder.AppendPreparStepListTo(workflow, steps.ChangeWorkDirStepListItem(envmanModels.EnvironmentItemModel{pathInputKey: "$" + gradlewDirInputEnvKey}))
+	}
+
+	// change-workdir points $BITRISE_SOURCE_DIR to the gradle wrapper dir
+	configBuilder.AddCachePathsTo(workflow, utility.GradleCachePaths(sourceDirEnv)...)
+}
+
+// appendDeployWorkflow adds the deploy workflow, which builds the release gradle task,
+// signs the APK (or App Bundle) with the keystore secrets and deploys the signed APK (or App Bundle).
+func appendDeployWorkflow(configBuilder *models.ConfigBuilderModel, changeWorkDir bool, jdkVersion, gradleTask string) {
+	appPath, signedAppPath := apkPathEnv, signedAPKPathEnv
+	if gradleTask == bundleReleaseGradleTask {
+		appPath, signedAppPath = aabPathEnv, signedAABPathEnv
+	}
+
+	configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+
+	appendPrepareStepListTo(configBuilder, models.DeployWorkflowID, changeWorkDir, jdkVersion)
+
+	configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.GradleRunnerStepListItem(
+		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
+		envmanModels.EnvironmentItemModel{gradleTaskInputKey: gradleTask},
+		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
+	))
+	configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.SignAPKStepListItem(
+		envmanModels.EnvironmentItemModel{androidAppInputKey: appPath},
+	))
+	configBuilder.SetDeployInputsTo(models.DeployWorkflowID, envmanModels.EnvironmentItemModel{deployPathInputKey: signedAppPath})
+}
+
+// lintStepListItem returns the step running the lint tool,
+// detekt and ktlint run through their gradle plugin's task.
+func lintStepListItem(tool utility.AndroidLintTool) bitriseModels.StepListItemModel {
+	switch tool {
+	case utility.AndroidLintToolDetekt:
+		return gradleTaskStepListItem(detektGradleTask)
+	case utility.AndroidLintToolKtlint:
+		return gradleTaskStepListItem(ktlintGradleTask)
+	default:
+		return steps.AndroidLintStepListItem()
+	}
+}
+
+func gradleTaskStepListItem(gradleTask string) bitriseModels.StepListItemModel {
+	return steps.GradleRunnerStepListItem(
+		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
+		envmanModels.EnvironmentItemModel{gradleTaskInputKey: gradleTask},
+		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
+	)
+}
+
+// appendInstrumentedTestWorkflow adds the instrumented-test workflow,
+// which builds the module's app and test APKs and runs the instrumented tests on virtual devices.
+func appendInstrumentedTestWorkflow(configBuilder *models.ConfigBuilderModel, changeWorkDir bool, jdkVersion, module string) {
+	configBuilder.AddDefaultWorkflowBuilder(instrumentedTestWorkflowID)
+
+	appendPrepareStepListTo(configBuilder, instrumentedTestWorkflowID, changeWorkDir, jdkVersion)
+
+	configBuilder.AppendMainStepListTo(instrumentedTestWorkflowID, steps.AndroidBuildForUITestingStepListItem(
+		envmanModels.EnvironmentItemModel{moduleInputKey: module},
+		envmanModels.EnvironmentItemModel{variantInputKey: instrumentedTestVariant},
+	))
+	configBuilder.AppendMainStepListTo(instrumentedTestWorkflowID, steps.VirtualDeviceTestingForAndroidStepListItem(
+		envmanModels.EnvironmentItemModel{testTypeInputKey: instrumentationTestType},
+	))
 }
 
 // DefaultConfigs ...
 func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
-	configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
-	configBuilder.AppendPreparStepList(steps.ChangeWorkDirStepListItem(envmanModels.EnvironmentItemModel{pathInputKey: "$" + gradlewDirInputEnvKey}))
+	appendPrepareStepListTo(configBuilder, models.PrimaryWorkflowID, true, "")
 	configBuilder.AppendMainStepList(steps.GradleRunnerStepListItem(
 		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
 		envmanModels.EnvironmentItemModel{gradleTaskInputKey: "$" + gradleTaskInputEnvKey},
 		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
 	))
 
+	appendDeployWorkflow(configBuilder, true, "", assembleReleaseGradleTask)
+
 	config, err := configBuilder.Generate(ScannerName)
 	if err != nil {
 		return models.BitriseConfigMap{}, err
@@ -0,0 +1,65 @@
+package android
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/steps"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/stretchr/testify/require"
+)
+
+// stepInputs returns the inputs of the step with the id (the last one, if the workflow has more), as a key-value map.
+func stepInputs(t *testing.T, workflow bitriseModels.WorkflowModel, id string) map[string]string {
+	inputs := map[string]string{}
+	found := false
+	for _, item := range workflow.Steps {
+		for stepIDComposite, step := range item {
+			if stepIDComposite != id+"@"+steps.Versions[id] {
+				continue
+			}
+			found = true
+			inputs = map[string]string{}
+			for _, input := range step.Inputs {
+				key, value, err := input.GetKeyValuePair()
+				require.NoError(t, err)
+				inputs[key] = value
+			}
+		}
+	}
+	require.True(t, found, "step not found: %s", id)
+	return inputs
+}
+
+func TestAppendDeployWorkflow(t *testing.T) {
+	t.Log("release APK")
+	{
+		configBuilder := models.NewDefaultConfigBuilder()
+		appendDeployWorkflow(configBuilder, false, "17", assembleReleaseGradleTask)
+
+		config, err := configBuilder.Generate(ScannerName)
+		require.NoError(t, err)
+
+		workflow := config.Workflows[string(models.DeployWorkflowID)]
+		require.Equal(t, assembleReleaseGradleTask, stepInputs(t, workflow, steps.GradleRunnerID)[gradleTaskInputKey])
+		require.Equal(t, map[string]string{androidAppInputKey: "$BITRISE_APK_PATH"}, stepInputs(t, workflow, steps.SignAPKID))
+		require.Equal(t, map[string]string{deployPathInputKey: "$BITRISE_SIGNED_APK_PATH"}, stepInputs(t, workflow, steps.DeployToBitriseIoID))
+	}
+
+	t.Log("release App Bundle")
+	{
+		configBuilder := models.NewDefaultConfigBuilder()
+		appendDeployWorkflow(configBuilder, false, "17", bundleReleaseGradleTask)
+
+		config, err := configBuilder.Generate(ScannerName)
+		require.NoError(t, err)
+
+		workflow := config.Workflows[string(models.DeployWorkflowID)]
+		require.Equal(t, bundleReleaseGradleTask, stepInputs(t, workflow, steps.GradleRunnerID)[gradleTaskInputKey])
+		require.Equal(t, map[string]string{androidAppInputKey: "$BITRISE_AAB_PATH"}, stepInputs(t, workflow, steps.SignAPKID))
+		require.Equal(t, map[string]string{deployPathInputKey: "$BITRISE_SIGNED_AAB_PATH"}, stepInputs(t, workflow, steps.DeployToBitriseIoID))
+
+		// the primary workflow deploys the build dir
+		require.Equal(t, map[string]string{}, stepInputs(t, config.Workflows[string(models.PrimaryWorkflowID)], steps.DeployToBitriseIoID))
+	}
+}
@@ -0,0 +1,219 @@
+package android
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+)
+
+// Scan warning codes of the gradle wrapper and the build toolchain inspection.
+const (
+	GradleWrapperPropertiesMissingWarningCode    models.ScanWarningCode = "gradle_wrapper_properties_missing"
+	GradleDistributionURLMissingWarningCode      models.ScanWarningCode = "gradle_distribution_url_missing"
+	GradleWrapperJarMissingWarningCode           models.ScanWarningCode = "gradle_wrapper_jar_missing"
+	GradlewNotExecutableWarningCode              models.ScanWarningCode = "gradlew_not_executable"
+	GradleVersionIncompatibleWarningCode         models.ScanWarningCode = "gradle_version_incompatible"
+	JDKVersionIncompatibleWarningCode            models.ScanWarningCode = "jdk_version_incompatible"
+	AndroidGradlePluginVersionUnknownWarningCode models.ScanWarningCode = "android_gradle_plugin_version_unknown"
+)
+
+// androidGradlePluginCompatibility is a row of the Android Gradle Plugin compatibility matrix:
+// the plugin versions from the AGPVersion require at least the MinGradleVersion and the JDKVersion.
+type androidGradlePluginCompatibility struct {
+	AGPVersion       string
+	MinGradleVersion string
+	JDKVersion       string
+}
+
+// androidGradlePluginCompatibilities is sorted by the plugin version, in descending order.
+var androidGradlePluginCompatibilities = []androidGradlePluginCompatibility{
+	{AGPVersion: "9.0", MinGradleVersion: "9.1.0", JDKVersion: "17"},
+	{AGPVersion: "8.11", MinGradleVersion: "8.13", JDKVersion: "17"},
+	{AGPVersion: "8.9", MinGradleVersion: "8.11.1", JDKVersion: "17"},
+	{AGPVersion: "8.8", MinGradleVersion: "8.10.2", JDKVersion: "17"},
+	{AGPVersion: "8.7", MinGradleVersion: "8.9", JDKVersion: "17"},
+	{AGPVersion: "8.6", MinGradleVersion: "8.7", JDKVersion: "17"},
+	{AGPVersion: "8.5", MinGradleVersion: "8.7", JDKVersion: "17"},
+	{AGPVersion: "8.4", MinGradleVersion: "8.6", JDKVersion: "17"},
+	{AGPVersion: "8.3", MinGradleVersion: "8.4", JDKVersion: "17"},
+	{AGPVersion: "8.2", MinGradleVersion: "8.2", JDKVersion: "17"},
+	{AGPVersion: "8.0", MinGradleVersion: "8.0", JDKVersion: "17"},
+	{AGPVersion: "7.4", MinGradleVersion: "7.5", JDKVersion: "11"},
+	{AGPVersion: "7.3", MinGradleVersion: "7.4", JDKVersion: "11"},
+	{AGPVersion: "7.2", MinGradleVersion: "7.3.3", JDKVersion: "11"},
+	{AGPVersion: "7.1", MinGradleVersion: "7.2", JDKVersion: "11"},
+	{AGPVersion: "7.0", MinGradleVersion: "7.0", JDKVersion: "11"},
+	{AGPVersion: "4.2", MinGradleVersion: "6.7.1", JDKVersion: "8"},
+	{AGPVersion: "4.1", MinGradleVersion: "6.5", JDKVersion: "8"},
+	{AGPVersion: "4.0", MinGradleVersion: "6.1.1", JDKVersion: "8"},
+	{AGPVersion: "3.6", MinGradleVersion: "5.6.4", JDKVersion: "8"},
+	{AGPVersion: "3.5", MinGradleVersion: "5.4.1", JDKVersion: "8"},
+	{AGPVersion: "3.4", MinGradleVersion: "5.1.1", JDKVersion: "8"},
+	{AGPVersion: "3.3", MinGradleVersion: "4.10.1", JDKVersion: "8"},
+	{AGPVersion: "3.2", MinGradleVersion: "4.6", JDKVersion: "8"},
+	{AGPVersion: "3.1", MinGradleVersion: "4.4", JDKVersion: "8"},
+	{AGPVersion: "3.0", MinGradleVersion: "4.1", JDKVersion: "8"},
+}
+
+// gradleJDKCompatibility is a row of the Gradle JDK compatibility matrix:
+// the Gradle versions from the GradleVersion run on the JDKs up to the MaxJDKVersion.
+type gradleJDKCompatibility struct {
+	GradleVersion string
+	MaxJDKVersion string
+}
+
+// gradleJDKCompatibilities is sorted by the Gradle version, in descending order.
+var gradleJDKCompatibilities = []gradleJDKCompatibility{
+	{GradleVersion: "8.5", MaxJDKVersion: "21"},
+	{GradleVersion: "7.3", MaxJDKVersion: "17"},
+	{GradleVersion: "7.0", MaxJDKVersion: "16"},
+	{GradleVersion: "6.7", MaxJDKVersion: "15"},
+	{GradleVersion: "6.3", MaxJDKVersion: "14"},
+	{GradleVersion: "6.0", MaxJDKVersion: "13"},
+	{GradleVersion: "5.4", MaxJDKVersion: "12"},
+	{GradleVersion: "5.0", MaxJDKVersion: "11"},
+	{GradleVersion: "4.7", MaxJDKVersion: "10"},
+	{GradleVersion: "0", MaxJDKVersion: "8"},
+}
+
+// releaseVersion strips the pre-release suffix of the version: 8.2.0-rc01 -> 8.2.0.
+func releaseVersion(version string) string {
+	return strings.SplitN(version, "-", 2)[0]
+}
+
+// majorMinorVersion returns the major and minor components of the version: 8.2.0-rc01 -> 8.2.
+func majorMinorVersion(version string) string {
+	components := strings.Split(releaseVersion(version), ".")
+	if len(components) > 2 {
+		components = components[:2]
+	}
+	return strings.Join(components, ".")
+}
+
+// isNewerThanKnownAndroidGradlePlugin returns true if the Android Gradle Plugin version is newer than the newest row of the compatibility matrix,
+// the requirements of those versions are unknown.
+func isNewerThanKnownAndroidGradlePlugin(agpVersion string) bool {
+	return steps.CompareVersions(majorMinorVersion(agpVersion), androidGradlePluginCompatibilities[0].AGPVersion) > 0
+}
+
+func androidGradlePluginCompatibilityOf(agpVersion string) (androidGradlePluginCompatibility, bool) {
+	for _, compatibility := range androidGradlePluginCompatibilities {
+		if steps.CompareVersions(releaseVersion(agpVersion), compatibility.AGPVersion) >= 0 {
+			return compatibility, true
+		}
+	}
+	return androidGradlePluginCompatibility{}, false
+}
+
+func gradleMaxJDKVersion(gradleVersion string) string {
+	for _, compatibility := range gradleJDKCompatibilities {
+		if steps.CompareVersions(releaseVersion(gradleVersion), compatibility.GradleVersion) >= 0 {
+			return compatibility.MaxJDKVersion
+		}
+	}
+	return ""
+}
+
+// defaultJDKVersion returns the LTS JDK version to run the Gradle version with, if the Android Gradle Plugin version is unknown.
+func defaultJDKVersion(gradleVersion string) string {
+	switch {
+	case steps.CompareVersions(releaseVersion(gradleVersion), "7.3") >= 0:
+		return "17"
+	case steps.CompareVersions(releaseVersion(gradleVersion), "5.0") >= 0:
+		return "11"
+	default:
+		return "8"
+	}
+}
+
+// gradleWrapperWarnings returns the warnings about the problems of the gradle wrapper, which would fail the first build.
+func gradleWrapperWarnings(gradlewPth string, wrapper utility.GradleWrapperModel) []models.ScanWarningModel {
+	warnings := []models.ScanWarningModel{}
+
+	if !wrapper.IsGradlewExecutable {
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    GradlewNotExecutableWarningCode,
+			Message: fmt.Sprintf("The gradle wrapper (%s) is not executable, run: git update-index --chmod=+x %s", gradlewPth, gradlewPth),
+		})
+	}
+
+	if !wrapper.HasJar {
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    GradleWrapperJarMissingWarningCode,
+			Message: fmt.Sprintf("The gradle wrapper jar (%s) is not committed, the gradle wrapper can not run without it", filepath.Join(filepath.Dir(gradlewPth), "gradle", "wrapper", "gradle-wrapper.jar")),
+		})
+	}
+
+	propertiesPth := filepath.Join(filepath.Dir(gradlewPth), "gradle", "wrapper", "gradle-wrapper.properties")
+	if !wrapper.HasProperties {
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    GradleWrapperPropertiesMissingWarningCode,
+			Message: fmt.Sprintf("The gradle wrapper properties (%s) are not committed, the gradle wrapper can not download Gradle without them", propertiesPth),
+		})
+	} else if wrapper.DistributionURL == "" {
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    GradleDistributionURLMissingWarningCode,
+			Message: fmt.Sprintf("The gradle wrapper properties (%s) do not define the distributionUrl, the gradle wrapper can not download Gradle", propertiesPth),
+		})
+	}
+
+	return warnings
+}
+
+// recommendedStack returns the build stack recommended for the Gradle and Android Gradle Plugin versions,
+// and the warnings about the incompatible versions, the stack is nil if both versions are unknown.
+// No Gradle version is recommended for an Android Gradle Plugin newer than the compatibility matrix.
+func recommendedStack(gradleVersion, agpVersion string) (*models.StackModel, []models.ScanWarningModel) {
+	if gradleVersion == "" && agpVersion == "" {
+		return nil, nil
+	}
+
+	stack := &models.StackModel{
+		GradleVersion:              gradleVersion,
+		AndroidGradlePluginVersion: agpVersion,
+	}
+	warnings := []models.ScanWarningModel{}
+
+	if agpVersion != "" && isNewerThanKnownAndroidGradlePlugin(agpVersion) {
+		newest := androidGradlePluginCompatibilities[0]
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    AndroidGradlePluginVersionUnknownWarningCode,
+			Message: fmt.Sprintf("The Android Gradle Plugin %s is newer than the known %s, check its required Gradle and JDK versions", agpVersion, newest.AGPVersion),
+		})
+		stack.GradleVersion = ""
+		stack.JDKVersion = newest.JDKVersion
+		return stack, warnings
+	}
+
+	compatibility, ok := androidGradlePluginCompatibilityOf(agpVersion)
+	if agpVersion == "" || !ok {
+		stack.JDKVersion = defaultJDKVersion(gradleVersion)
+		return stack, warnings
+	}
+
+	stack.JDKVersion = compatibility.JDKVersion
+	if gradleVersion == "" {
+		return stack, warnings
+	}
+
+	if steps.CompareVersions(releaseVersion(gradleVersion), compatibility.MinGradleVersion) < 0 {
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    GradleVersionIncompatibleWarningCode,
+			Message: fmt.Sprintf("Gradle %s is older than %s, required by the Android Gradle Plugin %s, update the gradle wrapper's distributionUrl", gradleVersion, compatibility.MinGradleVersion, agpVersion),
+		})
+		stack.GradleVersion = compatibility.MinGradleVersion
+	}
+
+	if maxJDKVersion := gradleMaxJDKVersion(gradleVersion); steps.CompareVersions(stack.JDKVersion, maxJDKVersion) > 0 {
+		warnings = append(warnings, models.ScanWarningModel{
+			Code:    JDKVersionIncompatibleWarningCode,
+			Message: fmt.Sprintf("Gradle %s does not run on JDK %s, required by the Android Gradle Plugin %s", gradleVersion, stack.JDKVersion, agpVersion),
+		})
+	}
+
+	return stack, warnings
+}
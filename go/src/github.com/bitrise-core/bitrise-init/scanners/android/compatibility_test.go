@@ -0,0 +1,55 @@
+package android
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendedStack(t *testing.T) {
+	t.Log("compatible versions")
+	{
+		stack, warnings := recommendedStack("8.10.2", "8.8.0")
+		require.Equal(t, &models.StackModel{JDKVersion: "17", GradleVersion: "8.10.2", AndroidGradlePluginVersion: "8.8.0"}, stack)
+		require.Equal(t, 0, len(warnings))
+	}
+
+	t.Log("gradle older than required by the plugin")
+	{
+		stack, warnings := recommendedStack("8.9", "8.8.0")
+		require.Equal(t, &models.StackModel{JDKVersion: "17", GradleVersion: "8.10.2", AndroidGradlePluginVersion: "8.8.0"}, stack)
+		require.Equal(t, 1, len(warnings))
+		require.Equal(t, GradleVersionIncompatibleWarningCode, warnings[0].Code)
+	}
+
+	t.Log("minor versions compare numerically")
+	{
+		stack, warnings := recommendedStack("8.11.1", "8.13.0")
+		require.Equal(t, "8.13", stack.GradleVersion)
+		require.Equal(t, 1, len(warnings))
+		require.Equal(t, GradleVersionIncompatibleWarningCode, warnings[0].Code)
+	}
+
+	t.Log("patch release of the newest known plugin")
+	{
+		stack, warnings := recommendedStack("9.1.0", "9.0.1")
+		require.Equal(t, &models.StackModel{JDKVersion: "17", GradleVersion: "9.1.0", AndroidGradlePluginVersion: "9.0.1"}, stack)
+		require.Equal(t, 0, len(warnings))
+	}
+
+	t.Log("plugin newer than the newest known")
+	{
+		stack, warnings := recommendedStack("9.1.0", "9.1.0-alpha01")
+		require.Equal(t, &models.StackModel{JDKVersion: "17", AndroidGradlePluginVersion: "9.1.0-alpha01"}, stack)
+		require.Equal(t, 1, len(warnings))
+		require.Equal(t, AndroidGradlePluginVersionUnknownWarningCode, warnings[0].Code)
+	}
+
+	t.Log("unknown plugin version")
+	{
+		stack, warnings := recommendedStack("7.6", "")
+		require.Equal(t, &models.StackModel{JDKVersion: "17", GradleVersion: "7.6"}, stack)
+		require.Equal(t, 0, len(warnings))
+	}
+}
@@ -1,6 +1,7 @@
 package cordova
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/scanners/javascript"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
 	envmanModels "github.com/bitrise-io/envman/models"
@@ -53,6 +55,7 @@ type Scanner struct {
 	searchDir           string
 	hasKarmaJasmineTest bool
 	hasJasmineTest      bool
+	jsDependencies      utility.JSDependenciesModel
 }
 
 // NewScanner ...
@@ -66,7 +69,7 @@ func (scanner Scanner) Name() string {
 }
 
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
 	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
@@ -136,7 +139,7 @@ func (scanner *Scanner) ExcludedScannerNames() []string {
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
 	warnings := models.Warnings{}
 	projectRootDir := filepath.Dir(scanner.cordovaConfigPth)
 
@@ -146,6 +149,12 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 		return models.OptionModel{}, warnings, err
 	}
 
+	jsDependencies, err := javascript.DetectDependencies(projectRootDir, scanner.searchDir)
+	if err != nil {
+		return models.OptionModel{}, warnings, err
+	}
+	scanner.jsDependencies = jsDependencies
+
 	// Search for karma/jasmine tests
 	log.Printft("Searching for karma/jasmine test")
 
@@ -287,6 +296,9 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		workdirEnvList = append(workdirEnvList, envmanModels.EnvironmentItemModel{workDirInputKey: "$" + workDirInputEnvKey})
 	}
 
+	configBuilder.AppendDependencyStepList(javascript.InstallDependenciesStepListItem(scanner.jsDependencies))
+	configBuilder.AddCachePaths(javascript.CachePaths(scanner.jsDependencies)...)
+
 	if scanner.hasJasmineTest || scanner.hasKarmaJasmineTest {
 		// CI
 		if scanner.hasKarmaJasmineTest {
@@ -298,6 +310,9 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		// CD
 		configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
 
+		configBuilder.AppendDependencyStepListTo(models.DeployWorkflowID, javascript.InstallDependenciesStepListItem(scanner.jsDependencies))
+		configBuilder.AddCachePathsTo(models.DeployWorkflowID, javascript.CachePaths(scanner.jsDependencies)...)
+
 		if scanner.hasKarmaJasmineTest {
 			configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.KarmaJasmineTestRunnerStepListItem(workdirEnvList...))
 		} else if scanner.hasJasmineTest {
@@ -360,6 +375,8 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
+	configBuilder.AppendDependencyStepList(javascript.DefaultInstallDependenciesStepListItem("$" + workDirInputEnvKey))
+
 	configBuilder.AppendMainStepList(steps.GenerateCordovaBuildConfigStepListItem())
 	cordovaArchiveEnvs := []envmanModels.EnvironmentItemModel{
 		envmanModels.EnvironmentItemModel{workDirInputKey: "$" + workDirInputEnvKey},
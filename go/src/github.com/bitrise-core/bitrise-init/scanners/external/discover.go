@@ -0,0 +1,143 @@
+package external
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ExecutablePrefix is the file name prefix of the external scanner executables,
+// searched in the external scanner dirs, the rest of the file name is used as the scanner name.
+const ExecutablePrefix = "bitrise-init-scanner-"
+
+// ConfigModel is the model of the external scanners config file.
+type ConfigModel struct {
+	Scanners []ScannerConfigModel `json:"scanners" yaml:"scanners"`
+}
+
+// ScannerConfigModel ...
+type ScannerConfigModel struct {
+	Name string   `json:"name" yaml:"name"`
+	Path string   `json:"path" yaml:"path"`
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// Discover collects the external scanners placed in the dirs of the pathList (separated by os.PathListSeparator)
+// and listed in the config file at configPth.
+// reservedNames are the names of the built-in scanners, external scanners can not use them.
+func Discover(pathList, configPth string, reservedNames []string) ([]*Scanner, error) {
+	scanners := []*Scanner{}
+
+	if pathList != "" {
+		dirScanners, err := FromDirs(filepath.SplitList(pathList))
+		if err != nil {
+			return []*Scanner{}, err
+		}
+		scanners = append(scanners, dirScanners...)
+	}
+
+	if configPth != "" {
+		configScanners, err := FromConfig(configPth)
+		if err != nil {
+			return []*Scanner{}, err
+		}
+		scanners = append(scanners, configScanners...)
+	}
+
+	names := append([]string{}, reservedNames...)
+	for _, scanner := range scanners {
+		if scanner.ScannerName == "" {
+			return []*Scanner{}, fmt.Errorf("external scanner (%s) has empty name", scanner.Path)
+		}
+		if sliceutil.IsStringInSlice(scanner.ScannerName, names) {
+			return []*Scanner{}, fmt.Errorf("external scanner (%s) name: %s is already used", scanner.Path, scanner.ScannerName)
+		}
+		names = append(names, scanner.ScannerName)
+	}
+
+	return scanners, nil
+}
+
+// FromDirs returns the external scanners of the executables named ExecutablePrefix + NAME in the given dirs.
+func FromDirs(dirs []string) ([]*Scanner, error) {
+	scanners := []*Scanner{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		absDir, err := pathutil.AbsPath(dir)
+		if err != nil {
+			return []*Scanner{}, fmt.Errorf("failed to expand path (%s), error: %s", dir, err)
+		}
+
+		infos, err := ioutil.ReadDir(absDir)
+		if err != nil {
+			return []*Scanner{}, fmt.Errorf("failed to list external scanners in (%s), error: %s", absDir, err)
+		}
+
+		for _, info := range infos {
+			if !strings.HasPrefix(info.Name(), ExecutablePrefix) || !isExecutable(info) {
+				continue
+			}
+
+			name := strings.TrimSuffix(strings.TrimPrefix(info.Name(), ExecutablePrefix), filepath.Ext(info.Name()))
+			scanners = append(scanners, NewScanner(name, filepath.Join(absDir, info.Name())))
+		}
+	}
+
+	sort.SliceStable(scanners, func(i, j int) bool {
+		return scanners[i].ScannerName < scanners[j].ScannerName
+	})
+
+	return scanners, nil
+}
+
+// FromConfig returns the external scanners listed in the config file (yml or json) at configPth,
+// relative scanner paths are relative to the config file's dir.
+func FromConfig(configPth string) ([]*Scanner, error) {
+	content, err := fileutil.ReadBytesFromFile(configPth)
+	if err != nil {
+		return []*Scanner{}, fmt.Errorf("failed to read external scanners config (%s), error: %s", configPth, err)
+	}
+
+	var config ConfigModel
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return []*Scanner{}, fmt.Errorf("failed to parse external scanners config (%s), error: %s", configPth, err)
+	}
+
+	absConfigPth, err := pathutil.AbsPath(configPth)
+	if err != nil {
+		return []*Scanner{}, fmt.Errorf("failed to expand path (%s), error: %s", configPth, err)
+	}
+	configDir := filepath.Dir(absConfigPth)
+
+	scanners := []*Scanner{}
+	for _, scannerConfig := range config.Scanners {
+		if scannerConfig.Path == "" {
+			return []*Scanner{}, fmt.Errorf("external scanner (%s) has no path defined in (%s)", scannerConfig.Name, configPth)
+		}
+
+		pth := scannerConfig.Path
+		if !filepath.IsAbs(pth) && strings.ContainsRune(pth, filepath.Separator) {
+			// executables without dir are looked up in the PATH
+			pth = filepath.Join(configDir, pth)
+		}
+
+		scanners = append(scanners, NewScanner(scannerConfig.Name, pth, scannerConfig.Args...))
+	}
+
+	return scanners, nil
+}
+
+func isExecutable(info os.FileInfo) bool {
+	return info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
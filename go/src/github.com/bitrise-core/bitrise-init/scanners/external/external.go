@@ -0,0 +1,198 @@
+// Package external runs scanners implemented as standalone executables.
+//
+// The executable is called with the command to run as its last argument,
+// the request is written to its stdin and the response is read from its stdout, both as JSON.
+// The executable's stderr is forwarded to the log.
+//
+//	<executable> detect
+//	  request:  {"search_dir": "/abs/path/of/the/scanned/dir", "file_index": ["scanned/dir/relative/path", ...]}
+//	  response: {"detected": true, "options": {...}, "configs": {...}, "warnings": [...], "excluded_scanners": [...], "error": "..."}
+//
+//	<executable> defaults
+//	  request:  {}
+//	  response: {"options": {...}, "configs": {...}}
+//
+// options maps onto models.OptionModel, configs onto models.BitriseConfigMap,
+// a non empty error means the platform was detected, but the project is not supported.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/utility"
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/log"
+)
+
+// Commands
+const (
+	DetectCommand   = "detect"
+	DefaultsCommand = "defaults"
+)
+
+// DetectRequestModel ...
+type DetectRequestModel struct {
+	SearchDir string   `json:"search_dir"`
+	FileIndex []string `json:"file_index"`
+}
+
+// DetectResponseModel ...
+type DetectResponseModel struct {
+	Detected         bool                    `json:"detected"`
+	Options          models.OptionModel      `json:"options"`
+	Configs          models.BitriseConfigMap `json:"configs"`
+	Warnings         models.Warnings         `json:"warnings"`
+	ExcludedScanners []string                `json:"excluded_scanners"`
+	Error            string                  `json:"error"`
+}
+
+// DefaultsResponseModel ...
+type DefaultsResponseModel struct {
+	Options models.OptionModel      `json:"options"`
+	Configs models.BitriseConfigMap `json:"configs"`
+}
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	ScannerName string
+	Path        string
+	Args        []string
+
+	detectResponse   DetectResponseModel
+	defaultsResponse *DefaultsResponseModel
+	defaultsErr      error
+}
+
+// NewScanner ...
+func NewScanner(name, pth string, args ...string) *Scanner {
+	return &Scanner{
+		ScannerName: name,
+		Path:        pth,
+		Args:        args,
+	}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return scanner.ScannerName
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
+	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
+	}
+
+	log.Infoft("Running external scanner: %s", scanner.Path)
+
+	request := DetectRequestModel{
+		SearchDir: searchDir,
+		FileIndex: fileList,
+	}
+	response := DetectResponseModel{}
+	if err := scanner.run(ctx, DetectCommand, searchDir, request, &response); err != nil {
+		return false, err
+	}
+	scanner.detectResponse = response
+
+	if !response.Detected {
+		log.Printft("platform not detected")
+		return false, nil
+	}
+
+	log.Doneft("Platform detected")
+
+	return true, nil
+}
+
+// ExcludedScannerNames ...
+func (scanner Scanner) ExcludedScannerNames() []string {
+	return scanner.detectResponse.ExcludedScanners
+}
+
+// Options ...
+func (scanner Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
+	response := scanner.detectResponse
+	if response.Error != "" {
+		return models.OptionModel{}, response.Warnings, fmt.Errorf("%s", response.Error)
+	}
+	if len(response.Configs) == 0 {
+		return models.OptionModel{}, response.Warnings, fmt.Errorf("external scanner (%s) detected the platform, but returned no configs", scanner.ScannerName)
+	}
+	return response.Options, response.Warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	response, err := scanner.defaults()
+	if err != nil {
+		log.Warnft("%s", err)
+		return models.OptionModel{}
+	}
+	return response.Options
+}
+
+// Configs ...
+func (scanner Scanner) Configs() (models.BitriseConfigMap, error) {
+	return scanner.detectResponse.Configs, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	response, err := scanner.defaults()
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+	return response.Configs, nil
+}
+
+func (scanner *Scanner) defaults() (DefaultsResponseModel, error) {
+	if scanner.defaultsResponse == nil && scanner.defaultsErr == nil {
+		response := DefaultsResponseModel{}
+		if err := scanner.run(context.Background(), DefaultsCommand, "", struct{}{}, &response); err != nil {
+			scanner.defaultsErr = err
+		} else {
+			scanner.defaultsResponse = &response
+		}
+	}
+
+	if scanner.defaultsErr != nil {
+		return DefaultsResponseModel{}, scanner.defaultsErr
+	}
+	return *scanner.defaultsResponse, nil
+}
+
+func (scanner Scanner) run(ctx context.Context, cmd, dir string, request, response interface{}) error {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request for external scanner (%s), error: %s", cmd, scanner.ScannerName, err)
+	}
+
+	var stdout bytes.Buffer
+	args := append(append([]string{}, scanner.Args...), cmd)
+	c := command.New(scanner.Path, args...).SetStdin(bytes.NewReader(requestBytes)).SetStdout(&stdout).SetStderr(os.Stderr)
+	if dir != "" {
+		c.SetDir(dir)
+	}
+
+	if err := utility.RunCmdContext(ctx, c.GetCmd()); err != nil {
+		return fmt.Errorf("external scanner (%s) failed, command: %s, error: %s", scanner.ScannerName, c.PrintableCommandArgs(), err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf("failed to parse %s response of external scanner (%s): %s, error: %s", cmd, scanner.ScannerName, strings.TrimSpace(stdout.String()), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package external
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+const unityConfig = `format_version: "2"
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+project_type: unity
+workflows:
+  primary:
+    steps:
+    - script@1.1.3: {}
+`
+
+const unityScannerContent = `#!/usr/bin/env bash
+set -e
+
+request=$(cat)
+
+case "$1" in
+  detect)
+    if [[ "$request" != *"ProjectSettings/ProjectVersion.txt"* ]] ; then
+      echo '{"detected": false}'
+      exit 0
+    fi
+    echo "unity project found" >&2
+    cat <<'EOS'
+{
+  "detected": true,
+  "options": {
+    "title": "Unity project path",
+    "env_key": "UNITY_PROJECT_PATH",
+    "value_map": {
+      ".": {
+        "config": "unity-config"
+      }
+    }
+  },
+  "configs": {
+    "unity-config": "format_version: \"2\"\ndefault_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git\nproject_type: unity\nworkflows:\n  primary:\n    steps:\n    - script@1.1.3: {}\n"
+  },
+  "warnings": ["unity version not pinned"],
+  "excluded_scanners": ["android", "ios"]
+}
+EOS
+    ;;
+  defaults)
+    echo '{"options": {"title": "Unity project path", "env_key": "UNITY_PROJECT_PATH", "value_map": {"_": {"config": "default-unity-config"}}}, "configs": {"default-unity-config": "format_version: \"2\"\n"}}'
+    ;;
+  *)
+    exit 1
+    ;;
+esac
+`
+
+func writeScanner(t *testing.T, dir, name, content string) string {
+	pth := filepath.Join(dir, name)
+	require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	require.NoError(t, os.Chmod(pth, 0755))
+	return pth
+}
+
+func TestScanner(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("external")
+	require.NoError(t, err)
+
+	scannerPth := writeScanner(t, tmpDir, "bitrise-init-scanner-unity", unityScannerContent)
+
+	t.Log("detects platform")
+	{
+		searchDir := filepath.Join(tmpDir, "project")
+		require.NoError(t, os.MkdirAll(filepath.Join(searchDir, "ProjectSettings"), 0755))
+		require.NoError(t, fileutil.WriteStringToFile(filepath.Join(searchDir, "ProjectSettings", "ProjectVersion.txt"), "m_EditorVersion: 2017.4.1f1\n"))
+
+		scanner := NewScanner("unity", scannerPth)
+		require.Equal(t, "unity", scanner.Name())
+
+		detected, err := scanner.DetectPlatform(context.Background(), searchDir)
+		require.NoError(t, err)
+		require.Equal(t, true, detected)
+		require.Equal(t, []string{"android", "ios"}, scanner.ExcludedScannerNames())
+
+		options, warnings, err := scanner.Options(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, models.Warnings{"unity version not pinned"}, warnings)
+		require.Equal(t, "UNITY_PROJECT_PATH", options.EnvKey)
+		require.Equal(t, "unity-config", options.ChildOptionMap["."].Config)
+
+		configs, err := scanner.Configs()
+		require.NoError(t, err)
+		require.Equal(t, models.BitriseConfigMap{"unity-config": unityConfig}, configs)
+	}
+
+	t.Log("platform not detected")
+	{
+		searchDir := filepath.Join(tmpDir, "empty")
+		require.NoError(t, os.MkdirAll(searchDir, 0755))
+
+		scanner := NewScanner("unity", scannerPth)
+		detected, err := scanner.DetectPlatform(context.Background(), searchDir)
+		require.NoError(t, err)
+		require.Equal(t, false, detected)
+	}
+
+	t.Log("defaults")
+	{
+		scanner := NewScanner("unity", scannerPth)
+		require.Equal(t, "default-unity-config", scanner.DefaultOptions().ChildOptionMap["_"].Config)
+
+		configs, err := scanner.DefaultConfigs()
+		require.NoError(t, err)
+		require.Equal(t, models.BitriseConfigMap{"default-unity-config": "format_version: \"2\"\n"}, configs)
+	}
+
+	t.Log("invalid response")
+	{
+		pth := writeScanner(t, tmpDir, "invalid", "#!/usr/bin/env bash\necho 'not a json'\n")
+
+		scanner := NewScanner("invalid", pth)
+		_, err := scanner.DetectPlatform(context.Background(), tmpDir)
+		require.Error(t, err)
+	}
+
+	t.Log("failing scanner")
+	{
+		pth := writeScanner(t, tmpDir, "failing", "#!/usr/bin/env bash\nexit 1\n")
+
+		scanner := NewScanner("failing", pth)
+		_, err := scanner.DetectPlatform(context.Background(), tmpDir)
+		require.Error(t, err)
+	}
+
+	t.Log("hanging scanner is killed when the ctx is done")
+	{
+		pth := writeScanner(t, tmpDir, "hanging", "#!/usr/bin/env bash\nsleep 30\n")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		scanner := NewScanner("hanging", pth)
+		_, err := scanner.DetectPlatform(ctx, tmpDir)
+		require.Error(t, err)
+		require.True(t, time.Since(start) < 10*time.Second)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("external")
+	require.NoError(t, err)
+
+	binDir := filepath.Join(tmpDir, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	writeScanner(t, binDir, "bitrise-init-scanner-unity", unityScannerContent)
+	writeScanner(t, binDir, "bitrise-init-scanner-kmp.sh", unityScannerContent)
+	require.NoError(t, fileutil.WriteStringToFile(filepath.Join(binDir, "bitrise-init-scanner-not-executable"), ""))
+	writeScanner(t, binDir, "other-tool", unityScannerContent)
+
+	t.Log("from dirs")
+	{
+		scanners, err := FromDirs([]string{binDir})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(scanners))
+		require.Equal(t, "kmp", scanners[0].Name())
+		require.Equal(t, filepath.Join(binDir, "bitrise-init-scanner-kmp.sh"), scanners[0].Path)
+		require.Equal(t, "unity", scanners[1].Name())
+	}
+
+	t.Log("from config")
+	{
+		configPth := filepath.Join(tmpDir, "scanners.yml")
+		require.NoError(t, fileutil.WriteStringToFile(configPth, `scanners:
+- name: flutter
+  path: ./bin/bitrise-init-scanner-unity
+  args: ["--flutter"]
+- name: react-native
+  path: rn-scanner
+`))
+
+		scanners, err := FromConfig(configPth)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(scanners))
+		require.Equal(t, "flutter", scanners[0].Name())
+		require.Equal(t, filepath.Join(binDir, "bitrise-init-scanner-unity"), scanners[0].Path)
+		require.Equal(t, []string{"--flutter"}, scanners[0].Args)
+		require.Equal(t, "react-native", scanners[1].Name())
+		require.Equal(t, "rn-scanner", scanners[1].Path)
+	}
+
+	t.Log("name collision with built-in scanner")
+	{
+		_, err := Discover(binDir, "", []string{"unity"})
+		require.Error(t, err)
+	}
+
+	t.Log("path list and config")
+	{
+		scanners, err := Discover(binDir+string(os.PathListSeparator)+binDir+"-missing-is-error", "", []string{"android"})
+		require.Error(t, err)
+		require.Equal(t, 0, len(scanners))
+
+		scanners, err = Discover(binDir, filepath.Join(tmpDir, "scanners.yml"), []string{"android"})
+		require.NoError(t, err)
+		require.Equal(t, 4, len(scanners))
+	}
+}
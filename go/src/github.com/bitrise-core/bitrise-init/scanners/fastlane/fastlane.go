@@ -1,7 +1,9 @@
 package fastlane
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
@@ -16,6 +18,7 @@ const scannerName = "fastlane"
 
 const (
 	configName        = "fastlane-config"
+	androidConfigName = "fastlane-android-config"
 	defaultConfigName = "default-fastlane-config"
 )
 
@@ -44,6 +47,9 @@ const (
 // Scanner ...
 type Scanner struct {
 	Fastfiles []string
+
+	// configNames are the configs referenced by the options
+	configNames map[string]bool
 }
 
 // NewScanner ...
@@ -57,7 +63,7 @@ func (scanner Scanner) Name() string {
 }
 
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
 	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
@@ -94,10 +100,11 @@ func (scanner *Scanner) ExcludedScannerNames() []string {
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
 	warnings := models.Warnings{}
 
 	isValidFastfileFound := false
+	scanner.configNames = map[string]bool{}
 
 	// Inspect Fastfiles
 
@@ -109,13 +116,27 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 		workDir := utility.FastlaneWorkDir(fastfile)
 		log.Printft("fastlane work dir: %s", workDir)
 
-		lanes, err := utility.InspectFastfile(fastfile)
+		inspection, err := utility.InspectFastfile(fastfile)
 		if err != nil {
 			log.Warnft("Failed to inspect Fastfile, error: %s", err)
 			warnings = append(warnings, fmt.Sprintf("Failed to inspect Fastfile (%s), error: %s", fastfile, err))
 			continue
 		}
 
+		for _, gitImport := range inspection.GitImports {
+			log.Warnft("Lanes imported from git are not inspected: %s", gitImport)
+			warnings = append(warnings, fmt.Sprintf("Fastfile (%s) imports lanes from git (%s), these lanes are not listed", fastfile, gitImport))
+		}
+
+		lanes := inspection.Lanes
+
+		environment, err := utility.DetectFastlaneEnvironment(fastfile, inspection)
+		if err != nil {
+			log.Warnft("Failed to inspect fastlane environment, error: %s", err)
+			warnings = append(warnings, fmt.Sprintf("Failed to inspect fastlane environment of Fastfile (%s), error: %s", fastfile, err))
+		}
+		warnings = append(warnings, environmentWarnings(environment)...)
+
 		log.Printft("%d lanes found", len(lanes))
 
 		if len(lanes) == 0 {
@@ -128,12 +149,25 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 
 		laneOption := models.NewOption(laneInputTitle, laneInputEnvKey)
 		workDirOption.AddOption(workDir, laneOption)
+		workDirOption.AddValueDescription(workDir, environmentDescription(environment))
 
 		for _, lane := range lanes {
-			log.Printft("- %s", lane)
-
-			configOption := models.NewConfigOption(configName)
-			laneOption.AddConfig(lane, configOption)
+			if lane.Description != "" {
+				log.Printft("- %s: %s", lane, lane.Description)
+			} else {
+				log.Printft("- %s", lane)
+			}
+
+			laneConfigName := configName
+			if platform := lane.Platform; platform == "android" || (platform == "" && inspection.DefaultPlatform == "android") {
+				// android lanes do not need the iOS code signing files
+				laneConfigName = androidConfigName
+			}
+			scanner.configNames[laneConfigName] = true
+
+			configOption := models.NewConfigOption(laneConfigName)
+			laneOption.AddConfig(lane.String(), configOption)
+			laneOption.AddValueDescription(lane.String(), lane.Description)
 		}
 	}
 
@@ -161,6 +195,35 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 
 // Configs ...
 func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+	configMap := models.BitriseConfigMap{}
+
+	if scanner.configNames[androidConfigName] {
+		configBuilder := models.NewDefaultConfigBuilder()
+
+		configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
+
+		configBuilder.AppendMainStepList(steps.FastlaneStepListItem(
+			envmanModels.EnvironmentItemModel{laneInputKey: "$" + laneInputEnvKey},
+			envmanModels.EnvironmentItemModel{workDirInputKey: "$" + workDirInputEnvKey},
+		))
+
+		config, err := configBuilder.Generate(scannerName)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		configMap[androidConfigName] = string(data)
+	}
+
+	if !scanner.configNames[configName] {
+		return configMap, nil
+	}
+
 	configBuilder := models.NewDefaultConfigBuilder()
 
 	configBuilder.AppendPreparStepList(steps.CertificateAndProfileInstallerStepListItem())
@@ -180,9 +243,9 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		return models.BitriseConfigMap{}, err
 	}
 
-	return models.BitriseConfigMap{
-		configName: string(data),
-	}, nil
+	configMap[configName] = string(data)
+
+	return configMap, nil
 }
 
 // DefaultConfigs ...
@@ -210,3 +273,73 @@ func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
 		defaultConfigName: string(data),
 	}, nil
 }
+
+// environmentDescription summarizes how fastlane runs in the work dir, like:
+// fastlane 2.100.0 via bundler, plugins: versioning, match (git), app: io.bitrise.sample, team: 72SA8V3WYL
+func environmentDescription(environment utility.FastlaneEnvironmentModel) string {
+	parts := []string{}
+
+	if environment.GemfilePath != "" {
+		if environment.FastlaneVersion != "" {
+			parts = append(parts, fmt.Sprintf("fastlane %s via bundler", environment.FastlaneVersion))
+		} else {
+			parts = append(parts, "fastlane via bundler")
+		}
+	}
+	if len(environment.Plugins) > 0 {
+		parts = append(parts, "plugins: "+strings.Join(environment.Plugins, ", "))
+	}
+	if environment.UsesMatch {
+		parts = append(parts, fmt.Sprintf("match (%s)", environment.MatchStorageMode))
+	}
+	if environment.AppIdentifier != "" {
+		parts = append(parts, "app: "+environment.AppIdentifier)
+	}
+	if environment.PackageName != "" {
+		parts = append(parts, "package: "+environment.PackageName)
+	}
+	if environment.TeamID != "" {
+		parts = append(parts, "team: "+environment.TeamID)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func environmentWarnings(environment utility.FastlaneEnvironmentModel) models.Warnings {
+	warnings := models.Warnings{}
+
+	if environment.GemfilePath != "" {
+		log.Printft("Gemfile: %s", environment.GemfilePath)
+		if environment.GemfileLockPath == "" {
+			log.Warnft("Gemfile.lock not found")
+			warnings = append(warnings, fmt.Sprintf("Gemfile (%s) lists fastlane but no Gemfile.lock found, commit Gemfile.lock to run the same fastlane version on every build", environment.GemfilePath))
+		} else {
+			log.Printft("fastlane version in Gemfile.lock: %s", environment.FastlaneVersion)
+		}
+	}
+
+	if environment.PluginfilePath != "" {
+		log.Printft("Pluginfile: %s", environment.PluginfilePath)
+		log.Printft("plugins: %s", strings.Join(environment.Plugins, ", "))
+		if environment.GemfilePath == "" || !environment.PluginfileLoaded {
+			log.Warnft("Pluginfile is not loaded by a Gemfile")
+			warnings = append(warnings, fmt.Sprintf("Pluginfile (%s) found, but it is not loaded by a Gemfile listing fastlane, the plugins will not be installed", environment.PluginfilePath))
+		}
+	}
+
+	if environment.UsesMatch {
+		log.Printft("match storage mode: %s", environment.MatchStorageMode)
+		if environment.MatchStorageMode == "git" || environment.MatchStorageMode == "s3" {
+			warnings = append(warnings, "match configured but no MATCH_PASSWORD secret likely set, add it as a secret env var to let match decrypt the code signing files")
+		}
+	}
+
+	if environment.AppIdentifier != "" {
+		log.Printft("app identifier: %s", environment.AppIdentifier)
+	}
+	if environment.TeamID != "" {
+		log.Printft("team id: %s", environment.TeamID)
+	}
+
+	return warnings
+}
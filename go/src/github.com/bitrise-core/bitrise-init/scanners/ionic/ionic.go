@@ -1,6 +1,7 @@
 package ionic
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/android"
 	"github.com/bitrise-core/bitrise-init/scanners/cordova"
+	"github.com/bitrise-core/bitrise-init/scanners/javascript"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
 	envmanModels "github.com/bitrise-io/envman/models"
@@ -22,6 +24,17 @@ const (
 	defaultConfigName = "default-ionic-config"
 )
 
+const capacitorConfigNameFormat = "ionic-capacitor-%s-config"
+
+// Capacitor generates the native projects into these paths of the Ionic project dir
+const (
+	capacitorIOSWorkspacePath = "ios/App/App.xcworkspace"
+	capacitorIOSScheme        = "App"
+	capacitorGradlewPath      = "android/gradlew"
+	capacitorGradleFilePath   = "android/build.gradle"
+	capacitorGradleTask       = "assembleRelease"
+)
+
 // Step inputs
 
 const (
@@ -36,15 +49,36 @@ const (
 	platformInputEnvKey = "IONIC_PLATFORM"
 )
 
+const (
+	scriptContentInputKey = "content"
+	scriptWorkDirInputKey = "working_dir"
+)
+
+const (
+	xcodeProjectPathInputKey = "project_path"
+	xcodeSchemeInputKey      = "scheme"
+)
+
+const (
+	gradleFileInputKey  = "gradle_file"
+	gradleTaskInputKey  = "gradle_task"
+	gradlewPathInputKey = "gradlew_path"
+)
+
 //------------------
 // ScannerInterface
 //------------------
 
 // Scanner ...
 type Scanner struct {
-	ionicConfigPth      string
-	relIonicConfigDir   string
-	searchDir           string
+	ionicConfigPth    string
+	relIonicConfigDir string
+	searchDir         string
+	jsDependencies    utility.JSDependenciesModel
+	ionicProject      utility.IonicProjectModel
+	tests             []utility.JSTestModel
+	// capacitorPlatformAdded maps the platforms to whether their native project is committed (npx cap add was run)
+	capacitorPlatformAdded map[string]bool
 }
 
 // NewScanner ...
@@ -58,7 +92,7 @@ func (scanner Scanner) Name() string {
 }
 
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
 	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
@@ -98,7 +132,7 @@ func (scanner *Scanner) ExcludedScannerNames() []string {
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
 	warnings := models.Warnings{}
 
 	// Get relative ionic.config.json dir
@@ -114,11 +148,63 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 	scanner.relIonicConfigDir = relIonicConfigDir
 	// ---
 
+	jsDependencies, err := javascript.DetectDependencies(configDir, scanner.searchDir)
+	if err != nil {
+		return models.OptionModel{}, warnings, err
+	}
+	scanner.jsDependencies = jsDependencies
+
+	tests, err := javascript.DetectTests(configDir)
+	if err != nil {
+		return models.OptionModel{}, warnings, err
+	}
+	scanner.tests = tests
+
+	log.Infoft("Detecting Ionic project type")
+
+	ionicProject, err := utility.ClassifyIonicProject(scanner.ionicConfigPth)
+	if err != nil {
+		return models.OptionModel{}, warnings, fmt.Errorf("Failed to parse ionic.config.json, error: %s", err)
+	}
+	scanner.ionicProject = ionicProject
+
+	if ionicProject.Type != "" {
+		log.Printft("type: %s", ionicProject.Type)
+	}
+	log.Printft("generation: %s", ionicProject.Generation)
+	log.Printft("integration: %s", ionicProject.Integration)
+	if ionicProject.CapacitorConfigPath != "" {
+		log.Printft("capacitor config: %s", ionicProject.CapacitorConfigPath)
+	}
+
 	// Options
 	var rootOption *models.OptionModel
 
 	platforms := []string{"ios", "android"}
 
+	platformConfigName := func(platform string) string {
+		return configName
+	}
+
+	if ionicProject.Integration == utility.IonicIntegrationCapacitor {
+		scanner.capacitorPlatformAdded = map[string]bool{}
+
+		for _, platform := range platforms {
+			exist, err := utility.CapacitorNativeProjectExists(configDir, platform)
+			if err != nil {
+				return models.OptionModel{}, warnings, err
+			}
+			scanner.capacitorPlatformAdded[platform] = exist
+
+			if !exist {
+				log.Warnft("%s native project not found, it will be generated by npx cap add %s", platform, platform)
+				warnings = append(warnings, fmt.Sprintf("Capacitor %s native project not found in %s, commit it (npx cap add %s) to keep the native project settings under version control", platform, filepath.Join(relIonicConfigDir, platform), platform))
+			}
+		}
+
+		platformConfigName = capacitorConfigName
+	}
+
 	if relIonicConfigDir != "" {
 		rootOption = models.NewOption(ionicProjectPathInputTitle, ionicProjectPathInputEnvKey)
 
@@ -126,14 +212,14 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 		rootOption.AddOption(relIonicConfigDir, projectTypeOption)
 
 		for _, platform := range platforms {
-			configOption := models.NewConfigOption(configName)
+			configOption := models.NewConfigOption(platformConfigName(platform))
 			projectTypeOption.AddConfig(platform, configOption)
 		}
 	} else {
 		rootOption = models.NewOption(platformInputTitle, platformInputEnvKey)
 
 		for _, platform := range platforms {
-			configOption := models.NewConfigOption(configName)
+			configOption := models.NewConfigOption(platformConfigName(platform))
 			rootOption.AddConfig(platform, configOption)
 		}
 	}
@@ -155,12 +241,34 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 
 // Configs ...
 func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
-	configBuilder := models.NewDefaultConfigBuilder()
+	if scanner.ionicProject.Integration == utility.IonicIntegrationCapacitor {
+		configMap := models.BitriseConfigMap{}
+		for _, platform := range []string{"ios", "android"} {
+			config, err := scanner.config(platform)
+			if err != nil {
+				return models.BitriseConfigMap{}, err
+			}
+			configMap[capacitorConfigName(platform)] = config
+		}
+		return configMap, nil
+	}
 
-	if scanner.relIonicConfigDir != "" {
-		configBuilder.AppendPreparStepList(steps.ChangeWorkDirStepListItem(envmanModels.EnvironmentItemModel{ionicProjectPathInputKey: "$" + ionicProjectPathInputEnvKey}))
+	config, err := scanner.config("")
+	if err != nil {
+		return models.BitriseConfigMap{}, err
 	}
 
+	return models.BitriseConfigMap{
+		configName: config,
+	}, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+
+	configBuilder.AppendDependencyStepList(javascript.DefaultInstallDependenciesStepListItem("$BITRISE_SOURCE_DIR"))
+
 	configBuilder.AppendMainStepList(steps.IonicBuildStepListItem(envmanModels.EnvironmentItemModel{
 		platformInputKey: "$" + platformInputEnvKey,
 	}))
@@ -176,29 +284,111 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 	}
 
 	return models.BitriseConfigMap{
-		configName: string(data),
+		defaultConfigName: string(data),
 	}, nil
 }
 
-// DefaultConfigs ...
-func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+func capacitorConfigName(platform string) string {
+	return fmt.Sprintf(capacitorConfigNameFormat, platform)
+}
+
+func capacitorSyncScriptContent(platform string, addPlatform bool) string {
+	content := "#!/usr/bin/env bash\nset -ex\n\n"
+	if addPlatform {
+		content += "npx cap add " + platform + "\n"
+	}
+	return content + "npx cap sync " + platform + "\n"
+}
+
+// config generates the config of the project, capacitorPlatform is the native platform
+// to build for Capacitor projects and it is empty for Cordova ones.
+// If tests were detected, the primary workflow runs the tests and a deploy workflow
+// runs the tests and builds the app, otherwise the primary workflow builds the app.
+func (scanner *Scanner) config(capacitorPlatform string) (string, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
-	configBuilder.AppendMainStepList(steps.IonicBuildStepListItem(envmanModels.EnvironmentItemModel{
-		platformInputKey: "$" + platformInputEnvKey,
-	}))
+	workflows := []models.WorkflowID{models.PrimaryWorkflowID}
+	if len(scanner.tests) > 0 {
+		configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+		workflows = append(workflows, models.DeployWorkflowID)
+	}
+
+	projectDir := javascript.WorkDir(scanner.relIonicConfigDir)
+	jsDependencies := scanner.jsDependencies
+
+	changeWorkDir := scanner.relIonicConfigDir != "" && capacitorPlatform == ""
+	if changeWorkDir {
+		// change-workdir points $BITRISE_SOURCE_DIR to the ionic project dir
+		projectDir = javascript.WorkDir("")
+
+		var err error
+		if jsDependencies, err = jsDependencies.RelativeTo(scanner.relIonicConfigDir); err != nil {
+			return "", err
+		}
+	}
+
+	for _, workflow := range workflows {
+		if changeWorkDir {
+			configBuilder.AppendPreparStepListTo(workflow, steps.ChangeWorkDirStepListItem(envmanModels.EnvironmentItemModel{ionicProjectPathInputKey: "$" + ionicProjectPathInputEnvKey}))
+		}
+
+		configBuilder.AddCachePathsTo(workflow, javascript.CachePaths(jsDependencies)...)
+		configBuilder.AppendDependencyStepListTo(workflow, javascript.InstallDependenciesStepListItem(jsDependencies))
+
+		for _, test := range scanner.tests {
+			configBuilder.AppendMainStepListTo(workflow, javascript.TestStepListItem(jsDependencies, projectDir, test))
+		}
+
+		if workflow == models.PrimaryWorkflowID && len(scanner.tests) > 0 {
+			// CI workflow, only runs the tests
+			continue
+		}
+
+		switch capacitorPlatform {
+		case "":
+			configBuilder.AppendMainStepListTo(workflow, steps.IonicBuildStepListItem(envmanModels.EnvironmentItemModel{
+				platformInputKey: "$" + platformInputEnvKey,
+			}))
+		case "ios":
+			configBuilder.AppendPreparStepListTo(workflow, steps.CertificateAndProfileInstallerStepListItem())
+			configBuilder.AddCachePathsTo(workflow, utility.CocoaPodsCachePaths(filepath.Join(projectDir, filepath.Dir(capacitorIOSWorkspacePath)))...)
+			scanner.appendCapacitorSyncStepList(configBuilder, workflow, capacitorPlatform)
+			configBuilder.AppendMainStepListTo(workflow, steps.XcodeArchiveStepListItem(
+				envmanModels.EnvironmentItemModel{xcodeProjectPathInputKey: filepath.Join(projectDir, capacitorIOSWorkspacePath)},
+				envmanModels.EnvironmentItemModel{xcodeSchemeInputKey: capacitorIOSScheme},
+			))
+		case "android":
+			configBuilder.AppendPreparStepListTo(workflow, steps.InstallMissingAndroidToolsStepListItem())
+			configBuilder.AddCachePathsTo(workflow, utility.GradleCachePaths(filepath.Join(projectDir, filepath.Dir(capacitorGradlewPath)))...)
+			scanner.appendCapacitorSyncStepList(configBuilder, workflow, capacitorPlatform)
+			configBuilder.AppendMainStepListTo(workflow, steps.GradleRunnerStepListItem(
+				envmanModels.EnvironmentItemModel{gradleFileInputKey: filepath.Join(projectDir, capacitorGradleFilePath)},
+				envmanModels.EnvironmentItemModel{gradleTaskInputKey: capacitorGradleTask},
+				envmanModels.EnvironmentItemModel{gradlewPathInputKey: filepath.Join(projectDir, capacitorGradlewPath)},
+			))
+		}
+	}
 
 	config, err := configBuilder.Generate(ScannerName)
 	if err != nil {
-		return models.BitriseConfigMap{}, err
+		return "", err
 	}
 
 	data, err := yaml.Marshal(config)
 	if err != nil {
-		return models.BitriseConfigMap{}, err
+		return "", err
 	}
 
-	return models.BitriseConfigMap{
-		defaultConfigName: string(data),
-	}, nil
+	return string(data), nil
+}
+
+// appendCapacitorSyncStepList appends the steps, which build the web assets and copy them into the native project.
+func (scanner *Scanner) appendCapacitorSyncStepList(configBuilder *models.ConfigBuilderModel, workflow models.WorkflowID, platform string) {
+	projectDir := javascript.WorkDir(scanner.relIonicConfigDir)
+
+	configBuilder.AppendMainStepListTo(workflow, javascript.RunScriptStepListItem(scanner.jsDependencies, projectDir, "build"))
+	configBuilder.AppendMainStepListTo(workflow, steps.ScriptSteplistItem("Sync Capacitor "+platform+" project",
+		envmanModels.EnvironmentItemModel{scriptContentInputKey: capacitorSyncScriptContent(platform, !scanner.capacitorPlatformAdded[platform])},
+		envmanModels.EnvironmentItemModel{scriptWorkDirInputKey: projectDir},
+	))
 }
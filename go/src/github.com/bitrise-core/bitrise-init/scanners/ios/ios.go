@@ -1,6 +1,7 @@
 package ios
 
 import (
+	"context"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/xcode"
 	"github.com/bitrise-core/bitrise-init/utility"
@@ -27,7 +28,7 @@ func (scanner *Scanner) Name() string {
 }
 
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
 	scanner.searchDir = searchDir
 
 	detected, err := xcode.Detect(utility.XcodeProjectTypeIOS, searchDir)
@@ -44,8 +45,8 @@ func (scanner *Scanner) ExcludedScannerNames() []string {
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
-	options, configDescriptors, warnings, err := xcode.GenerateOptions(utility.XcodeProjectTypeIOS, scanner.searchDir)
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
+	options, configDescriptors, warnings, err := xcode.GenerateOptions(ctx, utility.XcodeProjectTypeIOS, scanner.searchDir)
 	if err != nil {
 		return models.OptionModel{}, warnings, err
 	}
@@ -0,0 +1,207 @@
+package javascript
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/log"
+)
+
+// Step Inputs
+const (
+	commandInputKey = "command"
+	workDirInputKey = "workdir"
+)
+
+const (
+	scriptContentInputKey = "content"
+	scriptWorkDirInputKey = "working_dir"
+)
+
+const (
+	sourceDirEnv = "$BITRISE_SOURCE_DIR"
+)
+
+// DetectDependencies detects the package manager of the JavaScript project placed in projectDir,
+// searchDir is the root of the scan, workspace roots are searched up to this directory.
+func DetectDependencies(projectDir, searchDir string) (utility.JSDependenciesModel, error) {
+	log.Infoft("Searching for JavaScript package manager")
+
+	dependencies, err := utility.DetectJSDependencies(projectDir, searchDir)
+	if err != nil {
+		return utility.JSDependenciesModel{}, fmt.Errorf("failed to detect JavaScript package manager, error: %s", err)
+	}
+
+	log.Printft("package manager: %s", dependencies.PackageManager)
+	if dependencies.Lockfile != "" {
+		log.Printft("lockfile: %s", dependencies.Lockfile)
+	}
+	if dependencies.IsWorkspace {
+		log.Printft("project is part of a workspace, dependencies will be installed in: %s", dependencies.InstallDir)
+	}
+
+	return dependencies, nil
+}
+
+// WorkDir returns the given (scanned dir relative) directory, in a form that can be used as a step input.
+func WorkDir(relDir string) string {
+	relDir = filepath.Clean(relDir)
+	if relDir == "" || relDir == "." {
+		return sourceDirEnv
+	}
+	// filepath.Join would clean the leading .. elements away
+	return sourceDirEnv + "/" + relDir
+}
+
+// InstallDir returns the dependency install directory, in a form that can be used as a step input.
+func InstallDir(dependencies utility.JSDependenciesModel) string {
+	return WorkDir(dependencies.InstallDir)
+}
+
+// InstallDependenciesStepListItem returns the step, which installs the dependencies with the detected package manager.
+func InstallDependenciesStepListItem(dependencies utility.JSDependenciesModel) bitriseModels.StepListItemModel {
+	workDir := InstallDir(dependencies)
+
+	switch dependencies.PackageManager {
+	case utility.JSPackageManagerYarn:
+		return steps.YarnStepListItem(
+			envmanModels.EnvironmentItemModel{commandInputKey: "install"},
+			envmanModels.EnvironmentItemModel{workDirInputKey: workDir},
+		)
+	case utility.JSPackageManagerPNPM:
+		return steps.ScriptSteplistItem("Install dependencies with pnpm",
+			envmanModels.EnvironmentItemModel{scriptContentInputKey: pnpmInstallScriptContent(dependencies.Lockfile != "")},
+			envmanModels.EnvironmentItemModel{scriptWorkDirInputKey: workDir},
+		)
+	}
+
+	command := "install"
+	if dependencies.Lockfile != "" {
+		// npm ci installs exactly the locked dependency tree
+		command = "ci"
+	}
+	return steps.NpmStepListItem(
+		envmanModels.EnvironmentItemModel{commandInputKey: command},
+		envmanModels.EnvironmentItemModel{workDirInputKey: workDir},
+	)
+}
+
+// DefaultInstallDependenciesStepListItem returns the dependency install step used in the default configs.
+func DefaultInstallDependenciesStepListItem(workDir string) bitriseModels.StepListItemModel {
+	return steps.NpmStepListItem(
+		envmanModels.EnvironmentItemModel{commandInputKey: "install"},
+		envmanModels.EnvironmentItemModel{workDirInputKey: workDir},
+	)
+}
+
+// RunScriptStepListItem returns the step, which runs the given package.json script in workDir with the detected package manager.
+func RunScriptStepListItem(dependencies utility.JSDependenciesModel, workDir, script string) bitriseModels.StepListItemModel {
+	switch dependencies.PackageManager {
+	case utility.JSPackageManagerYarn:
+		return steps.YarnStepListItem(
+			envmanModels.EnvironmentItemModel{commandInputKey: "run " + script},
+			envmanModels.EnvironmentItemModel{workDirInputKey: workDir},
+		)
+	case utility.JSPackageManagerPNPM:
+		return steps.ScriptSteplistItem("Run "+script+" script with pnpm",
+			envmanModels.EnvironmentItemModel{scriptContentInputKey: "#!/usr/bin/env bash\nset -ex\n\npnpm run " + script + "\n"},
+			envmanModels.EnvironmentItemModel{scriptWorkDirInputKey: workDir},
+		)
+	}
+
+	return steps.NpmStepListItem(
+		envmanModels.EnvironmentItemModel{commandInputKey: "run " + script},
+		envmanModels.EnvironmentItemModel{workDirInputKey: workDir},
+	)
+}
+
+// DetectTests detects the unit and e2e tests of the JavaScript project placed in projectDir.
+func DetectTests(projectDir string) ([]utility.JSTestModel, error) {
+	log.Infoft("Searching for tests")
+
+	tests, err := utility.DetectJSTests(projectDir)
+	if err != nil {
+		return []utility.JSTestModel{}, fmt.Errorf("failed to detect tests, error: %s", err)
+	}
+
+	for _, test := range tests {
+		kind := "unit"
+		if test.IsE2E {
+			kind = "e2e"
+		}
+		log.Printft("%s %s test found, config: %s, script: %s", test.Framework, kind, test.ConfigPath, test.Script)
+	}
+	if len(tests) == 0 {
+		log.Printft("no tests found")
+	}
+
+	return tests, nil
+}
+
+// TestStepListItem returns the step, which runs the given test in workDir.
+// The package.json script of the test is preferred, otherwise the framework's cli is called directly.
+func TestStepListItem(dependencies utility.JSDependenciesModel, workDir string, test utility.JSTestModel) bitriseModels.StepListItemModel {
+	if test.Script != "" {
+		script := test.Script
+		if strings.Contains(test.ScriptCommand, "ng test") {
+			// ng test watches for changes and opens a browser by default
+			script += " -- --watch=false --browsers=ChromeHeadless"
+		}
+		return RunScriptStepListItem(dependencies, workDir, script)
+	}
+
+	command := ""
+	switch test.Framework {
+	case utility.JSTestFrameworkKarma:
+		return steps.KarmaJasmineTestRunnerStepListItem(envmanModels.EnvironmentItemModel{workDirInputKey: workDir})
+	case utility.JSTestFrameworkJasmine:
+		return steps.JasmineTestRunnerStepListItem(envmanModels.EnvironmentItemModel{workDirInputKey: workDir})
+	case utility.JSTestFrameworkJest:
+		command = "npx jest --ci"
+	case utility.JSTestFrameworkProtractor:
+		command = "npx protractor " + test.ConfigPath
+	case utility.JSTestFrameworkCypress:
+		command = "npx cypress run"
+	}
+
+	return steps.ScriptSteplistItem("Run "+string(test.Framework)+" tests",
+		envmanModels.EnvironmentItemModel{scriptContentInputKey: "#!/usr/bin/env bash\nset -ex\n\n" + command + "\n"},
+		envmanModels.EnvironmentItemModel{scriptWorkDirInputKey: workDir},
+	)
+}
+
+// CachePaths returns the dependencies' cache paths, moved into the source dir the workflow runs in.
+func CachePaths(dependencies utility.JSDependenciesModel) []string {
+	cachePaths := []string{}
+	for _, cachePath := range dependencies.CachePaths() {
+		// cache paths are relative to the scanned dir, make them (and their indicators) point into the source dir
+		split := strings.Split(cachePath, " -> ")
+		for i, pth := range split {
+			split[i] = WorkDir(pth)
+		}
+		cachePaths = append(cachePaths, strings.Join(split, " -> "))
+	}
+	return cachePaths
+}
+
+func pnpmInstallScriptContent(hasLockfile bool) string {
+	installCommand := "pnpm install"
+	if hasLockfile {
+		installCommand += " --frozen-lockfile"
+	}
+
+	return `#!/usr/bin/env bash
+set -ex
+
+if ! command -v pnpm > /dev/null ; then
+  npm install -g pnpm
+fi
+
+` + installCommand + `
+`
+}
@@ -0,0 +1,442 @@
+package kmp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/sliceutil"
+)
+
+// ScannerName ...
+const ScannerName = "kotlin-multiplatform"
+
+const (
+	configName        = "kotlin-multiplatform-config"
+	defaultConfigName = "default-kotlin-multiplatform-config"
+)
+
+const platformConfigNameFormat = "kotlin-multiplatform-%s-config"
+
+const sourceDirEnv = "$BITRISE_SOURCE_DIR"
+
+// The platforms of the generated configs.
+const (
+	androidPlatform = "android"
+	iosPlatform     = "ios"
+)
+
+// The gradle tasks of the Kotlin Multiplatform and the kotlin cocoapods gradle plugins.
+const (
+	allTestsGradleTask        = "allTests"
+	podInstallGradleTask      = "podInstall"
+	assembleReleaseGradleTask = "assembleRelease"
+)
+
+var rootGradleFileBasePaths = []string{"build.gradle.kts", "build.gradle"}
+
+// Step Inputs
+const (
+	gradlewPathInputKey    = "gradlew_path"
+	gradlewPathInputEnvKey = "GRADLEW_PATH"
+	gradlewPathInputTitle  = "Gradlew file path"
+)
+
+const (
+	gradleFileInputKey    = "gradle_file"
+	gradleFileInputEnvKey = "GRADLE_BUILD_FILE_PATH"
+	gradleFileInputTitle  = "Path to the gradle file to use"
+)
+
+const (
+	gradleTaskInputKey    = "gradle_task"
+	gradleTaskInputEnvKey = "GRADLE_TASK"
+	gradleTaskInputTitle  = "Gradle task to run"
+)
+
+const (
+	platformInputEnvKey = "KMP_PLATFORM"
+	platformInputTitle  = "Platform to build"
+)
+
+const (
+	xcodeProjectPathInputKey = "project_path"
+	xcodeSchemeInputKey      = "scheme"
+)
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	fileList   []string
+	searchDir  string
+	kmpModules []utility.KMPModuleModel
+
+	// relRootDir is the (scanned dir relative) directory of the gradle wrapper, it is empty for the scanned dir.
+	relRootDir       string
+	sharedModule     utility.KMPModuleModel
+	sharedModuleName string
+	androidAppModule string
+	iosApp           *utility.KMPIOSAppModel
+	platforms        []string
+}
+
+// NewScanner ...
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return ScannerName
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
+	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
+	}
+
+	// Search for Kotlin Multiplatform modules
+	log.Infoft("Searching for gradle files applying the Kotlin Multiplatform plugin")
+
+	kmpModules, err := utility.FilterKMPModules(fileList)
+	if err != nil {
+		return false, fmt.Errorf("failed to search for Kotlin Multiplatform modules, error: %s", err)
+	}
+
+	log.Printft("%d Kotlin Multiplatform modules detected", len(kmpModules))
+	for _, module := range kmpModules {
+		log.Printft("- %s", module.GradleFile)
+	}
+
+	if len(kmpModules) == 0 {
+		log.Printft("platform not detected")
+		return false, nil
+	}
+
+	log.Doneft("Platform detected")
+
+	scanner.fileList = fileList
+	scanner.searchDir = searchDir
+	scanner.kmpModules = kmpModules
+
+	return true, nil
+}
+
+// ExcludedScannerNames ...
+func (scanner *Scanner) ExcludedScannerNames() []string {
+	return []string{
+		string(utility.XcodeProjectTypeIOS),
+		android.ScannerName,
+	}
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
+	warnings := models.Warnings{}
+
+	// the shallowest module is the shared one
+	scanner.sharedModule = scanner.kmpModules[0]
+	sharedModuleDir := filepath.Dir(scanner.sharedModule.GradleFile)
+
+	// Search for the gradle wrapper of the shared module
+	log.Infoft("Searching for gradlew files")
+
+	gradlewFiles, err := utility.FilterGradlewFiles(scanner.fileList)
+	if err != nil {
+		return models.OptionModel{}, warnings, fmt.Errorf("Failed to list gradlew files, error: %s", err)
+	}
+
+	gradlewPth := ""
+	for _, pth := range gradlewFiles {
+		// the deepest gradle wrapper containing the shared module,
+		// checked with filepath.Rel to handle the wrapper in the "." dir of the relative file list as well
+		relModuleDir, err := filepath.Rel(filepath.Dir(pth), sharedModuleDir)
+		if err != nil {
+			continue
+		}
+		if relModuleDir != ".." && !strings.HasPrefix(relModuleDir, ".."+string(filepath.Separator)) {
+			gradlewPth = pth
+		}
+	}
+
+	if gradlewPth == "" {
+		log.Errorft("No gradle wrapper (gradlew) found")
+		return models.OptionModel{}, warnings, fmt.Errorf("No Gradle Wrapper (gradlew) found for the Kotlin Multiplatform module (%s), the gradle wrapper is required to run the same Gradle version as locally", scanner.sharedModule.GradleFile)
+	}
+	log.Printft("gradlew: %s", gradlewPth)
+
+	rootDir := filepath.Dir(gradlewPth)
+	relRootDir, err := utility.RelPath(scanner.searchDir, rootDir)
+	if err != nil {
+		return models.OptionModel{}, warnings, fmt.Errorf("Failed to get relative gradle wrapper dir path, error: %s", err)
+	}
+	if relRootDir == "." {
+		relRootDir = ""
+	}
+	scanner.relRootDir = relRootDir
+
+	rootGradleFile := ""
+	for _, base := range rootGradleFileBasePaths {
+		if pth := filepath.Join(rootDir, base); sliceutil.IsStringInSlice(pth, scanner.fileList) {
+			rootGradleFile = pth
+			break
+		}
+	}
+	if rootGradleFile == "" {
+		return models.OptionModel{}, warnings, fmt.Errorf("No root gradle file found next to the gradle wrapper (%s)", gradlewPth)
+	}
+	// ---
+
+	// Inspect the shared module
+	log.Infoft("Inspecting the shared module: %s", scanner.sharedModule.GradleFile)
+
+	if scanner.sharedModuleName, err = gradleModuleName(rootDir, sharedModuleDir); err != nil {
+		return models.OptionModel{}, warnings, fmt.Errorf("Failed to get the shared module's name, error: %s", err)
+	}
+
+	targets := []string{}
+	for _, target := range scanner.sharedModule.Targets {
+		targets = append(targets, string(target))
+	}
+	log.Printft("targets: %s", strings.Join(targets, ", "))
+	// ---
+
+	// Search for the Android app module
+	androidModules, err := utility.AndroidModules(scanner.fileList, rootGradleFile)
+	if err != nil {
+		return models.OptionModel{}, warnings, fmt.Errorf("Failed to search for the Android app module, error: %s", err)
+	}
+
+	scanner.androidAppModule = ""
+	for _, module := range androidModules {
+		if module.IsApp {
+			if scanner.androidAppModule, err = gradleModuleName(rootDir, filepath.Dir(module.GradleFile)); err != nil {
+				return models.OptionModel{}, warnings, fmt.Errorf("Failed to get the Android app module's name, error: %s", err)
+			}
+			break
+		}
+	}
+	log.Printft("Android app module: %s", scanner.androidAppModule)
+	// ---
+
+	// Search for the iOS app
+	if scanner.iosApp, err = utility.KMPIOSApp(scanner.fileList, rootDir, scanner.sharedModule); err != nil {
+		return models.OptionModel{}, warnings, fmt.Errorf("Failed to search for the iOS app, error: %s", err)
+	}
+	if scanner.iosApp != nil {
+		log.Printft("iOS app: %s (framework integration: %s)", scanner.iosApp.ProjectPath, scanner.iosApp.FrameworkIntegration)
+	}
+	// ---
+
+	scanner.platforms = []string{}
+
+	if scanner.sharedModule.HasTarget(utility.KMPTargetAndroid) && scanner.androidAppModule != "" {
+		scanner.platforms = append(scanner.platforms, androidPlatform)
+	}
+
+	if scanner.iosApp != nil && len(scanner.sharedModule.IOSTargets()) == 0 {
+		warnings = append(warnings, fmt.Sprintf("The iOS app (%s) is not built, as the shared module (%s) does not declare iOS targets", scanner.iosApp.ProjectPath, scanner.sharedModule.GradleFile))
+	} else if scanner.iosApp != nil {
+		if scanner.iosApp.FrameworkIntegration == "" {
+			warnings = append(warnings, fmt.Sprintf("The iOS app (%s) does not embed the shared module's framework, add a Run Script build phase running the embedAndSignAppleFrameworkForXcode gradle task", scanner.iosApp.ProjectPath))
+		}
+		scanner.platforms = append(scanner.platforms, iosPlatform)
+	}
+
+	for _, warning := range warnings {
+		log.Warnft(warning)
+	}
+
+	// Options
+	gradlewPthOption := models.NewOption(gradlewPathInputTitle, gradlewPathInputEnvKey)
+
+	gradleFileOption := models.NewOption(gradleFileInputTitle, gradleFileInputEnvKey)
+	gradlewPthOption.AddOption(gradlewPth, gradleFileOption)
+
+	if len(scanner.platforms) == 0 {
+		// no app to build, only the shared module's tests run
+		configOption := models.NewConfigOption(configName)
+		gradleFileOption.AddConfig(rootGradleFile, configOption)
+
+		return *gradlewPthOption, warnings, nil
+	}
+
+	platformOption := models.NewOption(platformInputTitle, platformInputEnvKey)
+	gradleFileOption.AddOption(rootGradleFile, platformOption)
+
+	for _, platform := range scanner.platforms {
+		configOption := models.NewConfigOption(platformConfigName(platform))
+		platformOption.AddConfig(platform, configOption)
+	}
+	// ---
+
+	return *gradlewPthOption, warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	gradlewPthOption := models.NewOption(gradlewPathInputTitle, gradlewPathInputEnvKey)
+
+	gradleFileOption := models.NewOption(gradleFileInputTitle, gradleFileInputEnvKey)
+	gradlewPthOption.AddOption("_", gradleFileOption)
+
+	gradleTaskOption := models.NewOption(gradleTaskInputTitle, gradleTaskInputEnvKey)
+	gradleFileOption.AddOption("_", gradleTaskOption)
+
+	configOption := models.NewConfigOption(defaultConfigName)
+	gradleTaskOption.AddConfig("_", configOption)
+
+	return *gradlewPthOption
+}
+
+// Configs ...
+func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+	if len(scanner.platforms) == 0 {
+		config, err := scanner.config("")
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		return models.BitriseConfigMap{
+			configName: config,
+		}, nil
+	}
+
+	configMap := models.BitriseConfigMap{}
+	for _, platform := range scanner.platforms {
+		config, err := scanner.config(platform)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		configMap[platformConfigName(platform)] = config
+	}
+	return configMap, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+
+	configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
+	configBuilder.AddCachePaths(utility.GradleCachePaths(sourceDirEnv)...)
+
+	configBuilder.AppendMainStepList(gradleRunnerStepListItem("$" + gradleTaskInputEnvKey))
+
+	config, err := configBuilder.Generate(ScannerName)
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	return models.BitriseConfigMap{
+		defaultConfigName: string(data),
+	}, nil
+}
+
+func platformConfigName(platform string) string {
+	return fmt.Sprintf(platformConfigNameFormat, platform)
+}
+
+// gradleModuleName returns the gradle project path of the module placed in the moduleDir, like :shared.
+func gradleModuleName(rootDir, moduleDir string) (string, error) {
+	relModuleDir, err := filepath.Rel(rootDir, moduleDir)
+	if err != nil {
+		return "", err
+	}
+	if relModuleDir == "." {
+		return "", nil
+	}
+	return ":" + strings.Replace(filepath.ToSlash(relModuleDir), "/", ":", -1), nil
+}
+
+func gradleRunnerStepListItem(gradleTask string) bitriseModels.StepListItemModel {
+	return steps.GradleRunnerStepListItem(
+		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
+		envmanModels.EnvironmentItemModel{gradleTaskInputKey: gradleTask},
+		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
+	)
+}
+
+// config generates the config of the platform, the platform is empty if the project has no app to build.
+// The primary workflow runs the shared module's tests, the deploy workflow runs the tests and builds the platform's app.
+func (scanner *Scanner) config(platform string) (string, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+
+	workflows := []models.WorkflowID{models.PrimaryWorkflowID}
+	if platform != "" {
+		configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+		workflows = append(workflows, models.DeployWorkflowID)
+	}
+
+	gradleWrapperDir := sourceDirEnv
+	if scanner.relRootDir != "" {
+		gradleWrapperDir += "/" + filepath.ToSlash(scanner.relRootDir)
+	}
+
+	for _, workflow := range workflows {
+		if platform == "" || platform == androidPlatform {
+			configBuilder.AppendPreparStepListTo(workflow, steps.InstallMissingAndroidToolsStepListItem())
+		}
+		configBuilder.AddCachePathsTo(workflow, utility.GradleCachePaths(gradleWrapperDir)...)
+
+		configBuilder.AppendMainStepListTo(workflow, gradleRunnerStepListItem(scanner.sharedModuleName+":"+allTestsGradleTask))
+
+		if workflow == models.PrimaryWorkflowID && platform != "" {
+			// CI workflow, only runs the tests
+			continue
+		}
+
+		switch platform {
+		case androidPlatform:
+			configBuilder.AppendMainStepListTo(workflow, gradleRunnerStepListItem(scanner.androidAppModule+":"+assembleReleaseGradleTask))
+			configBuilder.AppendMainStepListTo(workflow, steps.SignAPKStepListItem())
+		case iosPlatform:
+			configBuilder.AppendPreparStepListTo(workflow, steps.CertificateAndProfileInstallerStepListItem())
+
+			projectPth := scanner.iosApp.ProjectPath
+			if scanner.iosApp.FrameworkIntegration == utility.KMPFrameworkIntegrationCocoaPods {
+				// the kotlin cocoapods gradle plugin generates the shared framework's podspec and runs pod install
+				configBuilder.AddCachePathsTo(workflow, utility.CocoaPodsCachePaths(sourceDirEnv+"/"+filepath.ToSlash(filepath.Dir(projectPth)))...)
+				configBuilder.AppendMainStepListTo(workflow, gradleRunnerStepListItem(scanner.sharedModuleName+":"+podInstallGradleTask))
+				projectPth = scanner.iosApp.WorkspacePath
+			}
+
+			// the direct integration's build phase builds and embeds the shared framework through the gradle wrapper
+			configBuilder.AppendMainStepListTo(workflow, steps.XcodeArchiveStepListItem(
+				envmanModels.EnvironmentItemModel{xcodeProjectPathInputKey: projectPth},
+				envmanModels.EnvironmentItemModel{xcodeSchemeInputKey: scanner.iosApp.Scheme},
+			))
+		}
+	}
+
+	config, err := configBuilder.Generate(ScannerName)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -1,14 +1,17 @@
 package scanners
 
 import (
+	"context"
+
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/android"
 	"github.com/bitrise-core/bitrise-init/scanners/cordova"
 	"github.com/bitrise-core/bitrise-init/scanners/fastlane"
+	"github.com/bitrise-core/bitrise-init/scanners/ionic"
 	"github.com/bitrise-core/bitrise-init/scanners/ios"
+	"github.com/bitrise-core/bitrise-init/scanners/kmp"
 	"github.com/bitrise-core/bitrise-init/scanners/macos"
 	"github.com/bitrise-core/bitrise-init/scanners/xamarin"
-	"github.com/bitrise-core/bitrise-init/scanners/ionic"
 	"gopkg.in/yaml.v2"
 )
 
@@ -24,11 +27,12 @@ type ScannerInterface interface {
 
 	// Should implement as minimal logic as possible to determin if searchDir contains the - in question - platform or not.
 	// Inouts:
+	// - ctx: the scan is canceled, if the ctx is done; long running operations (like external commands) should respect it.
 	// - searchDir: the directory where the project to scann exists.
 	// Returns:
 	// - platform detected
 	// - error if (if any)
-	DetectPlatform(searchDir string) (bool, error)
+	DetectPlatform(ctx context.Context, searchDir string) (bool, error)
 
 	// ExcludedScannerNames is used to mark, which scanners should be excluded, if the current scanner detects platform.
 	ExcludedScannerNames() []string
@@ -37,11 +41,13 @@ type ScannerInterface interface {
 	// It defines option branches which leads different bitrise configurations.
 	// Each branch should define a complete and valid options to build the final bitrise config model.
 	// Every OptionModel branch's last options has to be the key of the workflow (in the BitriseConfigMap), which will fulfilled with the selected options.
+	// Inouts:
+	// - ctx: the scan is canceled, if the ctx is done.
 	// Returns:
 	// - OptionModel
 	// - Warnings (if any)
 	// - error if (if any)
-	Options() (models.OptionModel, models.Warnings, error)
+	Options(ctx context.Context) (models.OptionModel, models.Warnings, error)
 
 	// Returns:
 	// - default options for the platform.
@@ -58,17 +64,36 @@ type ScannerInterface interface {
 	DefaultConfigs() (models.BitriseConfigMap, error)
 }
 
-// ActiveScanners ...
-var ActiveScanners = []ScannerInterface{
-	cordova.NewScanner(),
-	ios.NewScanner(),
-	macos.NewScanner(),
-	android.NewScanner(),
-	xamarin.NewScanner(),
-	fastlane.NewScanner(),
-	ionic.NewScanner(),
+// ScanWarningsProvider is implemented by the scanners, which report machine readable warnings,
+// ScanWarnings is called after Options.
+type ScanWarningsProvider interface {
+	ScanWarnings() []models.ScanWarningModel
+}
+
+// StackRecommender is implemented by the scanners, which recommend a build stack for the detected project,
+// RecommendedStack is called after Options, nil means no recommendation.
+type StackRecommender interface {
+	RecommendedStack() *models.StackModel
+}
+
+// NewActiveScanners returns new instances of the active scanners.
+// Scanners store the state of the scan they run, use new instances for every scan.
+func NewActiveScanners() []ScannerInterface {
+	return []ScannerInterface{
+		cordova.NewScanner(),
+		kmp.NewScanner(),
+		ios.NewScanner(),
+		macos.NewScanner(),
+		android.NewScanner(),
+		xamarin.NewScanner(),
+		fastlane.NewScanner(),
+		ionic.NewScanner(),
+	}
 }
 
+// ActiveScanners ...
+var ActiveScanners = NewActiveScanners()
+
 func customConfigName() string {
 	return "other-config"
 }
@@ -0,0 +1,217 @@
+// Package scannertest is a conformance test kit for scanners.ScannerInterface implementations.
+// It runs a scanner on a fixture project and checks the rules described by the ScannerInterface.
+package scannertest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// CheckUniqueNames checks if the given scanners have unique, non empty names.
+func CheckUniqueNames(projectScanners []scanners.ScannerInterface) error {
+	nameMap := map[string]bool{}
+	for _, scanner := range projectScanners {
+		name := scanner.Name()
+		if name == "" {
+			return fmt.Errorf("scanner (%T) has empty name", scanner)
+		}
+		if nameMap[name] {
+			return fmt.Errorf("scanner name (%s) is not unique", name)
+		}
+		nameMap[name] = true
+	}
+	return nil
+}
+
+// CheckOptions checks if every branch of the option ends in a config, which exists in the configs
+// and if every config is reachable through the option branches.
+func CheckOptions(option models.OptionModel, configs models.BitriseConfigMap) error {
+	usedConfigMap := map[string]bool{}
+
+	var walk func(option *models.OptionModel, components []string) error
+	walk = func(option *models.OptionModel, components []string) error {
+		branch := strings.Join(components, " -> ")
+
+		if option.Config != "" {
+			if len(option.ChildOptionMap) > 0 {
+				return fmt.Errorf("option branch (%s) defines both config (%s) and values", branch, option.Config)
+			}
+			if _, ok := configs[option.Config]; !ok {
+				return fmt.Errorf("option branch (%s) ends in config (%s), which does not exist", branch, option.Config)
+			}
+			usedConfigMap[option.Config] = true
+			return nil
+		}
+
+		if option.EnvKey == "" {
+			return fmt.Errorf("option (%s) at branch (%s) has no env_key", option.Title, branch)
+		}
+		if len(option.ChildOptionMap) == 0 {
+			return fmt.Errorf("option (%s) at branch (%s) has no values", option.Title, branch)
+		}
+
+		values := []string{}
+		for value := range option.ChildOptionMap {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+
+		for _, value := range values {
+			childComponents := append(append([]string{}, components...), value)
+
+			child := option.ChildOptionMap[value]
+			if child == nil {
+				return fmt.Errorf("option branch (%s) does not end in a config", strings.Join(childComponents, " -> "))
+			}
+			if err := walk(child, childComponents); err != nil {
+				return err
+			}
+		}
+
+		for value := range option.ValueDescriptionMap {
+			if _, ok := option.ChildOptionMap[value]; !ok {
+				return fmt.Errorf("option (%s) at branch (%s) has description for unknown value (%s)", option.Title, branch, value)
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(&option, []string{}); err != nil {
+		return err
+	}
+
+	for name := range configs {
+		if !usedConfigMap[name] {
+			return fmt.Errorf("config (%s) is not reachable through the options", name)
+		}
+	}
+
+	return nil
+}
+
+// CheckConfig checks if the given config is a valid bitrise config.
+func CheckConfig(config string) error {
+	var bitriseData bitriseModels.BitriseDataModel
+	if err := yaml.Unmarshal([]byte(config), &bitriseData); err != nil {
+		return fmt.Errorf("failed to unmarshal config, error: %s", err)
+	}
+
+	if err := bitriseData.Normalize(); err != nil {
+		return fmt.Errorf("failed to normalize config, error: %s", err)
+	}
+
+	warnings, err := bitriseData.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid config, error: %s", err)
+	}
+	if len(warnings) > 0 {
+		return fmt.Errorf("invalid config, warnings: %s", strings.Join(warnings, ", "))
+	}
+
+	if bitriseData.ProjectType == "" {
+		return fmt.Errorf("invalid config, project_type is empty")
+	}
+	if len(bitriseData.Workflows) == 0 {
+		return fmt.Errorf("invalid config, no workflows defined")
+	}
+
+	return nil
+}
+
+// CheckConfigs checks every config of the given config map.
+func CheckConfigs(configs models.BitriseConfigMap) error {
+	for name, config := range configs {
+		if err := CheckConfig(config); err != nil {
+			return fmt.Errorf("config (%s): %s", name, err)
+		}
+	}
+	return nil
+}
+
+// Run runs the scanner on the searchDir, the way scanner.Config does,
+// and checks the rules described by the ScannerInterface, in a subtest named after the scanner.
+// The default options and configs are checked even if the scanner does not detect its platform in the searchDir.
+// Returns if the scanner detected its platform and passed the checks.
+func Run(t *testing.T, scanner scanners.ScannerInterface, searchDir string) bool {
+	return runSubtest(t, scanner, searchDir, true)
+}
+
+// RunUnsupported is the Run of a searchDir, which the scanner detects, but does not support:
+// the scanner has to fail to create the options.
+func RunUnsupported(t *testing.T, scanner scanners.ScannerInterface, searchDir string) bool {
+	return runSubtest(t, scanner, searchDir, false)
+}
+
+func runSubtest(t *testing.T, scanner scanners.ScannerInterface, searchDir string, supported bool) bool {
+	absSearchDir, err := pathutil.AbsPath(searchDir)
+	require.NoError(t, err)
+
+	passed := false
+	t.Run(scanner.Name(), func(t *testing.T) {
+		passed = run(t, scanner, absSearchDir, supported)
+	})
+	return passed
+}
+
+func run(t *testing.T, scanner scanners.ScannerInterface, absSearchDir string, supported bool) bool {
+	name := scanner.Name()
+	require.NotEmpty(t, name, "scanner (%T) has empty name", scanner)
+
+	for _, excludedName := range scanner.ExcludedScannerNames() {
+		require.NotEqual(t, name, excludedName, "scanner (%s) excludes itself", name)
+	}
+
+	defaultOptions := scanner.DefaultOptions()
+	defaultConfigs, err := scanner.DefaultConfigs()
+	require.NoError(t, err, "scanner (%s) failed to generate default configs", name)
+	require.NotEmpty(t, defaultConfigs, "scanner (%s) has no default configs", name)
+	require.NoError(t, CheckOptions(defaultOptions, defaultConfigs), "scanner (%s) default options", name)
+	require.NoError(t, CheckConfigs(defaultConfigs), "scanner (%s) default configs", name)
+
+	// scanners work with search dir relative paths, the current dir is restored at the end of the subtest
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(absSearchDir))
+	t.Cleanup(func() {
+		if err := os.Chdir(currentDir); err != nil {
+			t.Errorf("failed to restore the current dir (%s), error: %s", currentDir, err)
+		}
+	})
+
+	detected, err := scanner.DetectPlatform(context.Background(), absSearchDir)
+	require.NoError(t, err, "scanner (%s) failed to detect platform", name)
+	if !detected {
+		return false
+	}
+
+	options, _, err := scanner.Options(context.Background())
+	if !supported {
+		if err == nil {
+			t.Fatalf("scanner (%s) created options for an unsupported project", name)
+		}
+		return true
+	}
+	if err != nil {
+		t.Fatalf("scanner (%s) failed to create options, error: %s", name, err)
+		return false
+	}
+
+	configs, err := scanner.Configs()
+	require.NoError(t, err, "scanner (%s) failed to generate configs", name)
+	require.NoError(t, CheckOptions(options, configs), "scanner (%s) options", name)
+	require.NoError(t, CheckConfigs(configs), "scanner (%s) configs", name)
+
+	return true
+}
@@ -0,0 +1,154 @@
+package scannertest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/scanners/ios"
+	"github.com/stretchr/testify/require"
+)
+
+const validConfig = `format_version: "2"
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+project_type: android
+trigger_map:
+- push_branch: '*'
+  workflow: primary
+workflows:
+  primary:
+    steps:
+    - script@1.1.3: {}
+`
+
+func TestCheckUniqueNames(t *testing.T) {
+	t.Log("unique names")
+	{
+		require.NoError(t, CheckUniqueNames([]scanners.ScannerInterface{android.NewScanner(), ios.NewScanner()}))
+	}
+
+	t.Log("duplicated names")
+	{
+		err := CheckUniqueNames([]scanners.ScannerInterface{android.NewScanner(), ios.NewScanner(), android.NewScanner()})
+		require.EqualError(t, err, "scanner name (android) is not unique")
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	t.Log("every branch ends in an existing config")
+	{
+		option := models.NewOption("Gradle task", "GRADLE_TASK")
+		option.AddConfig("assembleRelease", models.NewConfigOption("android-config"))
+		option.AddConfig("assembleDebug", models.NewConfigOption("android-config"))
+
+		require.NoError(t, CheckOptions(*option, models.BitriseConfigMap{"android-config": validConfig}))
+	}
+
+	t.Log("branch ends in a missing config")
+	{
+		option := models.NewOption("Gradle task", "GRADLE_TASK")
+		option.AddConfig("assembleRelease", models.NewConfigOption("android-config"))
+
+		err := CheckOptions(*option, models.BitriseConfigMap{})
+		require.EqualError(t, err, "option branch (assembleRelease) ends in config (android-config), which does not exist")
+	}
+
+	t.Log("branch without config")
+	{
+		option := models.NewOption("Gradle file", "GRADLE_FILE")
+		option.AddOption("build.gradle", nil)
+
+		err := CheckOptions(*option, models.BitriseConfigMap{})
+		require.EqualError(t, err, "option branch (build.gradle) does not end in a config")
+	}
+
+	t.Log("unreachable config")
+	{
+		option := models.NewOption("Gradle task", "GRADLE_TASK")
+		option.AddConfig("assembleRelease", models.NewConfigOption("android-config"))
+
+		err := CheckOptions(*option, models.BitriseConfigMap{"android-config": validConfig, "other-config": validConfig})
+		require.EqualError(t, err, "config (other-config) is not reachable through the options")
+	}
+
+	t.Log("description for unknown value")
+	{
+		option := models.NewOption("Gradle task", "GRADLE_TASK")
+		option.AddConfig("assembleRelease", models.NewConfigOption("android-config"))
+		option.AddValueDescription("assembleDebug", "Debug build")
+
+		err := CheckOptions(*option, models.BitriseConfigMap{"android-config": validConfig})
+		require.EqualError(t, err, "option (Gradle task) at branch () has description for unknown value (assembleDebug)")
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	t.Log("valid config")
+	{
+		require.NoError(t, CheckConfig(validConfig))
+	}
+
+	t.Log("missing format_version")
+	{
+		err := CheckConfig(`project_type: android
+workflows:
+  primary:
+    steps:
+    - script@1.1.3: {}
+`)
+		require.EqualError(t, err, "invalid config, error: missing format_version")
+	}
+
+	t.Log("trigger map references missing workflow")
+	{
+		err := CheckConfig(`format_version: "2"
+project_type: android
+trigger_map:
+- push_branch: '*'
+  workflow: deploy
+workflows:
+  primary:
+    steps:
+    - script@1.1.3: {}
+`)
+		require.Error(t, err)
+	}
+
+	t.Log("not a yml")
+	{
+		require.Error(t, CheckConfig("workflows: ["))
+	}
+}
+
+func TestRun(t *testing.T) {
+	fixturesDir := filepath.Join("..", "..", "scanner", "testdata", "fixtures")
+
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Log("supported project")
+	{
+		require.True(t, Run(t, android.NewScanner(), filepath.Join(fixturesDir, "android-simple")))
+
+		dir, err := os.Getwd()
+		require.NoError(t, err)
+		require.Equal(t, currentDir, dir)
+	}
+
+	t.Log("unsupported project")
+	{
+		require.True(t, RunUnsupported(t, android.NewScanner(), filepath.Join(fixturesDir, "android-missing-gradlew")))
+
+		dir, err := os.Getwd()
+		require.NoError(t, err)
+		require.Equal(t, currentDir, dir)
+	}
+
+	t.Log("platform not detected")
+	{
+		require.False(t, Run(t, ios.NewScanner(), filepath.Join(fixturesDir, "android-simple")))
+	}
+}
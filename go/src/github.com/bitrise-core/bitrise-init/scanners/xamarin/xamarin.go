@@ -1,6 +1,7 @@
 package xamarin
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -21,6 +22,11 @@ const (
 	defaultConfigName = "default-xamarin-config"
 )
 
+const (
+	nugetLockfileBasePath = "packages.lock.json"
+	sourceDirEnv          = "$BITRISE_SOURCE_DIR"
+)
+
 const (
 	xamarinSolutionInputKey    = "xamarin_solution"
 	xamarinSolutionInputEnvKey = "BITRISE_PROJECT_PATH"
@@ -68,6 +74,8 @@ type Scanner struct {
 
 	HasNugetPackages     bool
 	HasXamarinComponents bool
+	// NugetLockfile is the (scanned dir relative) path of the first packages.lock.json.
+	NugetLockfile string
 
 	HasIosProject     bool
 	HasAndroidProject bool
@@ -86,7 +94,7 @@ func (scanner Scanner) Name() string {
 }
 
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
+func (scanner *Scanner) DetectPlatform(ctx context.Context, searchDir string) (bool, error) {
 	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
@@ -124,7 +132,7 @@ func (scanner *Scanner) ExcludedScannerNames() []string {
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Warnings, error) {
 	log.Infoft("Searching for NuGet packages & Xamarin Components")
 
 	warnings := models.Warnings{}
@@ -138,6 +146,11 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 			}
 		}
 
+		// Search for nuget lock file
+		if scanner.NugetLockfile == "" && filepath.Base(file) == nugetLockfileBasePath {
+			scanner.NugetLockfile = file
+		}
+
 		// If adding a component:
 		// /Components/[COMPONENT_NAME]/ dir added
 		// ItemGroup/XamarinComponentReference added to the project
@@ -149,7 +162,7 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 			}
 		}
 
-		if scanner.HasNugetPackages && scanner.HasXamarinComponents {
+		if scanner.HasNugetPackages && scanner.HasXamarinComponents && scanner.NugetLockfile != "" {
 			break
 		}
 	}
@@ -260,6 +273,14 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		configBuilder.AppendDependencyStepList(steps.NugetRestoreStepListItem())
 	}
 
+	if scanner.HasNugetPackages || scanner.NugetLockfile != "" {
+		lockfile := ""
+		if scanner.NugetLockfile != "" {
+			lockfile = sourceDirEnv + "/" + filepath.ToSlash(scanner.NugetLockfile)
+		}
+		configBuilder.AddCachePaths(utility.NuGetCachePaths(lockfile)...)
+	}
+
 	// XamarinComponentsRestore
 	if scanner.HasXamarinComponents {
 		configBuilder.AppendDependencyStepList(steps.XamarinComponentsRestoreStepListItem())
@@ -296,6 +317,7 @@ func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
 
 	configBuilder.AppendDependencyStepList(steps.NugetRestoreStepListItem())
 	configBuilder.AppendDependencyStepList(steps.XamarinComponentsRestoreStepListItem())
+	configBuilder.AddCachePaths(utility.NuGetCachePaths("")...)
 
 	configBuilder.AppendMainStepList(steps.XamarinArchiveStepListItem(
 		envmanModels.EnvironmentItemModel{xamarinSolutionInputKey: "$" + xamarinSolutionInputEnvKey},
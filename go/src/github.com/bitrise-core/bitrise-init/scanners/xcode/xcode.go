@@ -1,6 +1,7 @@
 package xcode
 
 import (
+	"context"
 	"fmt"
 
 	"gopkg.in/yaml.v2"
@@ -13,6 +14,7 @@ import (
 	envmanModels "github.com/bitrise-io/envman/models"
 	"github.com/bitrise-io/go-utils/log"
 	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/sliceutil"
 	"github.com/bitrise-tools/go-xcode/xcodeproj"
 )
 
@@ -21,6 +23,8 @@ const (
 	configNameFormat        = "%s%s-config"
 )
 
+const sourceDirEnv = "$BITRISE_SOURCE_DIR"
+
 const (
 	// ProjectPathInputKey ...
 	ProjectPathInputKey = "project_path"
@@ -52,6 +56,8 @@ type ConfigDescriptor struct {
 	CarthageCommand      string
 	HasTest              bool
 	MissingSharedSchemes bool
+	// DependencyDir is the (scanned dir relative) directory of the Podfile and the Cartfile, it is empty for the scanned dir.
+	DependencyDir string
 }
 
 // NewConfigDescriptor ...
@@ -154,6 +160,18 @@ Make sure to <a href="http://devcenter.bitrise.io/ios/frequent-ios-issues/#xcode
 	return message
 }
 
+// detectDependencyDir returns the (scanned dir relative) directory of the project's Podfile and Cartfile,
+// it is empty if the project has no dependencies or they are placed in the scanned dir.
+func detectDependencyDir(projectPth string, hasPodfile bool, carthageCommand string) string {
+	if !hasPodfile && carthageCommand == "" {
+		return ""
+	}
+	if dir := filepath.Dir(projectPth); dir != "." {
+		return dir
+	}
+	return ""
+}
+
 func detectCarthageCommand(projectPth string) (string, string) {
 	carthageCommand := ""
 	warning := ""
@@ -176,7 +194,7 @@ It is <a href="https://github.com/Carthage/Carthage/blob/master/Documentation/Ar
 }
 
 // GenerateOptions ...
-func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (models.OptionModel, []ConfigDescriptor, models.Warnings, error) {
+func GenerateOptions(ctx context.Context, projectType utility.XcodeProjectType, searchDir string) (models.OptionModel, []ConfigDescriptor, models.Warnings, error) {
 	warnings := models.Warnings{}
 
 	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
@@ -213,7 +231,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 	for _, podfile := range podfiles {
 		log.Printft("- %s", podfile)
 
-		workspaceProjectMap, err := utility.GetWorkspaceProjectMap(podfile, projectFiles)
+		workspaceProjectMap, err := utility.GetWorkspaceProjectMap(ctx, podfile, projectFiles)
 		if err != nil {
 			return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
 		}
@@ -255,6 +273,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 		if warning != "" {
 			warnings = append(warnings, warning)
 		}
+		dependencyDir := detectDependencyDir(project.Pth, false, carthageCommand)
 
 		log.Printft("%d shared schemes detected", len(project.SharedSchemes))
 
@@ -266,6 +285,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 
 			for _, target := range project.Targets {
 				configDescriptor := NewConfigDescriptor(false, carthageCommand, target.HasXCTest, true)
+				configDescriptor.DependencyDir = dependencyDir
 				configDescriptors = append(configDescriptors, configDescriptor)
 
 				configOption := models.NewConfigOption(configDescriptor.ConfigName(projectType))
@@ -276,6 +296,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 				log.Printft("- %s", scheme.Name)
 
 				configDescriptor := NewConfigDescriptor(false, carthageCommand, scheme.HasXCTest, false)
+				configDescriptor.DependencyDir = dependencyDir
 				configDescriptors = append(configDescriptors, configDescriptor)
 
 				configOption := models.NewConfigOption(configDescriptor.ConfigName(projectType))
@@ -295,6 +316,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 		if warning != "" {
 			warnings = append(warnings, warning)
 		}
+		dependencyDir := detectDependencyDir(workspace.Pth, workspace.IsPodWorkspace, carthageCommand)
 
 		sharedSchemes := workspace.GetSharedSchemes()
 		log.Printft("%d shared schemes detected", len(sharedSchemes))
@@ -309,6 +331,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 
 			for _, target := range targets {
 				configDescriptor := NewConfigDescriptor(workspace.IsPodWorkspace, carthageCommand, target.HasXCTest, true)
+				configDescriptor.DependencyDir = dependencyDir
 				configDescriptors = append(configDescriptors, configDescriptor)
 
 				configOption := models.NewConfigOption(configDescriptor.ConfigName(projectType))
@@ -319,6 +342,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 				log.Printft("- %s", scheme.Name)
 
 				configDescriptor := NewConfigDescriptor(workspace.IsPodWorkspace, carthageCommand, scheme.HasXCTest, false)
+				configDescriptor.DependencyDir = dependencyDir
 				configDescriptors = append(configDescriptors, configDescriptor)
 
 				configOption := models.NewConfigOption(configDescriptor.ConfigName(projectType))
@@ -351,9 +375,28 @@ func GenerateDefaultOptions(projectType utility.XcodeProjectType) models.OptionM
 }
 
 // GenerateConfigBuilder ...
-func GenerateConfigBuilder(projectType utility.XcodeProjectType, hasPodfile, hasTest, missingSharedSchemes bool, carthageCommand string) models.ConfigBuilderModel {
+// The dependencyDirs are the directories of the Podfiles and the Cartfiles to cache, the scanned dir if not provided.
+func GenerateConfigBuilder(projectType utility.XcodeProjectType, hasPodfile, hasTest, missingSharedSchemes bool, carthageCommand string, dependencyDirs ...string) models.ConfigBuilderModel {
 	configBuilder := models.NewDefaultConfigBuilder()
 
+	if len(dependencyDirs) == 0 {
+		dependencyDirs = []string{""}
+	}
+	cachePaths := []string{}
+	for _, dependencyDir := range dependencyDirs {
+		dependencySourceDir := sourceDirEnv
+		if dependencyDir != "" {
+			dependencySourceDir += "/" + filepath.ToSlash(dependencyDir)
+		}
+		if hasPodfile {
+			cachePaths = append(cachePaths, utility.CocoaPodsCachePaths(dependencySourceDir)...)
+		}
+		if carthageCommand != "" {
+			cachePaths = append(cachePaths, utility.CarthageCachePaths(dependencySourceDir)...)
+		}
+	}
+	configBuilder.AddCachePaths(cachePaths...)
+
 	// CI
 	configBuilder.AppendPreparStepList(steps.CertificateAndProfileInstallerStepListItem())
 
@@ -389,6 +432,7 @@ func GenerateConfigBuilder(projectType utility.XcodeProjectType, hasPodfile, has
 
 	// CD
 	configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+	configBuilder.AddCachePathsTo(models.DeployWorkflowID, cachePaths...)
 
 	configBuilder.AppendPreparStepListTo(models.DeployWorkflowID, steps.CertificateAndProfileInstallerStepListItem())
 
@@ -441,9 +485,18 @@ func plain(configDescriptors []ConfigDescriptor, projectType utility.XcodeProjec
 
 // GenerateConfig ...
 func GenerateConfig(projectType utility.XcodeProjectType, configDescriptors []ConfigDescriptor) (models.BitriseConfigMap, error) {
+	// projects in different dirs may share a config, which caches the dependencies of all of them
+	dependencyDirsByName := map[string][]string{}
+	for _, descriptor := range configDescriptors {
+		name := descriptor.ConfigName(projectType)
+		if !sliceutil.IsStringInSlice(descriptor.DependencyDir, dependencyDirsByName[name]) {
+			dependencyDirsByName[name] = append(dependencyDirsByName[name], descriptor.DependencyDir)
+		}
+	}
+
 	bitriseDataMap := models.BitriseConfigMap{}
 	for _, descriptor := range configDescriptors {
-		configBuilder := GenerateConfigBuilder(projectType, descriptor.HasPodfile, descriptor.HasTest, descriptor.MissingSharedSchemes, descriptor.CarthageCommand)
+		configBuilder := GenerateConfigBuilder(projectType, descriptor.HasPodfile, descriptor.HasTest, descriptor.MissingSharedSchemes, descriptor.CarthageCommand, dependencyDirsByName[descriptor.ConfigName(projectType)]...)
 
 		config, err := configBuilder.Generate(string(projectType))
 		if err != nil {
@@ -472,6 +525,7 @@ func GenerateDefaultConfig(projectType utility.XcodeProjectType) (models.Bitrise
 	))
 
 	configBuilder.AppendDependencyStepList(steps.CocoapodsInstallStepListItem())
+	configBuilder.AddCachePaths(utility.CocoaPodsCachePaths(sourceDirEnv)...)
 
 	xcodeTestAndArchiveStepInputModels := []envmanModels.EnvironmentItemModel{
 		envmanModels.EnvironmentItemModel{ProjectPathInputKey: "$" + ProjectPathInputEnvKey},
@@ -493,7 +547,8 @@ func GenerateDefaultConfig(projectType utility.XcodeProjectType) (models.Bitrise
 		envmanModels.EnvironmentItemModel{ProjectPathInputKey: "$" + ProjectPathInputEnvKey},
 	))
 
-	configBuilder.AppendPreparStepListTo(models.DeployWorkflowID, steps.CocoapodsInstallStepListItem())
+	configBuilder.AppendDependencyStepListTo(models.DeployWorkflowID, steps.CocoapodsInstallStepListItem())
+	configBuilder.AddCachePathsTo(models.DeployWorkflowID, utility.CocoaPodsCachePaths(sourceDirEnv)...)
 
 	switch projectType {
 	case utility.XcodeProjectTypeIOS:
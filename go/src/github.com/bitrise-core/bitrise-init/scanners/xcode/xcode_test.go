@@ -1,6 +1,7 @@
 package xcode
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/bitrise-core/bitrise-init/utility"
@@ -55,4 +56,29 @@ func TestConfigName(t *testing.T) {
 		descriptor := NewConfigDescriptor(true, "bootstrap", true, true)
 		require.Equal(t, "ios-pod-carthage-test-missing-shared-schemes-config", descriptor.ConfigName(utility.XcodeProjectTypeIOS))
 	}
+
+	{
+		descriptor := NewConfigDescriptor(true, "", false, false)
+		descriptor.DependencyDir = "mobile/ios"
+		require.Equal(t, "ios-pod-config", descriptor.ConfigName(utility.XcodeProjectTypeIOS))
+	}
+}
+
+func TestDetectDependencyDir(t *testing.T) {
+	require.Equal(t, "", detectDependencyDir("ios/Sample.xcodeproj", false, ""))
+	require.Equal(t, "", detectDependencyDir("Sample.xcworkspace", true, ""))
+	require.Equal(t, "ios", detectDependencyDir("ios/Sample.xcworkspace", true, ""))
+	require.Equal(t, "ios", detectDependencyDir("ios/Sample.xcodeproj", false, "bootstrap"))
+}
+
+func TestGenerateConfigSharedByDependencyDirs(t *testing.T) {
+	rootDescriptor := NewConfigDescriptor(true, "", false, false)
+	nestedDescriptor := NewConfigDescriptor(true, "", false, false)
+	nestedDescriptor.DependencyDir = "mobile/ios"
+
+	configs, err := GenerateConfig(utility.XcodeProjectTypeIOS, []ConfigDescriptor{rootDescriptor, nestedDescriptor})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(configs))
+	require.True(t, strings.Contains(configs["ios-pod-config"], "$BITRISE_SOURCE_DIR/Pods -> $BITRISE_SOURCE_DIR/Podfile.lock"), configs["ios-pod-config"])
+	require.True(t, strings.Contains(configs["ios-pod-config"], "$BITRISE_SOURCE_DIR/mobile/ios/Pods -> $BITRISE_SOURCE_DIR/mobile/ios/Podfile.lock"), configs["ios-pod-config"])
 }
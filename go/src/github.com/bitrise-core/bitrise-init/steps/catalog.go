@@ -0,0 +1,59 @@
+package steps
+
+// Versions maps the ids of the steps used in the generated configs to the used versions.
+var Versions = map[string]string{
+	ActivateSSHKeyID:                 ActivateSSHKeyVersion,
+	ChangeWorkDirID:                  ChangeWorkDirVersion,
+	GitCloneID:                       GitCloneVersion,
+	CertificateAndProfileInstallerID: CertificateAndProfileInstallerVersion,
+	DeployToBitriseIoID:              DeployToBitriseIoVersion,
+	ScriptID:                         ScriptVersion,
+	SetJavaVersionID:                 SetJavaVersionVersion,
+	InstallMissingAndroidToolsID:     InstallMissingAndroidToolsVersion,
+	GradleRunnerID:                   GradleRunnerVersion,
+	SignAPKID:                        SignAPKVersion,
+	AndroidUnitTestID:                AndroidUnitTestVersion,
+	AndroidLintID:                    AndroidLintVersion,
+	AndroidBuildForUITestingID:       AndroidBuildForUITestingVersion,
+	VirtualDeviceTestingForAndroidID: VirtualDeviceTestingForAndroidVersion,
+	FastlaneID:                       FastlaneVersion,
+	CocoapodsInstallID:               CocoapodsInstallVersion,
+	CarthageID:                       CarthageVersion,
+	RecreateUserSchemesID:            RecreateUserSchemesVersion,
+	XcodeArchiveID:                   XcodeArchiveVersion,
+	XcodeTestID:                      XcodeTestVersion,
+	XamarinUserManagementID:          XamarinUserManagementVersion,
+	NugetRestoreID:                   NugetRestoreVersion,
+	XamarinComponentsRestoreID:       XamarinComponentsRestoreVersion,
+	XamarinArchiveID:                 XamarinArchiveVersion,
+	XcodeArchiveMacID:                XcodeArchiveMacVersion,
+	XcodeTestMacID:                   XcodeTestMacVersion,
+	GenerateGradleWrapperID:          GenerateGradleWrapperVersion,
+	CordovaArchiveID:                 CordovaArchiveVersion,
+	GenerateCordovaBuildConfigID:     GenerateCordovaBuildConfigVersion,
+	JasmineTestRunnerID:              JasmineTestRunnerVersion,
+	KarmaJasmineTestRunnerID:         KarmaJasmineTestRunnerVersion,
+	IonicBuildID:                     IonicBuildVersion,
+	NpmID:                            NpmVersion,
+	YarnID:                           YarnVersion,
+	CachePullID:                      CachePullVersion,
+	CachePushID:                      CachePushVersion,
+}
+
+// InputRenameModel describes a step input renamed in the given version of the step.
+type InputRenameModel struct {
+	StepID  string
+	Version string
+	From    string
+	To      string
+}
+
+// InputRenames lists the inputs of the steps (in Versions) renamed between the step versions,
+// a config pinning an older version of the step needs the inputs renamed, when the step is upgraded to (or over) the rename's version.
+var InputRenames = []InputRenameModel{
+	// the App Bundle support of gradle-runner generalized the APK filters
+	{StepID: GradleRunnerID, Version: "1.9.0", From: "apk_file_include_filter", To: "app_file_include_filter"},
+	{StepID: GradleRunnerID, Version: "1.9.0", From: "apk_file_exclude_filter", To: "app_file_exclude_filter"},
+	// the App Bundle support of sign-apk generalized the APK path
+	{StepID: SignAPKID, Version: "1.3.0", From: "apk_path", To: "android_app"},
+}
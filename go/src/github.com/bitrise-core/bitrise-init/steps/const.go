@@ -47,6 +47,13 @@ const (
 	ScriptDefaultTitle = "Do anything with Script step"
 )
 
+const (
+	// SetJavaVersionID ...
+	SetJavaVersionID = "set-java-version"
+	// SetJavaVersionVersion ...
+	SetJavaVersionVersion = "1.1.0"
+)
+
 const (
 	// InstallMissingAndroidToolsID ...
 	InstallMissingAndroidToolsID = "install-missing-android-tools"
@@ -58,7 +65,42 @@ const (
 	// GradleRunnerID ...
 	GradleRunnerID = "gradle-runner"
 	// GradleRunnerVersion ...
-	GradleRunnerVersion = "1.5.6"
+	GradleRunnerVersion = "1.9.0"
+)
+
+const (
+	// SignAPKID ...
+	SignAPKID = "sign-apk"
+	// SignAPKVersion ...
+	SignAPKVersion = "1.3.0"
+)
+
+const (
+	// AndroidUnitTestID ...
+	AndroidUnitTestID = "android-unit-test"
+	// AndroidUnitTestVersion ...
+	AndroidUnitTestVersion = "0.9.3"
+)
+
+const (
+	// AndroidLintID ...
+	AndroidLintID = "android-lint"
+	// AndroidLintVersion ...
+	AndroidLintVersion = "0.9.4"
+)
+
+const (
+	// AndroidBuildForUITestingID ...
+	AndroidBuildForUITestingID = "android-build-for-ui-testing"
+	// AndroidBuildForUITestingVersion ...
+	AndroidBuildForUITestingVersion = "0.1.3"
+)
+
+const (
+	// VirtualDeviceTestingForAndroidID ...
+	VirtualDeviceTestingForAndroidID = "virtual-device-testing-for-android"
+	// VirtualDeviceTestingForAndroidVersion ...
+	VirtualDeviceTestingForAndroidVersion = "1.0.4"
 )
 
 const (
@@ -186,3 +228,31 @@ const (
 	// IonicBuildVersion ...
 	IonicBuildVersion = "1.0.0"
 )
+
+const (
+	// NpmID ...
+	NpmID = "npm"
+	// NpmVersion ...
+	NpmVersion = "0.9.0"
+)
+
+const (
+	// YarnID ...
+	YarnID = "yarn"
+	// YarnVersion ...
+	YarnVersion = "0.0.8"
+)
+
+const (
+	// CachePullID ...
+	CachePullID = "cache-pull"
+	// CachePullVersion ...
+	CachePullVersion = "2.0.1"
+)
+
+const (
+	// CachePushID ...
+	CachePushID = "cache-push"
+	// CachePushVersion ...
+	CachePushVersion = "2.0.5"
+)
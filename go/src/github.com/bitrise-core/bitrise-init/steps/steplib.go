@@ -0,0 +1,274 @@
+package steps
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	stepmanModels "github.com/bitrise-io/stepman/models"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// VersionPolicy defines how the step versions are resolved from the StepLib.
+type VersionPolicy string
+
+const (
+	// VersionPolicyPinned uses the versions set in const.go.
+	VersionPolicyPinned VersionPolicy = "pinned"
+	// VersionPolicyLatestMinor uses the latest version of the StepLib with the same major version, as the one set in const.go.
+	VersionPolicyLatestMinor VersionPolicy = "latest-minor"
+)
+
+// ParseVersionPolicy ...
+func ParseVersionPolicy(policy string) (VersionPolicy, error) {
+	switch VersionPolicy(policy) {
+	case "", VersionPolicyPinned:
+		return VersionPolicyPinned, nil
+	case VersionPolicyLatestMinor:
+		return VersionPolicyLatestMinor, nil
+	}
+	return "", fmt.Errorf("not a valid version policy: %s, options: [%s, %s]", policy, VersionPolicyPinned, VersionPolicyLatestMinor)
+}
+
+// StepLibModel is an offline copy of the StepLib, holding the versions of the steps used in the generated configs.
+type StepLibModel struct {
+	// Steps maps the step ids to the step versions.
+	Steps map[string]map[string]stepmanModels.StepModel
+}
+
+// OpenStepLib loads the known steps (listed in Versions) from a StepLib spec (spec.json) or from a cloned StepLib dir.
+func OpenStepLib(pth string) (StepLibModel, error) {
+	if isDir, err := pathutil.IsDirExists(pth); err != nil {
+		return StepLibModel{}, err
+	} else if isDir {
+		return NewStepLibFromDir(pth)
+	}
+	return NewStepLibFromSpec(pth)
+}
+
+// NewStepLibFromSpec loads the known steps from the StepLib spec (spec.json) generated by stepman.
+func NewStepLibFromSpec(pth string) (StepLibModel, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return StepLibModel{}, err
+	}
+
+	var spec stepmanModels.StepCollectionModel
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return StepLibModel{}, fmt.Errorf("failed to parse steplib spec (%s), error: %s", pth, err)
+	}
+
+	stepLib := StepLibModel{Steps: map[string]map[string]stepmanModels.StepModel{}}
+	for stepID := range Versions {
+		if stepGroup, ok := spec.Steps[stepID]; ok {
+			stepLib.Steps[stepID] = stepGroup.Versions
+		}
+	}
+
+	return stepLib, nil
+}
+
+// NewStepLibFromDir loads the known steps from a cloned StepLib dir, the step definitions are stored at: steps/<step id>/<version>/step.yml.
+func NewStepLibFromDir(dir string) (StepLibModel, error) {
+	if exist, err := pathutil.IsDirExists(filepath.Join(dir, "steps")); err != nil {
+		return StepLibModel{}, err
+	} else if !exist {
+		return StepLibModel{}, fmt.Errorf("not a steplib dir, steps dir not found in: %s", dir)
+	}
+
+	stepLib := StepLibModel{Steps: map[string]map[string]stepmanModels.StepModel{}}
+	for stepID := range Versions {
+		stepYMLPths, err := filepath.Glob(filepath.Join(dir, "steps", stepID, "*", "step.yml"))
+		if err != nil {
+			return StepLibModel{}, err
+		}
+
+		for _, stepYMLPth := range stepYMLPths {
+			content, err := fileutil.ReadBytesFromFile(stepYMLPth)
+			if err != nil {
+				return StepLibModel{}, err
+			}
+
+			var step stepmanModels.StepModel
+			if err := yaml.Unmarshal(content, &step); err != nil {
+				return StepLibModel{}, fmt.Errorf("failed to parse step.yml (%s), error: %s", stepYMLPth, err)
+			}
+
+			if stepLib.Steps[stepID] == nil {
+				stepLib.Steps[stepID] = map[string]stepmanModels.StepModel{}
+			}
+			stepLib.Steps[stepID][filepath.Base(filepath.Dir(stepYMLPth))] = step
+		}
+	}
+
+	return stepLib, nil
+}
+
+// LatestVersion returns the latest version of the step.
+func (stepLib StepLibModel) LatestVersion(stepID string) (string, bool) {
+	versions := stepLib.sortedVersions(stepID)
+	if len(versions) == 0 {
+		return "", false
+	}
+	return versions[len(versions)-1], true
+}
+
+// ResolveVersion returns the version of the step to use, based on the policy.
+func (stepLib StepLibModel) ResolveVersion(stepID, version string, policy VersionPolicy) string {
+	if policy != VersionPolicyLatestMinor || version == "" {
+		return version
+	}
+
+	major := strings.Split(version, ".")[0]
+	resolved := version
+	for _, v := range stepLib.sortedVersions(stepID) {
+		if strings.Split(v, ".")[0] == major && CompareVersions(v, resolved) > 0 {
+			resolved = v
+		}
+	}
+	return resolved
+}
+
+// ValidateInputs checks if the given version of the step exists and has the given inputs.
+func (stepLib StepLibModel) ValidateInputs(stepID, version string, inputs []envmanModels.EnvironmentItemModel) error {
+	stepVersions, ok := stepLib.Steps[stepID]
+	if !ok {
+		return fmt.Errorf("step (%s) not found in the steplib", stepID)
+	}
+
+	if version == "" {
+		latest, ok := stepLib.LatestVersion(stepID)
+		if !ok {
+			return fmt.Errorf("step (%s) has no versions in the steplib", stepID)
+		}
+		version = latest
+	}
+
+	step, ok := stepVersions[version]
+	if !ok {
+		return fmt.Errorf("step (%s@%s) not found in the steplib", stepID, version)
+	}
+
+	knownInputs := map[string]bool{}
+	for _, input := range step.Inputs {
+		key, _, err := input.GetKeyValuePair()
+		if err != nil {
+			return fmt.Errorf("step (%s@%s) has an invalid input in the steplib, error: %s", stepID, version, err)
+		}
+		knownInputs[key] = true
+	}
+
+	for _, input := range inputs {
+		key, _, err := input.GetKeyValuePair()
+		if err != nil {
+			return err
+		}
+		if !knownInputs[key] {
+			return fmt.Errorf("step (%s@%s) has no input: %s", stepID, version, key)
+		}
+	}
+
+	return nil
+}
+
+func (stepLib StepLibModel) sortedVersions(stepID string) []string {
+	versions := []string{}
+	for version := range stepLib.Steps[stepID] {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// defaultStepLibSourceAlias stands for the default steplib source, when parsing the step ids without source.
+const defaultStepLibSourceAlias = "_"
+
+var (
+	stepLib       *StepLibModel
+	versionPolicy = VersionPolicyPinned
+)
+
+// SetStepLib sets the StepLib used to resolve the versions of the generated steps (see: VersionPolicy) and to validate their inputs,
+// the versions of const.go are used without validation if the stepLib is nil.
+func SetStepLib(lib *StepLibModel, policy VersionPolicy) {
+	stepLib = lib
+	versionPolicy = policy
+}
+
+// ValidateStepList checks the steps and their inputs against the StepLib set by SetStepLib.
+// Only the steps used by the scanners (see: Versions) are validated, the steps referenced by their git url
+// or local path, and the other steps (like the steps added by a config template) are skipped.
+// A used step missing from the StepLib is an error.
+func ValidateStepList(stepList []bitriseModels.StepListItemModel) error {
+	if stepLib == nil {
+		return nil
+	}
+
+	for _, stepListItem := range stepList {
+		compositeID, step, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+		if err != nil {
+			return err
+		}
+
+		stepIDData, err := bitriseModels.CreateStepIDDataFromString(compositeID, defaultStepLibSourceAlias)
+		if err != nil {
+			return err
+		}
+
+		if stepIDData.SteplibSource != defaultStepLibSourceAlias {
+			// git::, path:: or an explicit steplib source
+			continue
+		}
+		if _, ok := Versions[stepIDData.IDorURI]; !ok {
+			continue
+		}
+
+		if err := stepLib.ValidateInputs(stepIDData.IDorURI, stepIDData.Version, step.Inputs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CompareVersions compares the dot separated numeric versions,
+// returns a negative number if a < b, 0 if a == b and a positive number if a > b.
+// Not numeric components are compared as strings.
+func CompareVersions(a, b string) int {
+	aComponents := strings.Split(a, ".")
+	bComponents := strings.Split(b, ".")
+
+	for i := 0; i < len(aComponents) || i < len(bComponents); i++ {
+		aComponent, bComponent := "0", "0"
+		if i < len(aComponents) {
+			aComponent = aComponents[i]
+		}
+		if i < len(bComponents) {
+			bComponent = bComponents[i]
+		}
+
+		aNum, aErr := strconv.Atoi(aComponent)
+		bNum, bErr := strconv.Atoi(bComponent)
+		if aErr == nil && bErr == nil {
+			if aNum != bNum {
+				return aNum - bNum
+			}
+			continue
+		}
+
+		if c := strings.Compare(aComponent, bComponent); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
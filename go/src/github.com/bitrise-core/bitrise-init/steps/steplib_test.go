@@ -0,0 +1,195 @@
+package steps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	stepmanModels "github.com/bitrise-io/stepman/models"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `{
+  "format_version": "1.0.0",
+  "steps": {
+    "git-clone": {
+      "latest_version_number": "4.1.0",
+      "versions": {
+        "3.6.0": {"inputs": [{"clone_into_dir": "$BITRISE_SOURCE_DIR"}]},
+        "3.10.2": {"inputs": [{"clone_into_dir": "$BITRISE_SOURCE_DIR"}, {"clone_depth": "", "opts": {"is_required": false}}]},
+        "4.1.0": {"inputs": [{"clone_into_dir": "$BITRISE_SOURCE_DIR"}]}
+      }
+    },
+    "unknown-step": {"latest_version_number": "1.0.0", "versions": {"1.0.0": {}}}
+  }
+}`
+
+const stepYML = `title: Script
+inputs:
+- content: ""
+  opts:
+    is_required: true
+- runner_bin: /bin/bash
+`
+
+func TestOpenStepLib(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("steplib")
+	require.NoError(t, err)
+
+	t.Log("spec")
+	{
+		pth := filepath.Join(tmpDir, "spec.json")
+		require.NoError(t, fileutil.WriteStringToFile(pth, spec))
+
+		stepLib, err := OpenStepLib(pth)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stepLib.Steps))
+		require.Equal(t, 3, len(stepLib.Steps[GitCloneID]))
+
+		latest, ok := stepLib.LatestVersion(GitCloneID)
+		require.True(t, ok)
+		require.Equal(t, "4.1.0", latest)
+	}
+
+	t.Log("cloned steplib dir")
+	{
+		dir := filepath.Join(tmpDir, "steplib")
+		for _, version := range []string{"1.1.5", "1.1.10"} {
+			versionDir := filepath.Join(dir, "steps", ScriptID, version)
+			require.NoError(t, os.MkdirAll(versionDir, 0700))
+			require.NoError(t, fileutil.WriteStringToFile(filepath.Join(versionDir, "step.yml"), stepYML))
+		}
+
+		stepLib, err := OpenStepLib(dir)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(stepLib.Steps[ScriptID]))
+
+		latest, ok := stepLib.LatestVersion(ScriptID)
+		require.True(t, ok)
+		require.Equal(t, "1.1.10", latest)
+
+		require.NoError(t, stepLib.ValidateInputs(ScriptID, "1.1.5", []envmanModels.EnvironmentItemModel{{"content": "echo"}}))
+	}
+
+	t.Log("not a steplib dir")
+	{
+		_, err := OpenStepLib(tmpDir)
+		require.Error(t, err)
+	}
+
+	t.Log("missing spec")
+	{
+		_, err := OpenStepLib(filepath.Join(tmpDir, "missing.json"))
+		require.Error(t, err)
+	}
+}
+
+func openSpec(t *testing.T) StepLibModel {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("steplib")
+	require.NoError(t, err)
+
+	pth := filepath.Join(tmpDir, "spec.json")
+	require.NoError(t, fileutil.WriteStringToFile(pth, spec))
+
+	stepLib, err := NewStepLibFromSpec(pth)
+	require.NoError(t, err)
+	return stepLib
+}
+
+func TestResolveVersion(t *testing.T) {
+	stepLib := openSpec(t)
+
+	t.Log("pinned")
+	{
+		require.Equal(t, "3.6.0", stepLib.ResolveVersion(GitCloneID, "3.6.0", VersionPolicyPinned))
+	}
+
+	t.Log("latest-minor")
+	{
+		require.Equal(t, "3.10.2", stepLib.ResolveVersion(GitCloneID, "3.6.0", VersionPolicyLatestMinor))
+		require.Equal(t, "4.1.0", stepLib.ResolveVersion(GitCloneID, "4.0.0", VersionPolicyLatestMinor))
+	}
+
+	t.Log("latest-minor does not downgrade")
+	{
+		require.Equal(t, "4.2.0", stepLib.ResolveVersion(GitCloneID, "4.2.0", VersionPolicyLatestMinor))
+	}
+
+	t.Log("latest-minor of an unknown step")
+	{
+		require.Equal(t, "1.0.0", stepLib.ResolveVersion("unknown-step", "1.0.0", VersionPolicyLatestMinor))
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	stepLib := openSpec(t)
+
+	t.Log("known inputs")
+	{
+		require.NoError(t, stepLib.ValidateInputs(GitCloneID, "3.10.2", []envmanModels.EnvironmentItemModel{
+			{"clone_into_dir": "."},
+			{"clone_depth": "1", "opts": map[string]interface{}{"is_expand": false}},
+		}))
+	}
+
+	t.Log("unknown input")
+	{
+		err := stepLib.ValidateInputs(GitCloneID, "4.1.0", []envmanModels.EnvironmentItemModel{{"clone_depth": "1"}})
+		require.EqualError(t, err, "step (git-clone@4.1.0) has no input: clone_depth")
+	}
+
+	t.Log("unknown version")
+	{
+		err := stepLib.ValidateInputs(GitCloneID, "1.0.0", nil)
+		require.EqualError(t, err, "step (git-clone@1.0.0) not found in the steplib")
+	}
+
+	t.Log("unknown step")
+	{
+		err := stepLib.ValidateInputs(ScriptID, "1.1.5", nil)
+		require.EqualError(t, err, "step (script) not found in the steplib")
+	}
+}
+
+func TestSetStepLib(t *testing.T) {
+	stepLib := openSpec(t)
+	SetStepLib(&stepLib, VersionPolicyLatestMinor)
+	defer SetStepLib(nil, VersionPolicyPinned)
+
+	require.Equal(t, GitCloneID+"@3.10.2", stepIDComposite(GitCloneID, "3.6.0"))
+
+	require.NoError(t, ValidateStepList([]bitriseModels.StepListItemModel{
+		{stepIDComposite(GitCloneID, "3.6.0"): stepmanModels.StepModel{Inputs: []envmanModels.EnvironmentItemModel{{"clone_depth": "1"}}}},
+	}))
+
+	err := ValidateStepList([]bitriseModels.StepListItemModel{
+		{stepIDComposite(GitCloneID, "4.0.0"): stepmanModels.StepModel{Inputs: []envmanModels.EnvironmentItemModel{{"clone_depth": "1"}}}},
+	})
+	require.EqualError(t, err, "step (git-clone@4.1.0) has no input: clone_depth")
+
+	t.Log("used step missing from the steplib")
+	{
+		err := ValidateStepList([]bitriseModels.StepListItemModel{
+			{stepIDComposite(ScriptID, ScriptVersion): stepmanModels.StepModel{}},
+		})
+		require.EqualError(t, err, "step (script) not found in the steplib")
+	}
+
+	t.Log("step not used by the scanners")
+	{
+		require.NoError(t, ValidateStepList([]bitriseModels.StepListItemModel{
+			{"custom-step@1.0.0": stepmanModels.StepModel{}},
+			{"git::https://github.com/bitrise-steplib/steps-script.git@master": stepmanModels.StepModel{}},
+		}))
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	require.True(t, CompareVersions("1.10.0", "1.9.0") > 0)
+	require.True(t, CompareVersions("1.2", "1.2.0") == 0)
+	require.True(t, CompareVersions("2.0.0", "10.0.0") < 0)
+}
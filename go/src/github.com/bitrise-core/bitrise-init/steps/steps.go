@@ -8,6 +8,9 @@ import (
 )
 
 func stepIDComposite(ID, version string) string {
+	if stepLib != nil {
+		version = stepLib.ResolveVersion(ID, version, versionPolicy)
+	}
 	if version != "" {
 		return ID + "@" + version
 	}
@@ -74,9 +77,9 @@ func CertificateAndProfileInstallerStepListItem() bitriseModels.StepListItemMode
 }
 
 // DeployToBitriseIoStepListItem ...
-func DeployToBitriseIoStepListItem() bitriseModels.StepListItemModel {
+func DeployToBitriseIoStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
 	stepIDComposite := stepIDComposite(DeployToBitriseIoID, DeployToBitriseIoVersion)
-	return stepListItem(stepIDComposite, "", "")
+	return stepListItem(stepIDComposite, "", "", inputs...)
 }
 
 // ScriptSteplistItem ...
@@ -85,6 +88,12 @@ func ScriptSteplistItem(title string, inputs ...envmanModels.EnvironmentItemMode
 	return stepListItem(stepIDComposite, title, "", inputs...)
 }
 
+// SetJavaVersionStepListItem ...
+func SetJavaVersionStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(SetJavaVersionID, SetJavaVersionVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
 // InstallMissingAndroidToolsStepListItem ....
 func InstallMissingAndroidToolsStepListItem() bitriseModels.StepListItemModel {
 	stepIDComposite := stepIDComposite(InstallMissingAndroidToolsID, InstallMissingAndroidToolsVersion)
@@ -97,6 +106,36 @@ func GradleRunnerStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitri
 	return stepListItem(stepIDComposite, "", "", inputs...)
 }
 
+// SignAPKStepListItem ...
+func SignAPKStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(SignAPKID, SignAPKVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// AndroidUnitTestStepListItem ...
+func AndroidUnitTestStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(AndroidUnitTestID, AndroidUnitTestVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// AndroidLintStepListItem ...
+func AndroidLintStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(AndroidLintID, AndroidLintVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// AndroidBuildForUITestingStepListItem ...
+func AndroidBuildForUITestingStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(AndroidBuildForUITestingID, AndroidBuildForUITestingVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// VirtualDeviceTestingForAndroidStepListItem ...
+func VirtualDeviceTestingForAndroidStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(VirtualDeviceTestingForAndroidID, VirtualDeviceTestingForAndroidVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
 // FastlaneStepListItem ...
 func FastlaneStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
 	stepIDComposite := stepIDComposite(FastlaneID, FastlaneVersion)
@@ -205,3 +244,27 @@ func IonicBuildStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitrise
 	stepIDComposite := stepIDComposite(IonicBuildID, IonicBuildVersion)
 	return stepListItem(stepIDComposite, "", "", inputs...)
 }
+
+// NpmStepListItem ...
+func NpmStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(NpmID, NpmVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// YarnStepListItem ...
+func YarnStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(YarnID, YarnVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// CachePullStepListItem ...
+func CachePullStepListItem() bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(CachePullID, CachePullVersion)
+	return stepListItem(stepIDComposite, "", "")
+}
+
+// CachePushStepListItem ...
+func CachePushStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(CachePushID, CachePushVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
@@ -0,0 +1,198 @@
+// Package submit uploads the scan result to a remote service.
+package submit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the key identifying the submitted content,
+	// the key does not change between the retries of a submission, so the server can drop the duplicates.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// maxResponseSize limits the response stored in the receipt.
+	maxResponseSize = 1024 * 1024
+)
+
+// OptionsModel ...
+type OptionsModel struct {
+	URL      string
+	APIToken string
+	// Timeout is the timeout of a single request, no timeout if 0.
+	Timeout time.Duration
+	// Retries is the number of retries after a failed (network error or 5xx) request.
+	Retries int
+	// RetryWait is the wait before the first retry, doubled before every further retry.
+	RetryWait time.Duration
+	Gzip      bool
+}
+
+// ReceiptModel describes the outcome of a submission.
+type ReceiptModel struct {
+	URL            string    `json:"url" yaml:"url"`
+	IdempotencyKey string    `json:"idempotency_key" yaml:"idempotency_key"`
+	Attempts       int       `json:"attempts" yaml:"attempts"`
+	StatusCode     int       `json:"status_code,omitempty" yaml:"status_code,omitempty"`
+	Response       string    `json:"response,omitempty" yaml:"response,omitempty"`
+	Error          string    `json:"error,omitempty" yaml:"error,omitempty"`
+	SubmittedAt    time.Time `json:"submitted_at" yaml:"submitted_at"`
+}
+
+// ResultFile submits the scan result file at pth as a JSON POST request body.
+// The returned receipt is filled as far as the submission got, even if an error is returned.
+func ResultFile(ctx context.Context, pth string, opts OptionsModel) (ReceiptModel, error) {
+	receipt := ReceiptModel{URL: redactURL(opts.URL)}
+
+	if exist, err := pathutil.IsPathExists(pth); err != nil {
+		return receipt, err
+	} else if !exist {
+		return receipt, fmt.Errorf("scan result not found at: %s", pth)
+	}
+
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return receipt, fmt.Errorf("failed to read scan result (%s), error: %s", pth, err)
+	}
+
+	return Submit(ctx, content, opts)
+}
+
+// Submit sends the content as a JSON POST request body to the opts.URL.
+// The API token is sent in the Authorization header, failed requests (network error or 5xx) are retried with backoff.
+func Submit(ctx context.Context, content []byte, opts OptionsModel) (ReceiptModel, error) {
+	receipt := ReceiptModel{
+		URL:            redactURL(opts.URL),
+		IdempotencyKey: idempotencyKey(content),
+	}
+
+	if opts.URL == "" {
+		return receipt, fmt.Errorf("submit url not provided")
+	}
+
+	body := content
+	if opts.Gzip {
+		var err error
+		if body, err = gzipContent(content); err != nil {
+			return receipt, fmt.Errorf("failed to compress the request body, error: %s", err)
+		}
+	}
+
+	client := &http.Client{Timeout: opts.Timeout}
+	wait := opts.RetryWait
+
+	for attempt := 1; ; attempt++ {
+		receipt.Attempts = attempt
+		receipt.SubmittedAt = time.Now().UTC()
+
+		statusCode, response, err := send(ctx, client, body, receipt.IdempotencyKey, opts)
+		receipt.StatusCode = statusCode
+		receipt.Response = response
+		receipt.Error = ""
+
+		retryable := false
+		if err != nil {
+			retryable = ctx.Err() == nil
+			// the network errors contain the url
+			err = errors.New(strings.Replace(err.Error(), opts.URL, receipt.URL, -1))
+		} else if statusCode >= 500 {
+			retryable = true
+			err = fmt.Errorf("server error, status code: %d, response: %s", statusCode, response)
+		} else if statusCode >= 300 {
+			err = fmt.Errorf("request failed, status code: %d, response: %s", statusCode, response)
+		}
+
+		if err == nil {
+			return receipt, nil
+		}
+		receipt.Error = err.Error()
+
+		if !retryable || attempt > opts.Retries {
+			return receipt, err
+		}
+
+		log.Warnft("Submit attempt %d failed, retrying in %s, error: %s", attempt, wait, err)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			receipt.Error = ctx.Err().Error()
+			return receipt, ctx.Err()
+		}
+		wait *= 2
+	}
+}
+
+func send(ctx context.Context, client *http.Client, body []byte, idempotencyKey string, opts OptionsModel) (int, string, error) {
+	request, err := http.NewRequest("POST", opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	request = request.WithContext(ctx)
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	if opts.Gzip {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	if opts.APIToken != "" {
+		request.Header.Set("Authorization", "token "+opts.APIToken)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			log.Warnft("Failed to close response body, error: %s", err)
+		}
+	}()
+
+	responseBytes, err := ioutil.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return response.StatusCode, "", fmt.Errorf("failed to read response, error: %s", err)
+	}
+
+	return response.StatusCode, strings.TrimSpace(string(responseBytes)), nil
+}
+
+func gzipContent(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// idempotencyKey is the content's hash, resubmitting the same scan result reuses the key.
+func idempotencyKey(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// redactURL drops the query of the url, as it may contain credentials (like the legacy api_token parameter).
+func redactURL(url string) string {
+	if idx := strings.Index(url, "?"); idx != -1 {
+		return url[:idx]
+	}
+	return url
+}
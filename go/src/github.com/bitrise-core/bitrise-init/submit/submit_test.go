@@ -0,0 +1,197 @@
+package submit
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+type requestModel struct {
+	header http.Header
+	query  string
+	body   string
+}
+
+// testServer responds with the given status codes in order, the last status code is repeated.
+type testServer struct {
+	statusCodes []int
+
+	mutex    sync.Mutex
+	requests []requestModel
+}
+
+func (server *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var body []byte
+	var err error
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		reader, gzipErr := gzip.NewReader(r.Body)
+		if gzipErr != nil {
+			http.Error(w, gzipErr.Error(), http.StatusBadRequest)
+			return
+		}
+		body, err = ioutil.ReadAll(reader)
+	} else {
+		body, err = ioutil.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	server.requests = append(server.requests, requestModel{header: r.Header, query: r.URL.RawQuery, body: string(body)})
+
+	idx := len(server.requests) - 1
+	if idx >= len(server.statusCodes) {
+		idx = len(server.statusCodes) - 1
+	}
+	w.WriteHeader(server.statusCodes[idx])
+	if _, err := w.Write([]byte(`{"status":"ok"}`)); err != nil {
+		panic(err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	content := []byte(`{"options":{}}`)
+
+	t.Log("submits")
+	{
+		handler := &testServer{statusCodes: []int{http.StatusOK}}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		receipt, err := Submit(context.Background(), content, OptionsModel{URL: server.URL, APIToken: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, 1, receipt.Attempts)
+		require.Equal(t, http.StatusOK, receipt.StatusCode)
+		require.Equal(t, `{"status":"ok"}`, receipt.Response)
+		require.Equal(t, idempotencyKey(content), receipt.IdempotencyKey)
+
+		require.Equal(t, 1, len(handler.requests))
+		request := handler.requests[0]
+		require.Equal(t, "token secret", request.header.Get("Authorization"))
+		require.Equal(t, "", request.query)
+		require.Equal(t, "application/json", request.header.Get("Content-Type"))
+		require.Equal(t, receipt.IdempotencyKey, request.header.Get(IdempotencyKeyHeader))
+		require.Equal(t, string(content), request.body)
+	}
+
+	t.Log("gzip")
+	{
+		handler := &testServer{statusCodes: []int{http.StatusOK}}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		_, err := Submit(context.Background(), content, OptionsModel{URL: server.URL, Gzip: true})
+		require.NoError(t, err)
+		require.Equal(t, "gzip", handler.requests[0].header.Get("Content-Encoding"))
+		require.Equal(t, string(content), handler.requests[0].body)
+	}
+
+	t.Log("retries on server error with the same idempotency key")
+	{
+		handler := &testServer{statusCodes: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusCreated}}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		receipt, err := Submit(context.Background(), content, OptionsModel{URL: server.URL, Retries: 3, RetryWait: time.Millisecond})
+		require.NoError(t, err)
+		require.Equal(t, 3, receipt.Attempts)
+		require.Equal(t, http.StatusCreated, receipt.StatusCode)
+		require.Equal(t, "", receipt.Error)
+
+		require.Equal(t, 3, len(handler.requests))
+		for _, request := range handler.requests {
+			require.Equal(t, receipt.IdempotencyKey, request.header.Get(IdempotencyKeyHeader))
+		}
+	}
+
+	t.Log("gives up after the retries")
+	{
+		handler := &testServer{statusCodes: []int{http.StatusServiceUnavailable}}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		receipt, err := Submit(context.Background(), content, OptionsModel{URL: server.URL, Retries: 2, RetryWait: time.Millisecond})
+		require.Error(t, err)
+		require.Equal(t, 3, receipt.Attempts)
+		require.Equal(t, http.StatusServiceUnavailable, receipt.StatusCode)
+		require.Equal(t, err.Error(), receipt.Error)
+	}
+
+	t.Log("does not retry on client error")
+	{
+		handler := &testServer{statusCodes: []int{http.StatusUnauthorized}}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		receipt, err := Submit(context.Background(), content, OptionsModel{URL: server.URL, Retries: 2, RetryWait: time.Millisecond})
+		require.Error(t, err)
+		require.Equal(t, 1, receipt.Attempts)
+		require.Equal(t, http.StatusUnauthorized, receipt.StatusCode)
+	}
+
+	t.Log("request timeout")
+	{
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		receipt, err := Submit(context.Background(), content, OptionsModel{URL: server.URL, Timeout: 100 * time.Millisecond, Retries: 1, RetryWait: time.Millisecond})
+		require.Error(t, err)
+		require.Equal(t, 2, receipt.Attempts)
+	}
+
+	t.Log("redacts the url query")
+	{
+		handler := &testServer{statusCodes: []int{http.StatusOK}}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		receipt, err := Submit(context.Background(), content, OptionsModel{URL: server.URL + "?api_token=secret"})
+		require.NoError(t, err)
+		require.Equal(t, server.URL, receipt.URL)
+	}
+}
+
+func TestResultFile(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("submit")
+	require.NoError(t, err)
+
+	handler := &testServer{statusCodes: []int{http.StatusOK}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Log("missing result file")
+	{
+		_, err := ResultFile(context.Background(), filepath.Join(tmpDir, "missing.json"), OptionsModel{URL: server.URL})
+		require.Error(t, err)
+		require.Equal(t, 0, len(handler.requests))
+	}
+
+	t.Log("result file")
+	{
+		pth := filepath.Join(tmpDir, "result.json")
+		require.NoError(t, fileutil.WriteStringToFile(pth, `{"options":{}}`))
+
+		receipt, err := ResultFile(context.Background(), pth, OptionsModel{URL: server.URL})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, receipt.StatusCode)
+		require.Equal(t, `{"options":{}}`, handler.requests[0].body)
+	}
+}
@@ -0,0 +1,368 @@
+// Package upgrade refreshes the pinned versions of the known steps in an existing bitrise config.
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/steps"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const bitriseStepLibSource = "https://github.com/bitrise-io/bitrise-steplib.git"
+
+// CatalogModel describes the versions to upgrade the steps to.
+type CatalogModel struct {
+	// Versions maps the step ids to the versions to upgrade to.
+	Versions     map[string]string
+	InputRenames []steps.InputRenameModel
+}
+
+// DefaultCatalog returns the catalog of the step versions used in the generated configs.
+func DefaultCatalog() CatalogModel {
+	return CatalogModel{
+		Versions:     steps.Versions,
+		InputRenames: steps.InputRenames,
+	}
+}
+
+// CatalogFromStepLib returns the catalog of the latest versions of the known steps in the given StepLib,
+// the pth is a StepLib spec (spec.json) or a cloned StepLib dir.
+func CatalogFromStepLib(pth string) (CatalogModel, error) {
+	stepLib, err := steps.OpenStepLib(pth)
+	if err != nil {
+		return CatalogModel{}, err
+	}
+
+	catalog := CatalogModel{
+		Versions:     map[string]string{},
+		InputRenames: steps.InputRenames,
+	}
+	for stepID := range steps.Versions {
+		if version, ok := stepLib.LatestVersion(stepID); ok {
+			catalog.Versions[stepID] = version
+		}
+	}
+
+	return catalog, nil
+}
+
+// ChangeModel describes an upgraded step.
+type ChangeModel struct {
+	WorkflowID    string
+	StepID        string
+	FromVersion   string
+	ToVersion     string
+	RenamedInputs []string
+}
+
+// String ...
+func (change ChangeModel) String() string {
+	s := fmt.Sprintf("%s: %s %s -> %s", change.WorkflowID, change.StepID, change.FromVersion, change.ToVersion)
+	if len(change.RenamedInputs) > 0 {
+		s += fmt.Sprintf(" (renamed inputs: %s)", strings.Join(change.RenamedInputs, ", "))
+	}
+	return s
+}
+
+// stepUpgradeModel describes the upgrade of the step at the stepIndex position of the workflow.
+type stepUpgradeModel struct {
+	workflowID     string
+	stepIndex      int
+	compositeID    string
+	newCompositeID string
+	// inputRenames maps the input keys to their new keys
+	inputRenames map[string]string
+	change       ChangeModel
+}
+
+// Upgrade bumps the pinned versions of the catalog's steps in the config content to the catalog's versions,
+// and migrates the inputs renamed in between.
+// Steps not coming from the default steplib, not pinned to a version or pinned to a newer version are kept as they are.
+// The config is upgraded by editing the lines of the changed step ids and input keys, so the rest of the config
+// (comments, key order and the keys unknown to the bitrise models) is kept as it is.
+// The returned changes are ordered by the workflow ids and the steps' positions.
+func Upgrade(content string, catalog CatalogModel) (string, []ChangeModel, error) {
+	var config bitriseModels.BitriseDataModel
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return "", nil, err
+	}
+
+	stepUpgrades, err := planUpgrade(config, catalog)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	changes := []ChangeModel{}
+	for _, stepUpgrade := range stepUpgrades {
+		if err := applyStepUpgrade(lines, stepUpgrade); err != nil {
+			return "", nil, fmt.Errorf("workflow (%s) step (%s): %s", stepUpgrade.workflowID, stepUpgrade.compositeID, err)
+		}
+		changes = append(changes, stepUpgrade.change)
+	}
+
+	return strings.Join(lines, ""), changes, nil
+}
+
+func planUpgrade(config bitriseModels.BitriseDataModel, catalog CatalogModel) ([]stepUpgradeModel, error) {
+	defaultStepLibSource := config.DefaultStepLibSource
+	if defaultStepLibSource == "" {
+		defaultStepLibSource = bitriseStepLibSource
+	}
+
+	workflowIDs := []string{}
+	for workflowID := range config.Workflows {
+		workflowIDs = append(workflowIDs, workflowID)
+	}
+	sort.Strings(workflowIDs)
+
+	stepUpgrades := []stepUpgradeModel{}
+	for _, workflowID := range workflowIDs {
+		for stepIndex, stepListItem := range config.Workflows[workflowID].Steps {
+			compositeID, step, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+			if err != nil {
+				return nil, fmt.Errorf("workflow (%s): %s", workflowID, err)
+			}
+
+			stepIDData, err := bitriseModels.CreateStepIDDataFromString(compositeID, defaultStepLibSource)
+			if err != nil {
+				return nil, fmt.Errorf("workflow (%s): %s", workflowID, err)
+			}
+
+			version, known := catalog.Versions[stepIDData.IDorURI]
+			if !known || stepIDData.SteplibSource != defaultStepLibSource || stepIDData.Version == "" || steps.CompareVersions(stepIDData.Version, version) >= 0 {
+				continue
+			}
+
+			inputRenames, renamedInputs, err := renameInputs(step.Inputs, catalog.InputRenames, stepIDData.IDorURI, stepIDData.Version, version)
+			if err != nil {
+				return nil, fmt.Errorf("workflow (%s) step (%s): %s", workflowID, compositeID, err)
+			}
+
+			stepUpgrades = append(stepUpgrades, stepUpgradeModel{
+				workflowID:     workflowID,
+				stepIndex:      stepIndex,
+				compositeID:    compositeID,
+				newCompositeID: strings.TrimSuffix(compositeID, stepIDData.Version) + version,
+				inputRenames:   inputRenames,
+				change: ChangeModel{
+					WorkflowID:    workflowID,
+					StepID:        stepIDData.IDorURI,
+					FromVersion:   stepIDData.Version,
+					ToVersion:     version,
+					RenamedInputs: renamedInputs,
+				},
+			})
+		}
+	}
+
+	return stepUpgrades, nil
+}
+
+// applyStepUpgrade edits the step id and the renamed input keys of the step in the config lines.
+func applyStepUpgrade(lines []string, stepUpgrade stepUpgradeModel) error {
+	workflowsLine, workflowsEnd, err := findMappingKey(lines, 0, len(lines), "workflows")
+	if err != nil {
+		return err
+	}
+	workflowLine, workflowEnd, err := findMappingKey(lines, workflowsLine+1, workflowsEnd, stepUpgrade.workflowID)
+	if err != nil {
+		return err
+	}
+	stepsLine, stepsEnd, err := findMappingKey(lines, workflowLine+1, workflowEnd, "steps")
+	if err != nil {
+		return err
+	}
+	stepLine, stepEnd, err := findListItem(lines, stepsLine+1, stepsEnd, stepUpgrade.stepIndex)
+	if err != nil {
+		return err
+	}
+	if err := renameKey(lines, stepLine, stepUpgrade.compositeID, stepUpgrade.newCompositeID); err != nil {
+		return err
+	}
+
+	if len(stepUpgrade.inputRenames) == 0 {
+		return nil
+	}
+
+	inputsLine, inputsEnd, err := findMappingKey(lines, stepLine+1, stepEnd, "inputs")
+	if err != nil {
+		return err
+	}
+	for index := 0; ; index++ {
+		inputLine, inputEnd, err := findListItem(lines, inputsLine+1, inputsEnd, index)
+		if err != nil {
+			break
+		}
+		// the env key is the list item's key or one of its siblings, like: - opts: {...}\n  key: value
+		keyIndent := listItemContentIndent(lines[inputLine])
+		for i := inputLine; i < inputEnd; i++ {
+			if i != inputLine && (isBlankLine(lines[i]) || indentOf(lines[i]) != keyIndent) {
+				continue
+			}
+			key := lineKey(lines[i])
+			if newKey, ok := stepUpgrade.inputRenames[key]; ok {
+				if err := renameKey(lines, i, key, newKey); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isBlankLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// listItemContentIndent returns the indent of the list item's content, like 4 in: "  - key: value"
+func listItemContentIndent(line string) int {
+	indent := indentOf(line)
+	rest := strings.TrimPrefix(line[indent:], "-")
+	return indent + 1 + indentOf(rest)
+}
+
+// lineKey returns the (unquoted) mapping key of the line, the list item dash is skipped.
+func lineKey(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+	}
+
+	if strings.HasPrefix(trimmed, "\"") || strings.HasPrefix(trimmed, "'") {
+		if end := strings.Index(trimmed[1:], trimmed[:1]); end >= 0 {
+			return trimmed[1 : end+1]
+		}
+		return ""
+	}
+
+	// step ids may contain colons (like git::https://...), the key is closed by ": " or a trailing ":"
+	if end := strings.Index(trimmed, ": "); end >= 0 {
+		return trimmed[:end]
+	}
+	return strings.TrimSuffix(trimmed, ":")
+}
+
+// findMappingKey returns the line of the key in the mapping placed between the start and end lines,
+// and the end of the key's block (the line after its value).
+func findMappingKey(lines []string, start, end int, key string) (int, int, error) {
+	keyIndent := -1
+	keyLine := -1
+	for i := start; i < end; i++ {
+		if isBlankLine(lines[i]) {
+			continue
+		}
+		indent := indentOf(lines[i])
+		if keyIndent == -1 {
+			keyIndent = indent
+		}
+		// a list may be placed at the indent of its key, like: steps:\n- script: {}
+		if indent > keyIndent || strings.HasPrefix(strings.TrimSpace(lines[i]), "-") {
+			continue
+		}
+		if keyLine != -1 {
+			return keyLine, i, nil
+		}
+		if indent == keyIndent && lineKey(lines[i]) == key {
+			keyLine = i
+		}
+	}
+	if keyLine != -1 {
+		return keyLine, end, nil
+	}
+	return 0, 0, fmt.Errorf("%s not found in the config", key)
+}
+
+// findListItem returns the line of the list item at the index, in the list placed between the start and end lines,
+// and the end of the item's block.
+func findListItem(lines []string, start, end, index int) (int, int, error) {
+	itemIndent := -1
+	itemLine := -1
+	current := -1
+	for i := start; i < end; i++ {
+		if isBlankLine(lines[i]) {
+			continue
+		}
+		indent := indentOf(lines[i])
+		if itemIndent == -1 {
+			itemIndent = indent
+		}
+		if indent > itemIndent {
+			continue
+		}
+		if itemLine != -1 {
+			return itemLine, i, nil
+		}
+		if indent < itemIndent || !strings.HasPrefix(strings.TrimSpace(lines[i]), "-") {
+			break
+		}
+		current++
+		if current == index {
+			itemLine = i
+		}
+	}
+	if itemLine != -1 {
+		return itemLine, end, nil
+	}
+	return 0, 0, fmt.Errorf("list item #%d not found in the config", index)
+}
+
+// renameKey replaces the line's key with the newKey.
+func renameKey(lines []string, line int, key, newKey string) error {
+	if lineKey(lines[line]) != key {
+		return fmt.Errorf("%s not found in line: %s", key, strings.TrimSpace(lines[line]))
+	}
+	lines[line] = strings.Replace(lines[line], key, newKey, 1)
+	return nil
+}
+
+// renameInputs returns the new keys of the step's inputs renamed after the fromVersion, up to (and including) the toVersion.
+func renameInputs(inputs []envmanModels.EnvironmentItemModel, renames []steps.InputRenameModel, stepID, fromVersion, toVersion string) (map[string]string, []string, error) {
+	inputRenames := map[string]string{}
+	renamedInputs := []string{}
+	if len(inputs) == 0 {
+		return inputRenames, renamedInputs, nil
+	}
+
+	// the renames are applied in the order of the versions, so that chained renames (a -> b -> c) work
+	stepRenames := []steps.InputRenameModel{}
+	for _, rename := range renames {
+		if rename.StepID == stepID && steps.CompareVersions(rename.Version, fromVersion) > 0 && steps.CompareVersions(rename.Version, toVersion) <= 0 {
+			stepRenames = append(stepRenames, rename)
+		}
+	}
+	sort.SliceStable(stepRenames, func(i, j int) bool {
+		return steps.CompareVersions(stepRenames[i].Version, stepRenames[j].Version) < 0
+	})
+
+	for _, input := range inputs {
+		key, _, err := input.GetKeyValuePair()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		newKey := key
+		for _, rename := range stepRenames {
+			if rename.From == newKey {
+				newKey = rename.To
+			}
+		}
+
+		if newKey != key {
+			inputRenames[key] = newKey
+			renamedInputs = append(renamedInputs, key+" -> "+newKey)
+		}
+	}
+
+	return inputRenames, renamedInputs, nil
+}
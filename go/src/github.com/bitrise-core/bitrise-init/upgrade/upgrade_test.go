@@ -0,0 +1,219 @@
+package upgrade
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+const config = `format_version: 1.3.1
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+# comments, unknown keys and the order of the keys are kept
+meta:
+  bitrise.io:
+    stack: linux-docker-android-22.04
+workflows:
+  deploy:
+    steps:
+    - git-clone@3.0.0: {}
+  primary:
+    # the steps
+    steps:
+    - git-clone@3.0.0: {}
+    - script: {}
+    - path::./local-step: {}
+    - 'gradle-runner@1.2.0':
+        title: Build
+        inputs:
+        - gradle_file: build.gradle # the root gradle file
+        - old_task: assemble
+          opts:
+            is_expand: false
+        - opts:
+            is_expand: false
+          other_task: old_task
+    - https://github.com/bitrise-io/bitrise-steplib.git::deploy-to-bitrise-io@99.0.0: {}
+    - custom-step@1.0.0: {}
+pipelines:
+  ci:
+    stages:
+    - test: {}
+`
+
+func TestUpgrade(t *testing.T) {
+	catalog := CatalogModel{
+		Versions: map[string]string{
+			steps.GitCloneID:          "4.0.0",
+			steps.ScriptID:            "1.1.5",
+			steps.GradleRunnerID:      "1.10.0",
+			steps.DeployToBitriseIoID: "1.3.0",
+		},
+		InputRenames: []steps.InputRenameModel{
+			{StepID: steps.GradleRunnerID, Version: "1.9.0", From: "mid_task", To: "gradle_task"},
+			{StepID: steps.GradleRunnerID, Version: "1.5.0", From: "old_task", To: "mid_task"},
+			{StepID: steps.GradleRunnerID, Version: "1.1.0", From: "gradle_file", To: "already_renamed"},
+			{StepID: steps.GradleRunnerID, Version: "2.0.0", From: "gradle_file", To: "not_yet_renamed"},
+			{StepID: steps.GradleRunnerID, Version: "1.6.0", From: "other_task", To: "another_task"},
+		},
+	}
+
+	upgraded, changes, err := Upgrade(config, catalog)
+	require.NoError(t, err)
+
+	require.Equal(t, []ChangeModel{
+		{WorkflowID: "deploy", StepID: "git-clone", FromVersion: "3.0.0", ToVersion: "4.0.0", RenamedInputs: []string{}},
+		{WorkflowID: "primary", StepID: "git-clone", FromVersion: "3.0.0", ToVersion: "4.0.0", RenamedInputs: []string{}},
+		{WorkflowID: "primary", StepID: "gradle-runner", FromVersion: "1.2.0", ToVersion: "1.10.0", RenamedInputs: []string{"old_task -> gradle_task", "other_task -> another_task"}},
+	}, changes)
+	require.Equal(t, "primary: gradle-runner 1.2.0 -> 1.10.0 (renamed inputs: old_task -> gradle_task, other_task -> another_task)", changes[2].String())
+
+	require.Equal(t, `format_version: 1.3.1
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+# comments, unknown keys and the order of the keys are kept
+meta:
+  bitrise.io:
+    stack: linux-docker-android-22.04
+workflows:
+  deploy:
+    steps:
+    - git-clone@4.0.0: {}
+  primary:
+    # the steps
+    steps:
+    - git-clone@4.0.0: {}
+    - script: {}
+    - path::./local-step: {}
+    - 'gradle-runner@1.10.0':
+        title: Build
+        inputs:
+        - gradle_file: build.gradle # the root gradle file
+        - gradle_task: assemble
+          opts:
+            is_expand: false
+        - opts:
+            is_expand: false
+          another_task: old_task
+    - https://github.com/bitrise-io/bitrise-steplib.git::deploy-to-bitrise-io@99.0.0: {}
+    - custom-step@1.0.0: {}
+pipelines:
+  ci:
+    stages:
+    - test: {}
+`, upgraded)
+
+	t.Log("lists at the indent of their key")
+	{
+		upgraded, _, err := Upgrade(`workflows:
+  primary:
+    steps:
+    - script@1.0.0:
+        inputs:
+        - content: echo
+    after_run:
+    - deploy
+  deploy:
+    steps:
+    - script@1.1.0: {}
+`, catalog)
+		require.NoError(t, err)
+		require.Equal(t, `workflows:
+  primary:
+    steps:
+    - script@1.1.5:
+        inputs:
+        - content: echo
+    after_run:
+    - deploy
+  deploy:
+    steps:
+    - script@1.1.5: {}
+`, upgraded)
+	}
+}
+
+func TestInputRenames(t *testing.T) {
+	for _, rename := range steps.InputRenames {
+		t.Logf("%s %s: %s -> %s", rename.StepID, rename.Version, rename.From, rename.To)
+
+		// upgrading from the version before the rename, to the pinned version migrates the input
+		require.True(t, steps.CompareVersions(rename.Version, steps.Versions[rename.StepID]) <= 0, "rename after the pinned version")
+
+		config := fmt.Sprintf(`workflows:
+  primary:
+    steps:
+    - %s@0.0.1:
+        inputs:
+        - %s: value
+`, rename.StepID, rename.From)
+
+		// the input may be renamed again by a later rename
+		expectedKey := rename.To
+		for _, next := range steps.InputRenames {
+			if next.StepID == rename.StepID && steps.CompareVersions(next.Version, rename.Version) > 0 && next.From == expectedKey {
+				expectedKey = next.To
+			}
+		}
+
+		upgraded, changes, err := Upgrade(config, DefaultCatalog())
+		require.NoError(t, err)
+		require.Equal(t, 1, len(changes))
+		require.True(t, strings.Contains(upgraded, fmt.Sprintf("- %s@%s:\n", rename.StepID, steps.Versions[rename.StepID])), upgraded)
+		require.True(t, strings.Contains(upgraded, "- "+expectedKey+": value\n"), upgraded)
+	}
+}
+
+func TestUpgradeSignAPKPath(t *testing.T) {
+	config := `workflows:
+  deploy:
+    steps:
+    - gradle-runner@1.5.6:
+        inputs:
+        - gradle_task: bundleRelease
+        - apk_file_include_filter: "*.apk"
+    - sign-apk@1.2.0:
+        inputs:
+        - apk_path: $BITRISE_APK_PATH
+`
+
+	upgraded, changes, err := Upgrade(config, DefaultCatalog())
+	require.NoError(t, err)
+	require.Equal(t, []ChangeModel{
+		{WorkflowID: "deploy", StepID: steps.GradleRunnerID, FromVersion: "1.5.6", ToVersion: steps.GradleRunnerVersion, RenamedInputs: []string{"apk_file_include_filter -> app_file_include_filter"}},
+		{WorkflowID: "deploy", StepID: steps.SignAPKID, FromVersion: "1.2.0", ToVersion: steps.SignAPKVersion, RenamedInputs: []string{"apk_path -> android_app"}},
+	}, changes)
+	require.Equal(t, fmt.Sprintf(`workflows:
+  deploy:
+    steps:
+    - gradle-runner@%s:
+        inputs:
+        - gradle_task: bundleRelease
+        - app_file_include_filter: "*.apk"
+    - sign-apk@%s:
+        inputs:
+        - android_app: $BITRISE_APK_PATH
+`, steps.GradleRunnerVersion, steps.SignAPKVersion), upgraded)
+}
+
+func TestCatalogFromStepLib(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("upgrade")
+	require.NoError(t, err)
+
+	pth := filepath.Join(tmpDir, "spec.json")
+	require.NoError(t, fileutil.WriteStringToFile(pth, `{
+  "format_version": "1.0.0",
+  "steps": {
+    "git-clone": {"latest_version_number": "4.0.17", "versions": {"4.0.17": {}, "3.4.3": {}}},
+    "unknown-step": {"latest_version_number": "1.0.0"}
+  }
+}`))
+
+	catalog, err := CatalogFromStepLib(pth)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"git-clone": "4.0.17"}, catalog.Versions)
+}
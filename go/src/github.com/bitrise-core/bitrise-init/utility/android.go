@@ -1,13 +1,21 @@
 package utility
 
 import (
+	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-io/go-utils/sliceutil"
 )
 
 const (
-	buildGradleBasePath = "build.gradle"
-	gradlewBasePath     = "gradlew"
+	buildGradleBasePath    = "build.gradle"
+	buildGradleKtsBasePath = "build.gradle.kts"
+	gradlewBasePath        = "gradlew"
 )
 
 // FixedGradlewPath ...
@@ -74,3 +82,370 @@ func FilterGradlewFiles(fileList []string) ([]string, error) {
 
 	return fixedGradlewFiles, nil
 }
+
+// GradlewRootBuildGradleFiles returns the root build.gradle files of the gradle wrapper's project:
+// the shallowest build.gradle files in the gradlew's dir, which are not part of a nested gradle wrapper's project.
+func GradlewRootBuildGradleFiles(fileList []string, gradlewPth string, gradlewFiles []string) ([]string, error) {
+	gradlewDir := filepath.Dir(gradlewPth)
+
+	nestedGradlewDirs := []string{}
+	for _, pth := range gradlewFiles {
+		dir := filepath.Dir(pth)
+		if dir != gradlewDir && isPathInDir(dir, gradlewDir) {
+			nestedGradlewDirs = append(nestedGradlewDirs, dir)
+		}
+	}
+
+	projectFileList := []string{}
+	for _, pth := range fileList {
+		if !isPathInDir(pth, gradlewDir) {
+			continue
+		}
+
+		nested := false
+		for _, dir := range nestedGradlewDirs {
+			if isPathInDir(pth, dir) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			projectFileList = append(projectFileList, pth)
+		}
+	}
+
+	return FilterRootBuildGradleFiles(projectFileList)
+}
+
+// GradleCachePaths returns the cache paths of the Gradle dependency caches and downloaded distributions,
+// keyed by the wrapper properties of the gradlew in the gradleWrapperDir.
+func GradleCachePaths(gradleWrapperDir string) []string {
+	indicator := gradleWrapperDir + "/gradle/wrapper/gradle-wrapper.properties"
+	return []string{
+		CachePath("$HOME/.gradle/caches", indicator),
+		CachePath("$HOME/.gradle/wrapper", indicator),
+	}
+}
+
+// AndroidSigningConfigSource tells where a signing config reads the keystore credentials from.
+type AndroidSigningConfigSource string
+
+const (
+	// AndroidSigningConfigSourceEnv means the credentials are read from env vars.
+	AndroidSigningConfigSourceEnv AndroidSigningConfigSource = "env"
+	// AndroidSigningConfigSourceKeystoreProperties means the credentials are read from a properties file, like keystore.properties.
+	AndroidSigningConfigSourceKeystoreProperties AndroidSigningConfigSource = "keystore.properties"
+	// AndroidSigningConfigSourceLiteral means the credentials are hardcoded in the gradle file.
+	AndroidSigningConfigSourceLiteral AndroidSigningConfigSource = "literal"
+)
+
+// AndroidSigningConfigModel is an entry of an app module's signingConfigs block,
+// EnvKeys are the env vars the signing config reads.
+type AndroidSigningConfigModel struct {
+	Name    string
+	Sources []AndroidSigningConfigSource
+	EnvKeys []string
+}
+
+var (
+	androidApplicationPluginRegexp = regexp.MustCompile(`com\.android\.application|android\.application`)
+	signingConfigsBlockRegexp      = regexp.MustCompile(`\bsigningConfigs\s*\{`)
+	signingConfigEntryRegexp       = regexp.MustCompile(`(?:(?:create|register|getByName|maybeCreate)\(\s*["'](\w+)["']\s*\)|\b(\w+))\s*\{`)
+	signingConfigEnvRegexp         = regexp.MustCompile(`System\.getenv\(\s*["'](\w+)["']\s*\)|System\.env\.(\w+)|environmentVariable\(\s*["'](\w+)["']\s*\)`)
+	signingConfigPropertiesRegexp  = regexp.MustCompile(`\w*[Pp]roperties(?:\s*\[|\.getProperty\(|\.get\()`)
+	signingConfigLiteralRegexp     = regexp.MustCompile(`(?m)^\s*(?:storePassword|keyPassword)\s*=?\s*["']`)
+	gradleBundleBlockRegexp        = regexp.MustCompile(`(?m)^\s*bundle\s*\{`)
+)
+
+// AndroidModuleModel is a gradle module of an Android project,
+// Name is the module dir's path relative to the project's root dir.
+type AndroidModuleModel struct {
+	Name       string
+	GradleFile string
+
+	IsApp                bool
+	HasUnitTests         bool
+	HasInstrumentedTests bool
+}
+
+// AndroidModules returns the gradle modules of the rootGradleFile's project.
+func AndroidModules(fileList []string, rootGradleFile string) ([]AndroidModuleModel, error) {
+	rootDir := filepath.Dir(rootGradleFile)
+
+	modules := []AndroidModuleModel{}
+	for _, pth := range fileList {
+		if base := filepath.Base(pth); base != buildGradleBasePath && base != buildGradleKtsBasePath {
+			continue
+		}
+		if pth == rootGradleFile || !isPathInDir(filepath.Dir(pth), rootDir) {
+			continue
+		}
+
+		content, err := fileutil.ReadStringFromFile(pth)
+		if err != nil {
+			return []AndroidModuleModel{}, err
+		}
+
+		moduleDir := filepath.Dir(pth)
+		name, err := filepath.Rel(rootDir, moduleDir)
+		if err != nil {
+			return []AndroidModuleModel{}, err
+		}
+
+		modules = append(modules, AndroidModuleModel{
+			Name:                 name,
+			GradleFile:           pth,
+			IsApp:                androidApplicationPluginRegexp.MatchString(content),
+			HasUnitTests:         hasPathInDir(fileList, filepath.Join(moduleDir, "src", "test")),
+			HasInstrumentedTests: hasPathInDir(fileList, filepath.Join(moduleDir, "src", "androidTest")),
+		})
+	}
+	return modules, nil
+}
+
+// hasPathInDir reports whether the fileList has a path in the dir.
+func hasPathInDir(fileList []string, dir string) bool {
+	for _, pth := range fileList {
+		if strings.HasPrefix(pth, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AndroidLintTool ...
+type AndroidLintTool string
+
+const (
+	// AndroidLintToolLint is the Android Lint, configured by lint.xml.
+	AndroidLintToolLint AndroidLintTool = "lint"
+	// AndroidLintToolDetekt is the detekt Kotlin linter, configured by detekt.yml.
+	AndroidLintToolDetekt AndroidLintTool = "detekt"
+	// AndroidLintToolKtlint is the ktlint Kotlin linter, configured in .editorconfig.
+	AndroidLintToolKtlint AndroidLintTool = "ktlint"
+)
+
+var detektConfigBasePaths = []string{"detekt.yml", "detekt.yaml", "detekt-config.yml"}
+
+// AndroidLintTools returns the lint tools configured in the rootGradleFile's project.
+func AndroidLintTools(fileList []string, rootGradleFile string) ([]AndroidLintTool, error) {
+	rootDir := filepath.Dir(rootGradleFile)
+
+	toolMap := map[AndroidLintTool]bool{}
+	for _, pth := range fileList {
+		if !isPathInDir(filepath.Dir(pth), rootDir) {
+			continue
+		}
+
+		base := filepath.Base(pth)
+		switch {
+		case base == "lint.xml":
+			toolMap[AndroidLintToolLint] = true
+		case sliceutil.IsStringInSlice(base, detektConfigBasePaths):
+			toolMap[AndroidLintToolDetekt] = true
+		case base == ".editorconfig":
+			usesKtlint, err := FileContains(pth, "ktlint")
+			if err != nil {
+				return []AndroidLintTool{}, err
+			}
+			if usesKtlint {
+				toolMap[AndroidLintToolKtlint] = true
+			}
+		}
+	}
+
+	tools := []AndroidLintTool{}
+	for _, tool := range []AndroidLintTool{AndroidLintToolLint, AndroidLintToolDetekt, AndroidLintToolKtlint} {
+		if toolMap[tool] {
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+func isPathInDir(pth, dir string) bool {
+	if dir == "." {
+		return !filepath.IsAbs(pth) && !strings.HasPrefix(pth, "..")
+	}
+	return pth == dir || strings.HasPrefix(pth, dir+string(filepath.Separator))
+}
+
+// ParseAndroidSigningConfigs returns the entries of the gradle file content's signingConfigs block,
+// the debug signing config is skipped, as it uses the generated debug keystore.
+func ParseAndroidSigningConfigs(content string) []AndroidSigningConfigModel {
+	loc := signingConfigsBlockRegexp.FindStringIndex(content)
+	if loc == nil {
+		return []AndroidSigningConfigModel{}
+	}
+	body, _ := gradleBlockBody(content, loc[1]-1)
+
+	signingConfigs := []AndroidSigningConfigModel{}
+	for {
+		match := signingConfigEntryRegexp.FindStringSubmatchIndex(body)
+		if match == nil {
+			break
+		}
+
+		name := ""
+		if match[2] != -1 {
+			name = body[match[2]:match[3]]
+		} else {
+			name = body[match[4]:match[5]]
+		}
+
+		entryBody, end := gradleBlockBody(body, match[1]-1)
+		body = body[end:]
+
+		if name == "debug" {
+			continue
+		}
+		signingConfigs = append(signingConfigs, newAndroidSigningConfig(name, entryBody))
+	}
+	return signingConfigs
+}
+
+func newAndroidSigningConfig(name, body string) AndroidSigningConfigModel {
+	signingConfig := AndroidSigningConfigModel{Name: name}
+
+	for _, match := range signingConfigEnvRegexp.FindAllStringSubmatch(body, -1) {
+		for _, key := range match[1:] {
+			if key != "" && !sliceutil.IsStringInSlice(key, signingConfig.EnvKeys) {
+				signingConfig.EnvKeys = append(signingConfig.EnvKeys, key)
+			}
+		}
+	}
+	if len(signingConfig.EnvKeys) > 0 {
+		signingConfig.Sources = append(signingConfig.Sources, AndroidSigningConfigSourceEnv)
+	}
+	if signingConfigPropertiesRegexp.MatchString(body) {
+		signingConfig.Sources = append(signingConfig.Sources, AndroidSigningConfigSourceKeystoreProperties)
+	}
+	if signingConfigLiteralRegexp.MatchString(body) {
+		signingConfig.Sources = append(signingConfig.Sources, AndroidSigningConfigSourceLiteral)
+	}
+	return signingConfig
+}
+
+// gradleBlockBody returns the body of the block opened at the openBraceIdx,
+// and the index after the block's closing brace (or the content's length, if the block is not closed).
+func gradleBlockBody(content string, openBraceIdx int) (string, int) {
+	depth := 0
+	for i := openBraceIdx; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return content[openBraceIdx+1 : i], i + 1
+			}
+		}
+	}
+	return content[openBraceIdx+1:], len(content)
+}
+
+// HasAndroidBundleConfig reports whether the gradle file content configures the Android App Bundle (bundle block).
+func HasAndroidBundleConfig(content string) bool {
+	return gradleBundleBlockRegexp.MatchString(content)
+}
+
+var (
+	gradleDistributionURLRegexp        = regexp.MustCompile(`(?m)^\s*distributionUrl\s*=\s*(.+?)\s*$`)
+	gradleDistributionVersionRegexp    = regexp.MustCompile(`gradle-([0-9][0-9A-Za-z.\-]*?)-(?:all|bin)\.zip$`)
+	androidGradlePluginClasspathRegexp = regexp.MustCompile(`com\.android\.tools\.build:gradle:([0-9][0-9A-Za-z.\-]*)`)
+	androidGradlePluginIDVersionRegexp = regexp.MustCompile(`id\s*\(?\s*["']com\.android\.(?:application|library)["']\s*\)?\s*version\s*["']([^"']+)["']`)
+	androidGradlePluginCatalogRegexp   = regexp.MustCompile(`(?m)^\s*(?:agp|androidGradlePlugin|android-gradle-plugin|androidGradle)\s*=\s*["']([^"']+)["']`)
+)
+
+var (
+	gradleVersionCatalogRelPth    = filepath.Join("gradle", "libs.versions.toml")
+	gradleWrapperPropertiesRelPth = filepath.Join("gradle", "wrapper", "gradle-wrapper.properties")
+	gradleWrapperJarRelPth        = filepath.Join("gradle", "wrapper", "gradle-wrapper.jar")
+)
+
+// GradleWrapperModel is the inspected gradle wrapper of a project,
+// DistributionURL and GradleVersion are empty, if the gradle-wrapper.properties is missing or does not define them.
+type GradleWrapperModel struct {
+	PropertiesPth       string
+	HasProperties       bool
+	DistributionURL     string
+	GradleVersion       string
+	HasJar              bool
+	IsGradlewExecutable bool
+}
+
+// InspectGradleWrapper inspects the gradle wrapper files of the gradlew.
+func InspectGradleWrapper(gradlewPth string) (GradleWrapperModel, error) {
+	wrapperDir := filepath.Dir(gradlewPth)
+	wrapper := GradleWrapperModel{PropertiesPth: filepath.Join(wrapperDir, gradleWrapperPropertiesRelPth)}
+
+	gradlewInfo, err := os.Stat(gradlewPth)
+	if err != nil {
+		return GradleWrapperModel{}, err
+	}
+	wrapper.IsGradlewExecutable = gradlewInfo.Mode()&0111 != 0
+
+	if wrapper.HasJar, err = pathutil.IsPathExists(filepath.Join(wrapperDir, gradleWrapperJarRelPth)); err != nil {
+		return GradleWrapperModel{}, err
+	}
+
+	if wrapper.HasProperties, err = pathutil.IsPathExists(wrapper.PropertiesPth); err != nil {
+		return GradleWrapperModel{}, err
+	} else if !wrapper.HasProperties {
+		return wrapper, nil
+	}
+
+	content, err := fileutil.ReadStringFromFile(wrapper.PropertiesPth)
+	if err != nil {
+		return GradleWrapperModel{}, err
+	}
+	wrapper.DistributionURL, wrapper.GradleVersion = ParseGradleDistributionURL(content)
+
+	return wrapper, nil
+}
+
+// ParseGradleDistributionURL returns the distribution url and the gradle version of the gradle-wrapper.properties content.
+func ParseGradleDistributionURL(propertiesContent string) (string, string) {
+	match := gradleDistributionURLRegexp.FindStringSubmatch(propertiesContent)
+	if match == nil {
+		return "", ""
+	}
+	// the colons are escaped in the properties file: https\://services.gradle.org/...
+	distributionURL := strings.Replace(match[1], `\:`, ":", -1)
+
+	version := ""
+	if versionMatch := gradleDistributionVersionRegexp.FindStringSubmatch(distributionURL); versionMatch != nil {
+		version = versionMatch[1]
+	}
+	return distributionURL, version
+}
+
+// AndroidGradlePluginVersion returns the Android Gradle Plugin version used by the rootGradleFile's project,
+// it is read from the buildscript classpath, the plugins block or the gradle version catalog.
+func AndroidGradlePluginVersion(rootGradleFile string) (string, error) {
+	content, err := fileutil.ReadStringFromFile(rootGradleFile)
+	if err != nil {
+		return "", err
+	}
+	for _, re := range []*regexp.Regexp{androidGradlePluginClasspathRegexp, androidGradlePluginIDVersionRegexp} {
+		if match := re.FindStringSubmatch(content); match != nil {
+			return match[1], nil
+		}
+	}
+
+	catalogPth := filepath.Join(filepath.Dir(rootGradleFile), gradleVersionCatalogRelPth)
+	if exist, err := pathutil.IsPathExists(catalogPth); err != nil {
+		return "", err
+	} else if !exist {
+		return "", nil
+	}
+
+	content, err = fileutil.ReadStringFromFile(catalogPth)
+	if err != nil {
+		return "", err
+	}
+	if match := androidGradlePluginCatalogRegexp.FindStringSubmatch(content); match != nil {
+		return match[1], nil
+	}
+	return "", nil
+}
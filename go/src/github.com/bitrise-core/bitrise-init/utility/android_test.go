@@ -1,8 +1,12 @@
 package utility
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -87,3 +91,278 @@ func TestFilterGradlewFiles(t *testing.T) {
 		require.Equal(t, 0, len(files))
 	}
 }
+
+func TestGradlewRootBuildGradleFiles(t *testing.T) {
+	fileList := []string{
+		"gradlew",
+		"build.gradle",
+		"app/build.gradle",
+		"sdk-sample/gradlew",
+		"sdk-sample/build.gradle",
+		"sdk-sample/app/build.gradle",
+		"tools/gradlew",
+	}
+	gradlewFiles := []string{"./gradlew", "sdk-sample/gradlew", "tools/gradlew"}
+
+	t.Log("root gradle wrapper")
+	{
+		files, err := GradlewRootBuildGradleFiles(fileList, "./gradlew", gradlewFiles)
+		require.NoError(t, err)
+		require.Equal(t, []string{"build.gradle"}, files)
+	}
+
+	t.Log("nested gradle wrapper")
+	{
+		files, err := GradlewRootBuildGradleFiles(fileList, "sdk-sample/gradlew", gradlewFiles)
+		require.NoError(t, err)
+		require.Equal(t, []string{"sdk-sample/build.gradle"}, files)
+	}
+
+	t.Log("gradle wrapper without build.gradle")
+	{
+		files, err := GradlewRootBuildGradleFiles(fileList, "tools/gradlew", gradlewFiles)
+		require.NoError(t, err)
+		require.Equal(t, []string{}, files)
+	}
+
+	t.Log("gradle wrapper project without a root build.gradle")
+	{
+		files, err := GradlewRootBuildGradleFiles([]string{"gradlew", "app/build.gradle", "sdk-sample/gradlew", "sdk-sample/build.gradle"}, "./gradlew", gradlewFiles)
+		require.NoError(t, err)
+		require.Equal(t, []string{"app/build.gradle"}, files)
+	}
+}
+
+func TestParseAndroidSigningConfigs(t *testing.T) {
+	t.Log("groovy signing configs")
+	{
+		content := `android {
+    signingConfigs {
+        debug {
+            storeFile file("debug.keystore")
+        }
+        release {
+            storeFile file(keystoreProperties['storeFile'])
+            storePassword keystoreProperties['storePassword']
+            keyAlias System.getenv("KEY_ALIAS")
+        }
+        staging {
+            storePassword "password"
+            keyPassword 'password'
+        }
+    }
+}`
+		require.Equal(t, []AndroidSigningConfigModel{
+			{
+				Name:    "release",
+				Sources: []AndroidSigningConfigSource{AndroidSigningConfigSourceEnv, AndroidSigningConfigSourceKeystoreProperties},
+				EnvKeys: []string{"KEY_ALIAS"},
+			},
+			{
+				Name:    "staging",
+				Sources: []AndroidSigningConfigSource{AndroidSigningConfigSourceLiteral},
+			},
+		}, ParseAndroidSigningConfigs(content))
+	}
+
+	t.Log("kotlin signing configs")
+	{
+		content := `android {
+    signingConfigs {
+        create("release") {
+            storePassword = System.getenv("KEYSTORE_PASSWORD")
+            keyPassword = providers.environmentVariable("KEY_PASSWORD").get()
+        }
+    }
+}`
+		require.Equal(t, []AndroidSigningConfigModel{
+			{
+				Name:    "release",
+				Sources: []AndroidSigningConfigSource{AndroidSigningConfigSourceEnv},
+				EnvKeys: []string{"KEYSTORE_PASSWORD", "KEY_PASSWORD"},
+			},
+		}, ParseAndroidSigningConfigs(content))
+	}
+
+	t.Log("no signing configs")
+	{
+		require.Equal(t, []AndroidSigningConfigModel{}, ParseAndroidSigningConfigs(`android { compileSdkVersion 27 }`))
+	}
+}
+
+func TestHasAndroidBundleConfig(t *testing.T) {
+	require.Equal(t, true, HasAndroidBundleConfig("android {\n    bundle {\n        language { enableSplit = false }\n    }\n}"))
+	require.Equal(t, false, HasAndroidBundleConfig("android {\n    compileSdkVersion 27\n}"))
+}
+
+func TestAndroidModules(t *testing.T) {
+	t.Log("app module")
+	{
+		fixtureDir, err := filepath.Abs("../scanner/testdata/fixtures/android-simple")
+		require.NoError(t, err)
+
+		fileList, err := ListPathInDirSortedByComponents(fixtureDir, false)
+		require.NoError(t, err)
+
+		modules, err := AndroidModules(fileList, filepath.Join(fixtureDir, "build.gradle"))
+		require.NoError(t, err)
+		require.Equal(t, []AndroidModuleModel{
+			{Name: "app", GradleFile: filepath.Join(fixtureDir, "app", "build.gradle"), IsApp: true},
+		}, modules)
+	}
+
+	t.Log("test sources")
+	{
+		fileList := []string{
+			"android/build.gradle",
+			"android/app/src/test/java/ExampleTest.kt",
+			"android/app/src/androidTest/java/ExampleInstrumentedTest.kt",
+			"android/lib/src/test/java/LibTest.kt",
+			"android/lib/src/main/java/Lib.kt",
+		}
+		require.Equal(t, true, hasPathInDir(fileList, "android/app/src/test"))
+		require.Equal(t, true, hasPathInDir(fileList, "android/app/src/androidTest"))
+		require.Equal(t, false, hasPathInDir(fileList, "android/lib/src/androidTest"))
+	}
+}
+
+func TestAndroidLintTools(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__android_lint__")
+	require.NoError(t, err)
+
+	for pth, content := range map[string]string{
+		"build.gradle":             "",
+		"app/lint.xml":             "<lint></lint>",
+		"config/detekt/detekt.yml": "build:",
+		".editorconfig":            "[*.{kt,kts}]\nktlint_code_style = android_studio",
+		"other/.editorconfig":      "root = true",
+	} {
+		pth = filepath.Join(tmpDir, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0777))
+		require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	}
+
+	fileList, err := ListPathInDirSortedByComponents(tmpDir, false)
+	require.NoError(t, err)
+
+	tools, err := AndroidLintTools(fileList, filepath.Join(tmpDir, "build.gradle"))
+	require.NoError(t, err)
+	require.Equal(t, []AndroidLintTool{AndroidLintToolLint, AndroidLintToolDetekt, AndroidLintToolKtlint}, tools)
+}
+
+func TestParseGradleDistributionURL(t *testing.T) {
+	t.Log("escaped distribution url")
+	{
+		url, version := ParseGradleDistributionURL("distributionBase=GRADLE_USER_HOME\ndistributionUrl=https\\://services.gradle.org/distributions/gradle-8.7-bin.zip\n")
+		require.Equal(t, "https://services.gradle.org/distributions/gradle-8.7-bin.zip", url)
+		require.Equal(t, "8.7", version)
+	}
+
+	t.Log("pre-release distribution")
+	{
+		_, version := ParseGradleDistributionURL("distributionUrl=https\\://services.gradle.org/distributions/gradle-7.3.3-rc-1-all.zip")
+		require.Equal(t, "7.3.3-rc-1", version)
+	}
+
+	t.Log("no distribution url")
+	{
+		url, version := ParseGradleDistributionURL("distributionBase=GRADLE_USER_HOME")
+		require.Equal(t, "", url)
+		require.Equal(t, "", version)
+	}
+}
+
+func TestInspectGradleWrapper(t *testing.T) {
+	t.Log("complete gradle wrapper")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__gradle_wrapper__")
+		require.NoError(t, err)
+
+		gradlewPth := filepath.Join(tmpDir, "gradlew")
+		require.NoError(t, fileutil.WriteStringToFile(gradlewPth, "#!/usr/bin/env sh"))
+		require.NoError(t, os.Chmod(gradlewPth, 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "gradle", "wrapper"), 0777))
+		require.NoError(t, fileutil.WriteStringToFile(filepath.Join(tmpDir, "gradle", "wrapper", "gradle-wrapper.jar"), ""))
+		require.NoError(t, fileutil.WriteStringToFile(filepath.Join(tmpDir, "gradle", "wrapper", "gradle-wrapper.properties"), "distributionUrl=https\\://services.gradle.org/distributions/gradle-4.4-all.zip"))
+
+		wrapper, err := InspectGradleWrapper(gradlewPth)
+		require.NoError(t, err)
+		require.Equal(t, GradleWrapperModel{
+			PropertiesPth:       filepath.Join(tmpDir, "gradle", "wrapper", "gradle-wrapper.properties"),
+			HasProperties:       true,
+			DistributionURL:     "https://services.gradle.org/distributions/gradle-4.4-all.zip",
+			GradleVersion:       "4.4",
+			HasJar:              true,
+			IsGradlewExecutable: true,
+		}, wrapper)
+	}
+
+	t.Log("gradlew only")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__gradle_wrapper__")
+		require.NoError(t, err)
+
+		gradlewPth := filepath.Join(tmpDir, "gradlew")
+		require.NoError(t, fileutil.WriteStringToFile(gradlewPth, "#!/usr/bin/env sh"))
+		require.NoError(t, os.Chmod(gradlewPth, 0644))
+
+		wrapper, err := InspectGradleWrapper(gradlewPth)
+		require.NoError(t, err)
+		require.False(t, wrapper.HasProperties)
+		require.False(t, wrapper.HasJar)
+		require.False(t, wrapper.IsGradlewExecutable)
+		require.Equal(t, "", wrapper.GradleVersion)
+	}
+}
+
+func TestAndroidGradlePluginVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		files   map[string]string
+		version string
+	}{
+		{
+			name:    "buildscript classpath",
+			files:   map[string]string{"build.gradle": "dependencies {\n    classpath 'com.android.tools.build:gradle:3.1.0'\n}"},
+			version: "3.1.0",
+		},
+		{
+			name:    "plugins block",
+			files:   map[string]string{"build.gradle.kts": `plugins { id("com.android.application") version "8.2.0" apply false }`},
+			version: "8.2.0",
+		},
+		{
+			name: "version catalog",
+			files: map[string]string{
+				"build.gradle.kts":          "plugins { alias(libs.plugins.android.application) apply false }",
+				"gradle/libs.versions.toml": "[versions]\nagp = \"8.5.1\"\nkotlin = \"1.9.0\"",
+			},
+			version: "8.5.1",
+		},
+		{
+			name:    "unknown version",
+			files:   map[string]string{"build.gradle": "allprojects {}"},
+			version: "",
+		},
+	} {
+		t.Log(tc.name)
+		{
+			tmpDir, err := pathutil.NormalizedOSTempDirPath("__agp_version__")
+			require.NoError(t, err)
+
+			rootGradleFile := ""
+			for pth, content := range tc.files {
+				pth = filepath.Join(tmpDir, pth)
+				require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0777))
+				require.NoError(t, fileutil.WriteStringToFile(pth, content))
+				if filepath.Dir(pth) == tmpDir {
+					rootGradleFile = pth
+				}
+			}
+
+			version, err := AndroidGradlePluginVersion(rootGradleFile)
+			require.NoError(t, err)
+			require.Equal(t, tc.version, version)
+		}
+	}
+}
@@ -33,3 +33,9 @@ func HasCartfileResolvedInDirectoryOf(pth string) bool {
 	}
 	return exist
 }
+
+// CarthageCachePaths returns the cache path of the Carthage dir next to the Cartfile in the cartfileDir,
+// keyed by its Cartfile.resolved.
+func CarthageCachePaths(cartfileDir string) []string {
+	return []string{CachePath(cartfileDir+"/Carthage", cartfileDir+"/"+cartfileResolvedBase)}
+}
@@ -0,0 +1,44 @@
+package utility
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// RunCmdContext runs the command and waits for it to finish.
+// If the ctx is done before the command finishes, the command and its child processes are killed
+// and the ctx's error is returned.
+func RunCmdContext(ctx context.Context, cmd *exec.Cmd) error {
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// RunCmdContextAndReturnTrimmedCombinedOutput runs the command like RunCmdContext does,
+// and returns its trimmed combined (stdout and stderr) output.
+func RunCmdContextAndReturnTrimmedCombinedOutput(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := RunCmdContext(ctx, cmd)
+	return strings.TrimSpace(out.String()), err
+}
@@ -0,0 +1,37 @@
+package utility
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCmdContextAndReturnTrimmedCombinedOutput(t *testing.T) {
+	t.Log("finishes")
+	{
+		out, err := RunCmdContextAndReturnTrimmedCombinedOutput(context.Background(), exec.Command("bash", "-c", "echo out; echo err >&2"))
+		require.NoError(t, err)
+		require.Equal(t, "out\nerr", out)
+	}
+
+	t.Log("fails")
+	{
+		_, err := RunCmdContextAndReturnTrimmedCombinedOutput(context.Background(), exec.Command("bash", "-c", "exit 1"))
+		require.Error(t, err)
+	}
+
+	t.Log("killed with its child processes on timeout")
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		// the child sleep process holds the output pipe, the command only returns if it is killed too
+		_, err := RunCmdContextAndReturnTrimmedCombinedOutput(ctx, exec.Command("bash", "-c", "sleep 30 & wait"))
+		require.Equal(t, context.DeadlineExceeded, err)
+		require.True(t, time.Since(start) < 10*time.Second)
+	}
+}
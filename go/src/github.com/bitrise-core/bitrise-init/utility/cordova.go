@@ -50,6 +50,7 @@ func FilterRootConfigXMLFile(fileList []string) (string, error) {
 type PackagesModel struct {
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
+	Scripts         map[string]string `json:"scripts"`
 }
 
 func parsePackagesJSONContent(content string) (PackagesModel, error) {
@@ -1,12 +1,13 @@
 package utility
 
 import (
-	"bufio"
 	"path/filepath"
-	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
 )
 
 const (
@@ -24,72 +25,913 @@ func FilterFastfiles(fileList []string) ([]string, error) {
 	return SortPathsByComponents(fastfiles)
 }
 
-func inspectFastfileContent(content string) ([]string, error) {
-	commonLanes := []string{}
-	laneMap := map[string][]string{}
+// FastlaneLaneModel ...
+type FastlaneLaneModel struct {
+	Name        string
+	Platform    string
+	Description string
+}
 
-	// platform :ios do ...
-	platformSectionStartRegexp := regexp.MustCompile(`platform\s+:(?P<platform>.*)\s+do`)
-	platformSectionEndPattern := "end"
-	platform := ""
+// String returns the lane in the form it can be passed to fastlane, like: ios beta
+func (lane FastlaneLaneModel) String() string {
+	if lane.Platform == "" {
+		return lane.Name
+	}
+	return lane.Platform + " " + lane.Name
+}
 
-	// lane :test_and_snapshot do
-	laneRegexp := regexp.MustCompile(`^[\s]*lane\s+:(?P<lane>.*)\s+do`)
+// FastfileModel ...
+type FastfileModel struct {
+	Lanes []FastlaneLaneModel
+	// GitImports lists the import_from_git urls, lanes of these Fastfiles are not inspected.
+	GitImports      []string
+	DefaultPlatform string
+	// Actions lists the actions (and other methods) called by the Fastfiles.
+	Actions map[string]bool
+}
 
-	reader := strings.NewReader(content)
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := strings.TrimRight(scanner.Text(), " ")
+//
+// Fastfile tokenizer
+//
+// Fastfiles are ruby scripts, the tokenizer only understands as much of ruby
+// as needed to follow the block structure and to find the fastlane DSL calls
+// (platform, lane, private_lane, desc, import, import_from_git).
 
-		if platform != "" && line == platformSectionEndPattern {
-			platform = ""
-			continue
+type fastfileTokenKind int
+
+const (
+	fastfileTokenIdent fastfileTokenKind = iota
+	// fastfileTokenLabel is a hash key or keyword argument, like: url:
+	fastfileTokenLabel
+	fastfileTokenSymbol
+	fastfileTokenString
+	fastfileTokenPunct
+	fastfileTokenNewline
+)
+
+type fastfileToken struct {
+	kind  fastfileTokenKind
+	value string
+}
+
+type fastfileLexer struct {
+	src    []rune
+	pos    int
+	tokens []fastfileToken
+
+	parenDepth int
+	// heredoc terminators, which body starts on the next line
+	pendingHeredocs []string
+}
+
+func isRubyIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (lexer *fastfileLexer) peek(offset int) rune {
+	if lexer.pos+offset < len(lexer.src) {
+		return lexer.src[lexer.pos+offset]
+	}
+	return 0
+}
+
+func (lexer *fastfileLexer) lastToken() (fastfileToken, bool) {
+	if len(lexer.tokens) == 0 {
+		return fastfileToken{}, false
+	}
+	return lexer.tokens[len(lexer.tokens)-1], true
+}
+
+func (lexer *fastfileLexer) emit(kind fastfileTokenKind, value string) {
+	if kind == fastfileTokenNewline {
+		// statements continue in parentheses and brackets, newlines are not significant there
+		if lexer.parenDepth > 0 {
+			return
+		}
+		if last, ok := lexer.lastToken(); !ok || last.kind == fastfileTokenNewline {
+			return
 		}
+	}
+	lexer.tokens = append(lexer.tokens, fastfileToken{kind: kind, value: value})
+}
 
-		if platform == "" {
-			if match := platformSectionStartRegexp.FindStringSubmatch(line); len(match) == 2 {
-				platform = match[1]
+// expectsOperand returns true if an expression may start at the current position,
+// used to tell apart regexp literals from divisions and symbols from ternaries.
+func (lexer *fastfileLexer) expectsOperand() bool {
+	last, ok := lexer.lastToken()
+	if !ok {
+		return true
+	}
+	switch last.kind {
+	case fastfileTokenNewline, fastfileTokenLabel:
+		return true
+	case fastfileTokenPunct:
+		return last.value != ")" && last.value != "]" && last.value != "}"
+	case fastfileTokenIdent:
+		// method call without parentheses, like: desc /regexp/
+		return lexer.pos > 0 && unicode.IsSpace(lexer.src[lexer.pos-1]) && !unicode.IsSpace(lexer.peek(1))
+	}
+	return false
+}
+
+func (lexer *fastfileLexer) skipLine() {
+	for lexer.pos < len(lexer.src) && lexer.src[lexer.pos] != '\n' {
+		lexer.pos++
+	}
+}
+
+func (lexer *fastfileLexer) atLineStart() bool {
+	return lexer.pos == 0 || lexer.src[lexer.pos-1] == '\n'
+}
+
+func (lexer *fastfileLexer) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(lexer.src[lexer.pos:minInt(len(lexer.src), lexer.pos+len(prefix))]), prefix)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// readDelimited reads a string-like literal closed by the close rune, opening runes
+// (if they differ from the closing one) nest, interpolations are skipped.
+func (lexer *fastfileLexer) readDelimited(open, close rune, interpolate bool) string {
+	value := []rune{}
+	depth := 0
+	for lexer.pos < len(lexer.src) {
+		r := lexer.src[lexer.pos]
+		lexer.pos++
+
+		switch {
+		case r == '\\' && lexer.pos < len(lexer.src):
+			next := lexer.src[lexer.pos]
+			lexer.pos++
+			if next == close || next == open || next == '\\' {
+				value = append(value, next)
+			} else {
+				value = append(value, r, next)
+			}
+		case interpolate && r == '#' && lexer.peek(0) == '{':
+			lexer.pos++
+			value = append(value, '#', '{')
+			value = append(value, []rune(lexer.readDelimited('{', '}', true))...)
+			value = append(value, '}')
+		case r == open && open != close:
+			depth++
+			value = append(value, r)
+		case r == close:
+			if depth == 0 {
+				return string(value)
+			}
+			depth--
+			value = append(value, r)
+		default:
+			value = append(value, r)
+		}
+	}
+	return string(value)
+}
+
+func percentLiteralClose(open rune) rune {
+	switch open {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	case '{':
+		return '}'
+	case '<':
+		return '>'
+	}
+	return open
+}
+
+// readHeredocBodies skips the bodies of the heredocs started in the previous line.
+func (lexer *fastfileLexer) readHeredocBodies() {
+	for _, terminator := range lexer.pendingHeredocs {
+		for lexer.pos < len(lexer.src) {
+			start := lexer.pos
+			lexer.skipLine()
+			line := strings.TrimSpace(string(lexer.src[start:lexer.pos]))
+			if lexer.pos < len(lexer.src) {
+				lexer.pos++
+			}
+			if line == terminator {
+				break
+			}
+		}
+	}
+	lexer.pendingHeredocs = nil
+}
+
+func (lexer *fastfileLexer) tryReadHeredocStart() bool {
+	// <<~EOS, <<-EOS, <<EOS, <<~'EOS', <<~"EOS"
+	i := 2
+	if r := lexer.peek(i); r == '~' || r == '-' {
+		i++
+	}
+	quote := lexer.peek(i)
+	if quote == '\'' || quote == '"' {
+		i++
+	} else {
+		quote = 0
+	}
+
+	start := i
+	for isRubyIdentRune(lexer.peek(i)) {
+		i++
+	}
+	terminator := string(lexer.src[lexer.pos+start : lexer.pos+i])
+	if terminator == "" || (quote == 0 && strings.ToUpper(terminator) != terminator) {
+		return false
+	}
+	if quote != 0 {
+		if lexer.peek(i) != quote {
+			return false
+		}
+		i++
+	}
+
+	lexer.pos += i
+	lexer.pendingHeredocs = append(lexer.pendingHeredocs, terminator)
+	lexer.emit(fastfileTokenString, "")
+	return true
+}
+
+func (lexer *fastfileLexer) tokenize() []fastfileToken {
+	for lexer.pos < len(lexer.src) {
+		r := lexer.src[lexer.pos]
+
+		switch {
+		case lexer.atLineStart() && lexer.hasPrefix("=begin"):
+			for lexer.pos < len(lexer.src) && !(lexer.atLineStart() && lexer.hasPrefix("=end")) {
+				lexer.pos++
+			}
+			lexer.skipLine()
+		case lexer.atLineStart() && lexer.hasPrefix("__END__"):
+			lexer.pos = len(lexer.src)
+		case r == '\n':
+			lexer.pos++
+			lexer.emit(fastfileTokenNewline, "")
+			lexer.readHeredocBodies()
+		case r == '\\' && lexer.peek(1) == '\n':
+			// line continuation
+			lexer.pos += 2
+		case unicode.IsSpace(r):
+			lexer.pos++
+		case r == '#':
+			lexer.skipLine()
+		case r == ';':
+			lexer.pos++
+			lexer.emit(fastfileTokenNewline, "")
+		case r == '"' || r == '`':
+			lexer.pos++
+			lexer.emit(fastfileTokenString, lexer.readDelimited(r, r, true))
+		case r == '\'':
+			lexer.pos++
+			lexer.emit(fastfileTokenString, lexer.readDelimited(r, r, false))
+		case r == '%' && lexer.expectsOperand() && lexer.pos+1 < len(lexer.src):
+			// %q(), %Q{}, %w[], %i[], %r{}, %()
+			i := 1
+			if unicode.IsLetter(lexer.peek(i)) {
+				i++
+			}
+			open := lexer.peek(i)
+			if unicode.IsSpace(open) || isRubyIdentRune(open) || open == 0 {
+				lexer.pos++
+				lexer.emit(fastfileTokenPunct, "%")
 				continue
 			}
+			lexer.pos += i + 1
+			lexer.emit(fastfileTokenString, lexer.readDelimited(open, percentLiteralClose(open), true))
+		case r == '/' && lexer.expectsOperand():
+			lexer.pos++
+			lexer.emit(fastfileTokenString, lexer.readDelimited('/', '/', true))
+			for unicode.IsLetter(lexer.peek(0)) {
+				// regexp options
+				lexer.pos++
+			}
+		case r == '<' && lexer.peek(1) == '<' && lexer.tryReadHeredocStart():
+		case r == '?' && lexer.expectsOperand() && lexer.peek(1) != 0 && !isRubyIdentRune(lexer.peek(2)):
+			// character literal, like: ?a
+			lexer.pos += 2
+			lexer.emit(fastfileTokenString, string(lexer.src[lexer.pos-1]))
+		case r == ':' && lexer.peek(1) == ':':
+			lexer.pos += 2
+			lexer.emit(fastfileTokenPunct, "::")
+		case r == ':' && (lexer.peek(1) == '"' || lexer.peek(1) == '\''):
+			quote := lexer.peek(1)
+			lexer.pos += 2
+			lexer.emit(fastfileTokenSymbol, lexer.readDelimited(quote, quote, quote == '"'))
+		case r == ':' && (isRubyIdentRune(lexer.peek(1)) && !unicode.IsDigit(lexer.peek(1))) && lexer.expectsOperand():
+			lexer.pos++
+			start := lexer.pos
+			for isRubyIdentRune(lexer.peek(0)) {
+				lexer.pos++
+			}
+			if r := lexer.peek(0); r == '?' || r == '!' || r == '=' {
+				lexer.pos++
+			}
+			lexer.emit(fastfileTokenSymbol, string(lexer.src[start:lexer.pos]))
+		case isRubyIdentRune(r) || r == '@' || r == '$':
+			start := lexer.pos
+			lexer.pos++
+			for isRubyIdentRune(lexer.peek(0)) {
+				lexer.pos++
+			}
+			if r := lexer.peek(0); (r == '?' || r == '!') && lexer.peek(1) != '=' {
+				lexer.pos++
+			}
+			ident := string(lexer.src[start:lexer.pos])
+			if lexer.peek(0) == ':' && lexer.peek(1) != ':' {
+				lexer.pos++
+				lexer.emit(fastfileTokenLabel, ident)
+				continue
+			}
+			lexer.emit(fastfileTokenIdent, ident)
+		default:
+			lexer.pos++
+			switch r {
+			case '(', '[':
+				lexer.parenDepth++
+			case ')', ']':
+				if lexer.parenDepth > 0 {
+					lexer.parenDepth--
+				}
+			}
+			lexer.emit(fastfileTokenPunct, string(r))
+		}
+	}
+	return lexer.tokens
+}
+
+func tokenizeFastfile(content string) []fastfileToken {
+	lexer := fastfileLexer{src: []rune(content)}
+	return lexer.tokenize()
+}
+
+//
+// Fastfile parser
+//
+
+type fastfileScopeKind int
+
+const (
+	fastfileScopeBlock fastfileScopeKind = iota
+	fastfileScopePlatform
+	fastfileScopeLane
+)
+
+type fastfileScope struct {
+	kind     fastfileScopeKind
+	platform string
+	// braced scopes are closed by }, others by end
+	braced bool
+}
+
+// rubyBlockKeywords open a block closed by end, if they start a statement
+var rubyBlockKeywords = map[string]bool{
+	"if": true, "unless": true, "while": true, "until": true, "for": true,
+	"case": true, "begin": true, "def": true, "class": true, "module": true,
+}
+
+// rubyLoopKeywords may be followed by an optional do, which does not open a new block
+var rubyLoopKeywords = map[string]bool{
+	"while": true, "until": true, "for": true,
+}
+
+type fastfileParser struct {
+	tokens []fastfileToken
+	pos    int
+
+	scopes      []fastfileScope
+	description []string
+
+	lanes   []FastlaneLaneModel
+	imports []string
+	// actions are the methods called at the start of a statement, like: match, gym
+	actions         map[string]bool
+	defaultPlatform string
+	// gitImports are the import_from_git urls (or the raw call, if the url can not be determined)
+	gitImports []string
+}
+
+func (parser *fastfileParser) platform() string {
+	for i := len(parser.scopes) - 1; i >= 0; i-- {
+		if parser.scopes[i].kind == fastfileScopePlatform {
+			return parser.scopes[i].platform
+		}
+	}
+	return ""
+}
+
+func (parser *fastfileParser) inLane() bool {
+	for _, scope := range parser.scopes {
+		if scope.kind == fastfileScopeLane {
+			return true
+		}
+	}
+	return false
+}
+
+// closeUnclosedScopes drops the scopes from the outermost scope of the given kinds, used to recover from
+// lane and platform blocks missing their end: lanes and platforms do not nest, so a new lane or platform
+// definition in a lane closes the previous one.
+func (parser *fastfileParser) closeUnclosedScopes(kinds ...fastfileScopeKind) {
+	for i, scope := range parser.scopes {
+		for _, kind := range kinds {
+			if scope.kind == kind {
+				parser.scopes = parser.scopes[:i]
+				return
+			}
+		}
+	}
+}
+
+func (parser *fastfileParser) popScope(braced bool) {
+	for i := len(parser.scopes) - 1; i >= 0; i-- {
+		if parser.scopes[i].braced == braced {
+			parser.scopes = parser.scopes[:i]
+			return
+		}
+	}
+}
+
+// statement returns the tokens of the statement starting at the current position.
+func (parser *fastfileParser) statement() []fastfileToken {
+	end := parser.pos
+	for end < len(parser.tokens) && parser.tokens[end].kind != fastfileTokenNewline {
+		end++
+	}
+	return parser.tokens[parser.pos:end]
+}
+
+// firstOfKind returns the first token of the given kind, skipping the opening parenthesis.
+func firstOfKind(tokens []fastfileToken, kind fastfileTokenKind) (fastfileToken, bool) {
+	for _, token := range tokens {
+		if token.kind == kind {
+			return token, true
+		}
+		if token.kind != fastfileTokenPunct || token.value != "(" {
+			return fastfileToken{}, false
+		}
+	}
+	return fastfileToken{}, false
+}
+
+// opensDoBlock returns true if the statement opens a do block, like: lane :beta do |options|
+func opensDoBlock(tokens []fastfileToken) bool {
+	for _, token := range tokens {
+		if token.kind == fastfileTokenIdent && token.value == "do" {
+			return true
+		}
+	}
+	return false
+}
+
+// stringsOf returns the string arguments of a call, like: desc "first" "second"
+func stringsOf(tokens []fastfileToken) []string {
+	values := []string{}
+	for _, token := range tokens {
+		if token.kind == fastfileTokenString {
+			values = append(values, token.value)
+		}
+	}
+	return values
+}
+
+// parseStatementHead handles the fastlane DSL calls at the start of a statement,
+// it returns the scope kind to open if the statement opens a do block.
+func (parser *fastfileParser) parseStatementHead() (fastfileScopeKind, string) {
+	statement := parser.statement()
+	if len(statement) == 0 || statement[0].kind != fastfileTokenIdent {
+		return fastfileScopeBlock, ""
+	}
+	args := statement[1:]
+
+	parser.actions[statement[0].value] = true
+
+	switch statement[0].value {
+	case "default_platform":
+		if symbol, ok := firstOfKind(args, fastfileTokenSymbol); ok {
+			parser.defaultPlatform = symbol.value
+		}
+	case "desc":
+		parser.description = append(parser.description, stringsOf(args)...)
+	case "platform":
+		symbol, ok := firstOfKind(args, fastfileTokenSymbol)
+		if !ok {
+			break
+		}
+		if parser.inLane() {
+			if !opensDoBlock(args) {
+				break
+			}
+			parser.closeUnclosedScopes(fastfileScopePlatform, fastfileScopeLane)
+		}
+		return fastfileScopePlatform, symbol.value
+	case "lane", "override_lane", "private_lane":
+		symbol, ok := firstOfKind(args, fastfileTokenSymbol)
+		if !ok {
+			break
+		}
+		if parser.inLane() {
+			if !opensDoBlock(args) {
+				break
+			}
+			parser.closeUnclosedScopes(fastfileScopeLane)
+		}
+		if statement[0].value != "private_lane" {
+			parser.lanes = append(parser.lanes, FastlaneLaneModel{
+				Name:        symbol.value,
+				Platform:    parser.platform(),
+				Description: strings.Join(parser.description, " "),
+			})
+		}
+		parser.description = nil
+		return fastfileScopeLane, ""
+	case "import":
+		if pth, ok := firstOfKind(args, fastfileTokenString); ok && !parser.inLane() {
+			parser.imports = append(parser.imports, pth.value)
+		}
+	case "import_from_git":
+		if parser.inLane() {
+			break
+		}
+		url := ""
+		for i, token := range args {
+			if token.kind == fastfileTokenLabel && token.value == "url" && i+1 < len(args) && args[i+1].kind == fastfileTokenString {
+				url = args[i+1].value
+			}
 		}
+		if url == "" {
+			url = "import_from_git"
+		}
+		parser.gitImports = append(parser.gitImports, url)
+	}
+
+	return fastfileScopeBlock, ""
+}
 
-		if match := laneRegexp.FindStringSubmatch(line); len(match) == 2 {
-			lane := match[1]
+func (parser *fastfileParser) parse() {
+	statementStart := true
+	headKind, headPlatform := fastfileScopeBlock, ""
+	loopPendingDo := false
+
+	for ; parser.pos < len(parser.tokens); parser.pos++ {
+		token := parser.tokens[parser.pos]
+
+		previousIsDot := parser.pos > 0 && parser.tokens[parser.pos-1].kind == fastfileTokenPunct &&
+			(parser.tokens[parser.pos-1].value == "." || parser.tokens[parser.pos-1].value == "&.")
+
+		if statementStart {
+			headKind, headPlatform = parser.parseStatementHead()
+			loopPendingDo = false
+		}
+		wasStatementStart := statementStart
+		statementStart = false
 
-			if platform != "" {
-				lanes, found := laneMap[platform]
-				if !found {
-					lanes = []string{}
+		switch token.kind {
+		case fastfileTokenNewline:
+			statementStart = true
+		case fastfileTokenPunct:
+			switch token.value {
+			case "{":
+				parser.scopes = append(parser.scopes, fastfileScope{kind: fastfileScopeBlock, braced: true})
+				statementStart = true
+			case "}":
+				parser.popScope(true)
+			case "=":
+				// a block keyword may start the right hand side, like: x = if ...
+				if next := parser.pos + 1; next < len(parser.tokens) && parser.tokens[next].kind == fastfileTokenIdent && rubyBlockKeywords[parser.tokens[next].value] {
+					parser.scopes = append(parser.scopes, fastfileScope{kind: fastfileScopeBlock})
+					parser.pos++
 				}
-				lanes = append(lanes, lane)
-				laneMap[platform] = lanes
-			} else {
-				commonLanes = append(commonLanes, lane)
+			}
+		case fastfileTokenIdent:
+			if previousIsDot {
+				break
+			}
+			switch {
+			case token.value == "do":
+				if loopPendingDo {
+					loopPendingDo = false
+					break
+				}
+				kind, platform := headKind, headPlatform
+				headKind, headPlatform = fastfileScopeBlock, ""
+				parser.scopes = append(parser.scopes, fastfileScope{kind: kind, platform: platform})
+				statementStart = true
+			case token.value == "end":
+				parser.popScope(false)
+			case wasStatementStart && rubyBlockKeywords[token.value]:
+				parser.scopes = append(parser.scopes, fastfileScope{kind: fastfileScopeBlock})
+				loopPendingDo = rubyLoopKeywords[token.value]
+			case token.value == "then" || token.value == "else" || token.value == "elsif" || token.value == "begin" ||
+				token.value == "rescue" || token.value == "ensure" || token.value == "when":
+				if token.value == "begin" {
+					parser.scopes = append(parser.scopes, fastfileScope{kind: fastfileScopeBlock})
+				}
+				statementStart = token.value != "elsif" && token.value != "when"
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return []string{}, err
+}
+
+func parseFastfileContent(content string) fastfileParser {
+	parser := fastfileParser{tokens: tokenizeFastfile(content), actions: map[string]bool{}}
+	parser.parse()
+	return parser
+}
+
+// sortFastfileLanes lists the common lanes first, then the platform lanes in order of appearance.
+func sortFastfileLanes(lanes []FastlaneLaneModel) []FastlaneLaneModel {
+	sorted := []FastlaneLaneModel{}
+	for _, lane := range lanes {
+		if lane.Platform == "" {
+			sorted = append(sorted, lane)
+		}
+	}
+	for _, lane := range lanes {
+		if lane.Platform != "" {
+			sorted = append(sorted, lane)
+		}
+	}
+	return sorted
+}
+
+func inspectFastfileContent(content string) ([]FastlaneLaneModel, error) {
+	parser := parseFastfileContent(content)
+	return sortFastfileLanes(parser.lanes), nil
+}
+
+// inspectFastfile inspects the given Fastfile and the Fastfiles it imports into the model,
+// visited is used to break import cycles.
+func inspectFastfile(fastfile string, visited map[string]bool, model *FastfileModel) error {
+	absFastfile, err := pathutil.AbsPath(fastfile)
+	if err != nil {
+		return err
+	}
+	if visited[absFastfile] {
+		return nil
+	}
+	visited[absFastfile] = true
+
+	content, err := fileutil.ReadStringFromFile(fastfile)
+	if err != nil {
+		return err
+	}
+
+	parser := parseFastfileContent(content)
+	model.Lanes = append(model.Lanes, parser.lanes...)
+	model.GitImports = append(model.GitImports, parser.gitImports...)
+	if model.DefaultPlatform == "" {
+		model.DefaultPlatform = parser.defaultPlatform
+	}
+	for action := range parser.actions {
+		model.Actions[action] = true
+	}
+
+	for _, importPth := range parser.imports {
+		// fastlane resolves the imported paths relative to the importing Fastfile's dir
+		pth := importPth
+		if !filepath.IsAbs(pth) {
+			pth = filepath.Join(filepath.Dir(fastfile), importPth)
+		}
+
+		// an unresolvable import only loses its own lanes
+		if exist, err := pathutil.IsPathExists(pth); err != nil {
+			log.Warnft("Failed to check if the imported Fastfile (%s) exists, skipping it, error: %s", importPth, err)
+			continue
+		} else if !exist {
+			log.Warnft("Imported Fastfile (%s) of (%s) does not exist, skipping it", importPth, fastfile)
+			continue
+		}
+
+		if err := inspectFastfile(pth, visited, model); err != nil {
+			log.Warnft("Failed to inspect the imported Fastfile (%s), skipping it, error: %s", importPth, err)
+		}
+	}
+
+	return nil
+}
+
+// InspectFastfile returns the public lanes (with their platform and description) defined in the Fastfile
+// and in the local Fastfiles it imports.
+func InspectFastfile(fastFile string) (FastfileModel, error) {
+	model := FastfileModel{
+		Lanes:      []FastlaneLaneModel{},
+		GitImports: []string{},
+		Actions:    map[string]bool{},
+	}
+	if err := inspectFastfile(fastFile, map[string]bool{}, &model); err != nil {
+		return FastfileModel{}, err
 	}
+	model.Lanes = sortFastfileLanes(model.Lanes)
 
-	lanes := commonLanes
-	for platform, platformLanes := range laneMap {
-		for _, lane := range platformLanes {
-			lanes = append(lanes, platform+" "+lane)
+	return model, nil
+}
+
+// Platforms returns the platforms targeted by the lanes, lanes without platform block
+// target the default platform.
+func (model FastfileModel) Platforms() []string {
+	platforms := []string{}
+	seen := map[string]bool{}
+	for _, lane := range model.Lanes {
+		platform := lane.Platform
+		if platform == "" {
+			platform = model.DefaultPlatform
+		}
+		if platform != "" && !seen[platform] {
+			seen[platform] = true
+			platforms = append(platforms, platform)
 		}
 	}
+	return platforms
+}
 
-	return lanes, nil
+//
+// fastlane environment
+//
+
+const (
+	gemfileBasePath     = "Gemfile"
+	gemfileLockBasePath = "Gemfile.lock"
+	pluginfileBasePath  = "Pluginfile"
+	matchfileBasePath   = "Matchfile"
+	appfileBasePath     = "Appfile"
+
+	fastlanePluginGemPrefix = "fastlane-plugin-"
+)
+
+// FastlaneEnvironmentModel describes the files configuring how fastlane runs.
+type FastlaneEnvironmentModel struct {
+	// GemfilePath is set if the work dir's Gemfile lists fastlane, in this case fastlane runs with bundle exec.
+	GemfilePath     string
+	GemfileLockPath string
+	// FastlaneVersion is the fastlane version locked in Gemfile.lock.
+	FastlaneVersion string
+
+	PluginfilePath string
+	Plugins        []string
+	// PluginfileLoaded is true, if the Gemfile loads the Pluginfile (eval_gemfile).
+	PluginfileLoaded bool
+
+	MatchfilePath string
+	// UsesMatch is true, if a Matchfile exists or the lanes call match.
+	UsesMatch bool
+	// MatchStorageMode is the storage_mode of the Matchfile, git if not specified.
+	MatchStorageMode string
+
+	AppIdentifier string
+	TeamID        string
+	PackageName   string
 }
 
-// InspectFastfile ...
-func InspectFastfile(fastFile string) ([]string, error) {
-	content, err := fileutil.ReadStringFromFile(fastFile)
+// fastlaneDSLCalls returns the first string argument of the given method calls (at the start of a statement),
+// like: app_identifier("io.bitrise.sample") in an Appfile.
+func fastlaneDSLCalls(content string, methods ...string) map[string][]string {
+	wanted := map[string]bool{}
+	for _, method := range methods {
+		wanted[method] = true
+	}
+
+	calls := map[string][]string{}
+	tokens := tokenizeFastfile(content)
+	statementStart := true
+	for i, token := range tokens {
+		if token.kind == fastfileTokenNewline {
+			statementStart = true
+			continue
+		}
+		if !statementStart {
+			continue
+		}
+		statementStart = false
+
+		if token.kind != fastfileTokenIdent || !wanted[token.value] {
+			continue
+		}
+
+		value := ""
+		for j := i + 1; j < len(tokens) && tokens[j].kind != fastfileTokenNewline; j++ {
+			if tokens[j].kind == fastfileTokenString || tokens[j].kind == fastfileTokenSymbol {
+				value = tokens[j].value
+				break
+			}
+		}
+		calls[token.value] = append(calls[token.value], value)
+	}
+	return calls
+}
+
+func firstCallValue(calls map[string][]string, method string) string {
+	if values := calls[method]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func readFileInDir(dir, baseName string) (string, string, error) {
+	pth, err := existingFileInDir(dir, baseName)
+	if err != nil || pth == "" {
+		return "", "", err
+	}
+	content, err := fileutil.ReadStringFromFile(pth)
 	if err != nil {
-		return []string{}, err
+		return "", "", err
+	}
+	return pth, content, nil
+}
+
+// DetectFastlaneEnvironment inspects the Gemfile (in the work dir) and the Pluginfile, Matchfile and Appfile
+// (next to the Fastfile) of a fastlane setup, the returned paths are absolute.
+func DetectFastlaneEnvironment(fastfilePth string, fastfile FastfileModel) (FastlaneEnvironmentModel, error) {
+	fastlaneDir := filepath.Dir(fastfilePth)
+	workDir := FastlaneWorkDir(fastfilePth)
+
+	environment := FastlaneEnvironmentModel{
+		Plugins:   []string{},
+		UsesMatch: fastfile.Actions["match"] || fastfile.Actions["sync_code_signing"],
+	}
+
+	// Gemfile
+	gemfilePth, gemfileContent, err := readFileInDir(workDir, gemfileBasePath)
+	if err != nil {
+		return FastlaneEnvironmentModel{}, err
+	}
+	if gemfilePth != "" {
+		for _, gem := range fastlaneDSLCalls(gemfileContent, "gem")["gem"] {
+			if gem == "fastlane" {
+				environment.GemfilePath = gemfilePth
+			}
+		}
+		// fastlane generates: plugins_path = File.join(File.dirname(__FILE__), 'fastlane', 'Pluginfile')
+		// eval_gemfile(plugins_path) if File.exist?(plugins_path)
+		if strings.Contains(gemfileContent, "eval_gemfile") && strings.Contains(gemfileContent, pluginfileBasePath) {
+			environment.PluginfileLoaded = true
+		}
+	}
+
+	if environment.GemfilePath != "" {
+		gemfileLockPth, err := existingFileInDir(workDir, gemfileLockBasePath)
+		if err != nil {
+			return FastlaneEnvironmentModel{}, err
+		}
+		if gemfileLockPth != "" {
+			environment.GemfileLockPath = gemfileLockPth
+
+			version, err := GemVersionFromGemfileLock("fastlane", gemfileLockPth)
+			if err != nil {
+				return FastlaneEnvironmentModel{}, err
+			}
+			environment.FastlaneVersion = version
+		}
+	}
+
+	// Pluginfile
+	pluginfilePth, pluginfileContent, err := readFileInDir(fastlaneDir, pluginfileBasePath)
+	if err != nil {
+		return FastlaneEnvironmentModel{}, err
+	}
+	if pluginfilePth != "" {
+		environment.PluginfilePath = pluginfilePth
+		for _, gem := range fastlaneDSLCalls(pluginfileContent, "gem")["gem"] {
+			if gem != "" {
+				environment.Plugins = append(environment.Plugins, strings.TrimPrefix(gem, fastlanePluginGemPrefix))
+			}
+		}
+	}
+
+	// Matchfile
+	matchfilePth, matchfileContent, err := readFileInDir(fastlaneDir, matchfileBasePath)
+	if err != nil {
+		return FastlaneEnvironmentModel{}, err
+	}
+	if matchfilePth != "" {
+		environment.MatchfilePath = matchfilePth
+		environment.UsesMatch = true
+		environment.MatchStorageMode = firstCallValue(fastlaneDSLCalls(matchfileContent, "storage_mode"), "storage_mode")
+	}
+	if environment.UsesMatch && environment.MatchStorageMode == "" {
+		environment.MatchStorageMode = "git"
+	}
+
+	// Appfile
+	_, appfileContent, err := readFileInDir(fastlaneDir, appfileBasePath)
+	if err != nil {
+		return FastlaneEnvironmentModel{}, err
+	}
+	if appfileContent != "" {
+		calls := fastlaneDSLCalls(appfileContent, "app_identifier", "team_id", "package_name")
+		environment.AppIdentifier = firstCallValue(calls, "app_identifier")
+		environment.TeamID = firstCallValue(calls, "team_id")
+		environment.PackageName = firstCallValue(calls, "package_name")
 	}
 
-	return inspectFastfileContent(content)
+	return environment, nil
 }
 
 // FastlaneWorkDir ...
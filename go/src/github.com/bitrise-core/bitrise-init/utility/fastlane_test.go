@@ -1,9 +1,12 @@
 package utility
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,6 +43,14 @@ func TestFilterFastFiles(t *testing.T) {
 	}
 }
 
+func laneNames(lanes []FastlaneLaneModel) []string {
+	names := []string{}
+	for _, lane := range lanes {
+		names = append(names, lane.String())
+	}
+	return names
+}
+
 func TestInspectFastFileContent(t *testing.T) {
 	lines := []string{
 		" test ",
@@ -63,15 +74,15 @@ func TestInspectFastFileContent(t *testing.T) {
 
 	lanes, err := inspectFastfileContent(content)
 	require.NoError(t, err)
-	require.Equal(t, expectedLanes, lanes)
+	require.Equal(t, expectedLanes, laneNames(lanes))
 
 	t.Log("ios test")
 	{
 		lanes, err := inspectFastfileContent(iosTesFastfileContent)
 		require.NoError(t, err)
 
-		expectedLanes := []string{
-			"ios test",
+		expectedLanes := []FastlaneLaneModel{
+			{Name: "test", Platform: "ios", Description: "Runs all tests, archives app"},
 		}
 
 		require.Equal(t, expectedLanes, lanes)
@@ -100,7 +111,131 @@ func TestInspectFastFileContent(t *testing.T) {
 			"ios dev",
 		}
 
-		require.Equal(t, expectedLanes, lanes)
+		require.Equal(t, expectedLanes, laneNames(lanes))
+		require.Equal(t, "Submit a new **Wikipedia Beta** build to Apple TestFlight for internal testing.", lanes[12].Description)
+	}
+
+	t.Log("private lanes, descriptions and indented platform end")
+	{
+		lanes, err := inspectFastfileContent(`default_platform(:ios)
+
+desc "Common lane"
+lane :lint do |options|
+  if options[:strict] then swiftlint(strict: true) end
+  sh("echo 'lane :not_a_lane do'") # lane :commented do
+end
+
+  platform :ios do
+    desc "Submit a new build to TestFlight"
+    desc "Uses match"
+    lane :beta do
+      increment_build_number unless is_ci?
+      changelog = <<~EOS
+        lane :in_heredoc do
+        end
+      EOS
+      [1, 2].each { |i| puts i }
+      pilot(changelog: changelog)
+    end
+
+    desc "Only used by other lanes"
+    private_lane :notify do
+      while false do end
+    end
+
+    lane :release do
+    end
+  end
+
+platform :android do
+  lane(:deploy) do
+    version = if ENV["RELEASE"]
+      "1.0"
+    else
+      "1.0-beta"
+    end
+    gradle(task: "assemble", properties: {"versionName" => version})
+  end
+end
+`)
+		require.NoError(t, err)
+		require.Equal(t, []FastlaneLaneModel{
+			{Name: "lint", Description: "Common lane"},
+			{Name: "beta", Platform: "ios", Description: "Submit a new build to TestFlight Uses match"},
+			{Name: "release", Platform: "ios"},
+			{Name: "deploy", Platform: "android"},
+		}, lanes)
+	}
+}
+
+func TestInspectFastFileContentUnclosedBlocks(t *testing.T) {
+	lanes, err := inspectFastfileContent(`platform :ios do
+  lane :beta do
+    if is_ci?
+      setup_ci
+  lane :release do
+    gym
+platform :android do
+  lane :deploy do
+    gradle(task: "assemble")
+  end
+end
+`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ios beta", "ios release", "android deploy"}, laneNames(lanes))
+}
+
+func TestInspectFastfile(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__fastlane__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	createTestFiles(t, tmpDir, map[string]string{
+		"fastlane/Fastfile": `import "../shared/Fastfile"
+import_from_git(url: "https://github.com/fastlane/fastlane", path: "fastlane/Fastfile")
+
+lane :test do
+end
+`,
+		"shared/Fastfile": `import "../fastlane/Fastfile"
+
+platform :ios do
+  desc "Shared beta lane"
+  lane :beta do
+  end
+end
+`,
+	})
+
+	fastfile, err := InspectFastfile(filepath.Join(tmpDir, "fastlane", "Fastfile"))
+	require.NoError(t, err)
+	require.Equal(t, []FastlaneLaneModel{
+		{Name: "test"},
+		{Name: "beta", Platform: "ios", Description: "Shared beta lane"},
+	}, fastfile.Lanes)
+	require.Equal(t, []string{"https://github.com/fastlane/fastlane"}, fastfile.GitImports)
+	require.Equal(t, []string{"ios"}, fastfile.Platforms())
+
+	t.Log("missing import")
+	{
+		createTestFiles(t, tmpDir, map[string]string{
+			"missing/Fastfile": `import "Other"
+import "../shared/Fastfile"
+
+lane :build do
+end
+`,
+		})
+
+		fastfile, err := InspectFastfile(filepath.Join(tmpDir, "missing", "Fastfile"))
+		require.NoError(t, err)
+		require.Equal(t, []FastlaneLaneModel{
+			{Name: "build"},
+			{Name: "test"},
+			{Name: "beta", Platform: "ios", Description: "Shared beta lane"},
+		}, fastfile.Lanes)
 	}
 }
 
@@ -147,3 +282,101 @@ func TestFastlaneWorkDir(t *testing.T) {
 		require.Equal(t, expected, actual)
 	}
 }
+
+func TestDetectFastlaneEnvironment(t *testing.T) {
+	t.Log("bundler, plugins, match and Appfile")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__fastlane__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"Gemfile": `source "https://rubygems.org"
+
+gem "fastlane"
+
+plugins_path = File.join(File.dirname(__FILE__), 'fastlane', 'Pluginfile')
+eval_gemfile(plugins_path) if File.exist?(plugins_path)
+`,
+			"Gemfile.lock": `GEM
+  remote: https://rubygems.org/
+  specs:
+    fastlane (2.100.1)
+      CFPropertyList (>= 2.3, < 4.0.0)
+    fastlane-plugin-versioning (0.3.4)
+
+PLATFORMS
+  ruby
+`,
+			"fastlane/Pluginfile": `# Autogenerated by fastlane
+gem 'fastlane-plugin-versioning'
+gem "fastlane-plugin-badge", "~> 1.0"
+`,
+			"fastlane/Matchfile": `git_url("git@github.com:bitrise-io/certificates.git")
+type("development") # The default type, can be: appstore, adhoc, enterprise or development
+`,
+			"fastlane/Appfile": `app_identifier "io.bitrise.sample" # The bundle identifier of your app
+apple_id "dev@bitrise.io"
+team_id("72SA8V3WYL")
+`,
+			"fastlane/Fastfile": `lane :beta do
+end
+`,
+		})
+
+		fastfilePth := filepath.Join(tmpDir, "fastlane", "Fastfile")
+		fastfile, err := InspectFastfile(fastfilePth)
+		require.NoError(t, err)
+
+		environment, err := DetectFastlaneEnvironment(fastfilePth, fastfile)
+		require.NoError(t, err)
+		require.Equal(t, FastlaneEnvironmentModel{
+			GemfilePath:      filepath.Join(tmpDir, "Gemfile"),
+			GemfileLockPath:  filepath.Join(tmpDir, "Gemfile.lock"),
+			FastlaneVersion:  "2.100.1",
+			PluginfilePath:   filepath.Join(tmpDir, "fastlane", "Pluginfile"),
+			Plugins:          []string{"versioning", "badge"},
+			PluginfileLoaded: true,
+			MatchfilePath:    filepath.Join(tmpDir, "fastlane", "Matchfile"),
+			UsesMatch:        true,
+			MatchStorageMode: "git",
+			AppIdentifier:    "io.bitrise.sample",
+			TeamID:           "72SA8V3WYL",
+		}, environment)
+	}
+
+	t.Log("android lanes without Gemfile")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__fastlane__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"fastlane/Appfile": `json_key_file("key.json")
+package_name("io.bitrise.android")
+`,
+			"fastlane/Fastfile": `default_platform(:android)
+
+lane :deploy do
+  gradle(task: "assembleRelease")
+end
+`,
+		})
+
+		fastfilePth := filepath.Join(tmpDir, "fastlane", "Fastfile")
+		fastfile, err := InspectFastfile(fastfilePth)
+		require.NoError(t, err)
+		require.Equal(t, []string{"android"}, fastfile.Platforms())
+
+		environment, err := DetectFastlaneEnvironment(fastfilePth, fastfile)
+		require.NoError(t, err)
+		require.Equal(t, FastlaneEnvironmentModel{
+			Plugins:     []string{},
+			PackageName: "io.bitrise.android",
+		}, environment)
+	}
+}
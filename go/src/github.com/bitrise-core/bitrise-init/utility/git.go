@@ -0,0 +1,241 @@
+package utility
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	gitDirFilePrefix          = "gitdir: "
+	gitHeadRefPrefix          = "ref: "
+	gitLocalBranchRefPrefix   = "refs/heads/"
+	gitOriginBranchRefPrefix  = "refs/remotes/origin/"
+	gitOriginHEADRelativePath = "refs/remotes/origin/HEAD"
+)
+
+// releaseBranchPrefixes are the branch name prefixes of the release branches.
+var releaseBranchPrefixes = []string{"release", "releases"}
+
+// GitDir returns the git dir of the repository containing the dir.
+// In a worktree or a submodule .git is a file, pointing to the git dir (like: gitdir: ../.git/modules/app),
+// which is followed, instead of looking for the git dir of a parent repository.
+func GitDir(dir string) (string, error) {
+	absDir, err := pathutil.AbsPath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		gitPth := filepath.Join(absDir, ".git")
+		info, err := os.Stat(gitPth)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if err == nil {
+			if info.IsDir() {
+				return gitPth, nil
+			}
+			return readGitDirFile(gitPth)
+		}
+
+		parentDir := filepath.Dir(absDir)
+		if parentDir == absDir {
+			return "", fmt.Errorf("not a git repository (or any of the parent directories): %s", dir)
+		}
+		absDir = parentDir
+	}
+}
+
+// readGitDirFile returns the git dir the .git file points to, relative paths are relative to the .git file's dir.
+func readGitDirFile(pth string) (string, error) {
+	content, err := fileutil.ReadStringFromFile(pth)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(strings.SplitN(content, "\n", 2)[0])
+	if !strings.HasPrefix(line, gitDirFilePrefix) {
+		return "", fmt.Errorf("invalid .git file (%s), %s prefix not found", pth, strings.TrimSpace(gitDirFilePrefix))
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, gitDirFilePrefix))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(pth), gitDir)
+	}
+
+	if exist, err := pathutil.IsDirExists(gitDir); err != nil {
+		return "", err
+	} else if !exist {
+		return "", fmt.Errorf("git dir (%s) referenced by %s does not exist", gitDir, pth)
+	}
+	return gitDir, nil
+}
+
+// gitCommonDir returns the dir holding the refs: the main repository's git dir for a worktree's git dir
+// (the commondir file points to it), otherwise the git dir itself.
+func gitCommonDir(gitDir string) (string, error) {
+	commonDirPth := filepath.Join(gitDir, "commondir")
+	if exist, err := pathutil.IsPathExists(commonDirPth); err != nil {
+		return "", err
+	} else if !exist {
+		return gitDir, nil
+	}
+
+	content, err := fileutil.ReadStringFromFile(commonDirPth)
+	if err != nil {
+		return "", err
+	}
+
+	commonDir := strings.TrimSpace(content)
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return commonDir, nil
+}
+
+// GitDefaultBranch returns the default branch of the repository: the branch of origin's HEAD, or the checked out branch.
+func GitDefaultBranch(gitDir string) (string, error) {
+	commonDir, err := gitCommonDir(gitDir)
+	if err != nil {
+		return "", err
+	}
+
+	if branch, err := symbolicRefBranch(filepath.Join(commonDir, gitOriginHEADRelativePath), gitOriginBranchRefPrefix); err != nil {
+		return "", err
+	} else if branch != "" {
+		return branch, nil
+	}
+
+	branch, err := symbolicRefBranch(filepath.Join(gitDir, "HEAD"), gitLocalBranchRefPrefix)
+	if err != nil {
+		return "", err
+	}
+	if branch == "" {
+		return "", fmt.Errorf("HEAD is not a branch (detached HEAD)")
+	}
+	return branch, nil
+}
+
+// symbolicRefBranch returns the branch referenced by the symbolic ref file, if it exists and references a branch (like: ref: refs/heads/master).
+func symbolicRefBranch(pth, branchRefPrefix string) (string, error) {
+	if exist, err := pathutil.IsPathExists(pth); err != nil {
+		return "", err
+	} else if !exist {
+		return "", nil
+	}
+
+	content, err := fileutil.ReadStringFromFile(pth)
+	if err != nil {
+		return "", err
+	}
+
+	ref := strings.TrimSpace(content)
+	if !strings.HasPrefix(ref, gitHeadRefPrefix+branchRefPrefix) {
+		return "", nil
+	}
+	return strings.TrimPrefix(ref, gitHeadRefPrefix+branchRefPrefix), nil
+}
+
+// GitBranches returns the sorted names of the local and the origin branches, based on the loose and the packed refs.
+func GitBranches(gitDir string) ([]string, error) {
+	commonDir, err := gitCommonDir(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	gitDir = commonDir
+
+	branchMap := map[string]bool{}
+
+	for _, refPrefix := range []string{gitLocalBranchRefPrefix, gitOriginBranchRefPrefix} {
+		refsDir := filepath.Join(gitDir, filepath.FromSlash(refPrefix))
+		if exist, err := pathutil.IsDirExists(refsDir); err != nil {
+			return nil, err
+		} else if !exist {
+			continue
+		}
+
+		if err := filepath.Walk(refsDir, func(pth string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(refsDir, pth)
+			if err != nil {
+				return err
+			}
+			branchMap[filepath.ToSlash(rel)] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	packedRefsPth := filepath.Join(gitDir, "packed-refs")
+	if exist, err := pathutil.IsPathExists(packedRefsPth); err != nil {
+		return nil, err
+	} else if exist {
+		content, err := fileutil.ReadStringFromFile(packedRefsPth)
+		if err != nil {
+			return nil, err
+		}
+
+		// <sha> <ref> lines, the peeled tags (^<sha>) and the comments (# ...) are skipped
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+
+			for _, refPrefix := range []string{gitLocalBranchRefPrefix, gitOriginBranchRefPrefix} {
+				if strings.HasPrefix(fields[1], refPrefix) {
+					branchMap[strings.TrimPrefix(fields[1], refPrefix)] = true
+				}
+			}
+		}
+	}
+
+	delete(branchMap, "HEAD")
+
+	branches := []string{}
+	for branch := range branchMap {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	return branches, nil
+}
+
+// ReleaseBranchPatterns returns the sorted trigger map patterns of the release branches,
+// like: release/* for release/1.0 and release-* for release-2.0.
+func ReleaseBranchPatterns(branches []string) []string {
+	patternMap := map[string]bool{}
+	for _, branch := range branches {
+		for _, prefix := range releaseBranchPrefixes {
+			if !strings.HasPrefix(branch, prefix) || len(branch) <= len(prefix)+1 {
+				continue
+			}
+
+			separator := branch[len(prefix)]
+			if separator == '/' || separator == '-' || separator == '_' {
+				patternMap[branch[:len(prefix)+1]+"*"] = true
+			}
+		}
+	}
+
+	patterns := []string{}
+	for pattern := range patternMap {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	return patterns
+}
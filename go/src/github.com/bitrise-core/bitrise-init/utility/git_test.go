@@ -0,0 +1,168 @@
+package utility
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitDir(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("git")
+	require.NoError(t, err)
+
+	createTestFiles(t, tmpDir, map[string]string{
+		".git/HEAD":          "ref: refs/heads/master\n",
+		"android/app/.empty": "",
+	})
+
+	t.Log("repository root")
+	{
+		gitDir, err := GitDir(tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(tmpDir, ".git"), gitDir)
+	}
+
+	t.Log("sub dir of the repository")
+	{
+		gitDir, err := GitDir(filepath.Join(tmpDir, "android", "app"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(tmpDir, ".git"), gitDir)
+	}
+
+	t.Log("submodule")
+	{
+		createTestFiles(t, tmpDir, map[string]string{
+			".git/modules/ios/HEAD": "ref: refs/heads/develop\n",
+			"ios/.git":              "gitdir: ../.git/modules/ios\n",
+			"ios/App/.empty":        "",
+		})
+
+		gitDir, err := GitDir(filepath.Join(tmpDir, "ios", "App"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(tmpDir, ".git", "modules", "ios"), gitDir)
+	}
+
+	t.Log("worktree")
+	{
+		createTestFiles(t, tmpDir, map[string]string{
+			".git/refs/remotes/origin/HEAD":    "ref: refs/remotes/origin/main\n",
+			".git/refs/remotes/origin/main":    "2b4a6d8e\n",
+			".git/refs/heads/release/1.0":      "2b4a6d8e\n",
+			".git/worktrees/feature/HEAD":      "ref: refs/heads/feature\n",
+			".git/worktrees/feature/commondir": "../..\n",
+			"worktrees/feature/.git":           "gitdir: " + filepath.Join(tmpDir, ".git", "worktrees", "feature") + "\n",
+			"worktrees/feature/android/.empty": "",
+		})
+
+		gitDir, err := GitDir(filepath.Join(tmpDir, "worktrees", "feature", "android"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(tmpDir, ".git", "worktrees", "feature"), gitDir)
+
+		// the refs are read from the main repository's git dir
+		branch, err := GitDefaultBranch(gitDir)
+		require.NoError(t, err)
+		require.Equal(t, "main", branch)
+
+		branches, err := GitBranches(gitDir)
+		require.NoError(t, err)
+		require.Equal(t, []string{"main", "release/1.0"}, branches)
+	}
+
+	t.Log("invalid .git file")
+	{
+		createTestFiles(t, tmpDir, map[string]string{
+			"broken/.git":     "gitdir: ../.git/modules/missing\n",
+			"not-a-link/.git": "[core]\n",
+		})
+
+		_, err := GitDir(filepath.Join(tmpDir, "broken"))
+		require.Error(t, err)
+
+		_, err = GitDir(filepath.Join(tmpDir, "not-a-link"))
+		require.Error(t, err)
+	}
+}
+
+func TestGitDefaultBranch(t *testing.T) {
+	t.Log("origin HEAD")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("git")
+		require.NoError(t, err)
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"HEAD":                      "ref: refs/heads/feature/login\n",
+			"refs/remotes/origin/HEAD":  "ref: refs/remotes/origin/main\n",
+			"refs/heads/feature/login":  "2b4a6d8e\n",
+			"refs/remotes/origin/main":  "2b4a6d8e\n",
+			"refs/remotes/origin/.keep": "",
+		})
+
+		branch, err := GitDefaultBranch(tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, "main", branch)
+	}
+
+	t.Log("checked out branch")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("git")
+		require.NoError(t, err)
+
+		createTestFiles(t, tmpDir, map[string]string{"HEAD": "ref: refs/heads/develop\n"})
+
+		branch, err := GitDefaultBranch(tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, "develop", branch)
+	}
+
+	t.Log("detached HEAD")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("git")
+		require.NoError(t, err)
+
+		createTestFiles(t, tmpDir, map[string]string{"HEAD": "2b4a6d8e\n"})
+
+		_, err = GitDefaultBranch(tmpDir)
+		require.Error(t, err)
+	}
+}
+
+func TestGitBranches(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("git")
+	require.NoError(t, err)
+
+	createTestFiles(t, tmpDir, map[string]string{
+		"refs/heads/master":                "2b4a6d8e\n",
+		"refs/heads/release/1.0":           "2b4a6d8e\n",
+		"refs/remotes/origin/HEAD":         "ref: refs/remotes/origin/master\n",
+		"refs/remotes/origin/release-2.0":  "2b4a6d8e\n",
+		"refs/remotes/upstream/release_ci": "2b4a6d8e\n",
+		"packed-refs": `# pack-refs with: peeled fully-peeled sorted
+2b4a6d8e refs/heads/develop
+2b4a6d8e refs/remotes/origin/release/1.1
+2b4a6d8e refs/tags/1.0.0
+^3c5b7e9f
+`,
+	})
+
+	branches, err := GitBranches(tmpDir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"develop", "master", "release-2.0", "release/1.0", "release/1.1"}, branches)
+}
+
+func TestReleaseBranchPatterns(t *testing.T) {
+	require.Equal(t, []string{"release-*", "release/*", "releases/*"}, ReleaseBranchPatterns([]string{
+		"develop",
+		"master",
+		"release",
+		"release-2.0",
+		"release/1.0",
+		"release/1.1",
+		"releases/2018-10",
+		"released",
+		"prerelease/1.0",
+	}))
+
+	require.Equal(t, []string{}, ReleaseBranchPatterns([]string{"master", "feature/login"}))
+}
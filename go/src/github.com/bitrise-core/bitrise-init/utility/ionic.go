@@ -1,7 +1,176 @@
 package utility
 
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
 const ionicConfigJsonBasePath = "ionic.config.json"
 
+var capacitorConfigBasePaths = []string{"capacitor.config.json", "capacitor.config.ts"}
+
+// IonicGeneration ...
+type IonicGeneration string
+
+const (
+	// IonicGeneration1 is an Ionic 1 (AngularJS) project.
+	IonicGeneration1 IonicGeneration = "ionic1"
+	// IonicGeneration3 is an Ionic 2/3 (ionic-angular) project.
+	IonicGeneration3 IonicGeneration = "ionic3"
+	// IonicGeneration4Plus is an Ionic 4 or newer project (angular, react, vue or custom).
+	IonicGeneration4Plus IonicGeneration = "ionic4+"
+)
+
+// IonicIntegration ...
+type IonicIntegration string
+
+const (
+	// IonicIntegrationCordova ...
+	IonicIntegrationCordova IonicIntegration = "cordova"
+	// IonicIntegrationCapacitor ...
+	IonicIntegrationCapacitor IonicIntegration = "capacitor"
+)
+
+// IonicConfigModel ...
+type IonicConfigModel struct {
+	Name         string                     `json:"name"`
+	Type         string                     `json:"type"`
+	Integrations map[string]json.RawMessage `json:"integrations"`
+}
+
+// IonicProjectModel describes the flavour of an Ionic project.
+type IonicProjectModel struct {
+	// Type is the type field of ionic.config.json, like: ionic1, ionic-angular, angular, react, vue.
+	Type                string
+	Generation          IonicGeneration
+	Integration         IonicIntegration
+	CapacitorConfigPath string
+}
+
+func parseIonicConfigJSONContent(content string) (IonicConfigModel, error) {
+	var config IonicConfigModel
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return IonicConfigModel{}, err
+	}
+	return config, nil
+}
+
+// ParseIonicConfigJSON ...
+func ParseIonicConfigJSON(pth string) (IonicConfigModel, error) {
+	content, err := fileutil.ReadStringFromFile(pth)
+	if err != nil {
+		return IonicConfigModel{}, err
+	}
+	return parseIonicConfigJSONContent(content)
+}
+
+func ionicGeneration(projectType string) IonicGeneration {
+	switch projectType {
+	case "ionic1":
+		return IonicGeneration1
+	case "ionic-angular":
+		return IonicGeneration3
+	}
+	return IonicGeneration4Plus
+}
+
+// ionic1MarkerBasePaths are the project dir relative paths found only in Ionic 1 projects:
+// the legacy ionic-cli's project file and the bower installed ionic lib.
+var ionic1MarkerBasePaths = []string{"ionic.project", "bower.json", "www/lib/ionic"}
+
+func hasAnyJSDependency(packages PackagesModel, names ...string) bool {
+	for _, dependencies := range []map[string]string{packages.Dependencies, packages.DevDependencies} {
+		for _, name := range names {
+			if _, ok := dependencies[name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// untypedIonicGeneration detects the generation of the Ionic project in the projectDir, whose ionic.config.json has no type field.
+// The type field was introduced by ionic-cli 3, so these are usually Ionic 1 or 2/3 projects, the Ionic 2/3 generation is the fallback.
+func untypedIonicGeneration(projectDir string) (IonicGeneration, error) {
+	packageJSONPth := filepath.Join(projectDir, packageJSONBasePath)
+	if exist, err := pathutil.IsPathExists(packageJSONPth); err != nil {
+		return "", err
+	} else if exist {
+		packages, err := ParsePackagesJSON(packageJSONPth)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s, error: %s", packageJSONPth, err)
+		}
+
+		if hasAnyJSDependency(packages, "@ionic/angular", "@ionic/react", "@ionic/vue", "@ionic/core") {
+			return IonicGeneration4Plus, nil
+		}
+		if hasAnyJSDependency(packages, "ionic-angular", "@ionic/app-scripts") {
+			return IonicGeneration3, nil
+		}
+	}
+
+	ionic1MarkerPth, err := existingFileInDir(projectDir, ionic1MarkerBasePaths...)
+	if err != nil {
+		return "", err
+	}
+	if ionic1MarkerPth != "" {
+		return IonicGeneration1, nil
+	}
+
+	return IonicGeneration3, nil
+}
+
+// ClassifyIonicProject detects the generation and the native integration of the Ionic project
+// defined by the given ionic.config.json.
+func ClassifyIonicProject(ionicConfigPth string) (IonicProjectModel, error) {
+	config, err := ParseIonicConfigJSON(ionicConfigPth)
+	if err != nil {
+		return IonicProjectModel{}, err
+	}
+
+	project := IonicProjectModel{
+		Type:        config.Type,
+		Generation:  ionicGeneration(config.Type),
+		Integration: IonicIntegrationCordova,
+	}
+	if config.Type == "" {
+		if project.Generation, err = untypedIonicGeneration(filepath.Dir(ionicConfigPth)); err != nil {
+			return IonicProjectModel{}, err
+		}
+	}
+
+	capacitorConfigPth, err := existingFileInDir(filepath.Dir(ionicConfigPth), capacitorConfigBasePaths...)
+	if err != nil {
+		return IonicProjectModel{}, err
+	}
+	project.CapacitorConfigPath = capacitorConfigPth
+
+	_, hasCapacitorIntegration := config.Integrations[string(IonicIntegrationCapacitor)]
+	_, hasCordovaIntegration := config.Integrations[string(IonicIntegrationCordova)]
+
+	if project.Generation == IonicGeneration4Plus && (hasCapacitorIntegration || capacitorConfigPth != "") {
+		if !hasCordovaIntegration || capacitorConfigPth != "" {
+			project.Integration = IonicIntegrationCapacitor
+		}
+	}
+
+	return project, nil
+}
+
+// CapacitorNativeProjectExists checks if the native project of the given platform (ios/App or android)
+// was generated (npx cap add) in the Capacitor project dir.
+func CapacitorNativeProjectExists(projectDir, platform string) (bool, error) {
+	pth := filepath.Join(projectDir, platform)
+	if platform == "ios" {
+		pth = filepath.Join(pth, "App")
+	}
+	return pathutil.IsDirExists(pth)
+}
+
 // FilterRootIonicConfigJsonFile ...
 func FilterRootIonicConfigJsonFile(fileList []string) (string, error) {
 	allowIonicConfigJsonBaseFilter := BaseFilter(ionicConfigJsonBasePath, true)
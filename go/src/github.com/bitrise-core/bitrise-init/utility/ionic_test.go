@@ -1 +1,165 @@
 package utility
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIonicConfigJSONContent(t *testing.T) {
+	config, err := parseIonicConfigJSONContent(`{
+  "name": "myApp",
+  "integrations": {
+    "capacitor": {}
+  },
+  "type": "angular"
+}`)
+	require.NoError(t, err)
+	require.Equal(t, "myApp", config.Name)
+	require.Equal(t, "angular", config.Type)
+	_, ok := config.Integrations["capacitor"]
+	require.Equal(t, true, ok)
+}
+
+func TestClassifyIonicProject(t *testing.T) {
+	t.Log("ionic 3 cordova project")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__ionic__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"ionic.config.json": `{"name": "app", "app_id": "", "type": "ionic-angular", "integrations": {"cordova": {}}}`,
+		})
+
+		project, err := ClassifyIonicProject(filepath.Join(tmpDir, "ionic.config.json"))
+		require.NoError(t, err)
+		require.Equal(t, IonicProjectModel{
+			Type:        "ionic-angular",
+			Generation:  IonicGeneration3,
+			Integration: IonicIntegrationCordova,
+		}, project)
+	}
+
+	t.Log("ionic 1 project")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__ionic__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"ionic.config.json": `{"name": "app", "type": "ionic1"}`,
+		})
+
+		project, err := ClassifyIonicProject(filepath.Join(tmpDir, "ionic.config.json"))
+		require.NoError(t, err)
+		require.Equal(t, IonicGeneration1, project.Generation)
+		require.Equal(t, IonicIntegrationCordova, project.Integration)
+	}
+
+	t.Log("ionic 4+ capacitor project")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__ionic__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"ionic.config.json":   `{"name": "app", "type": "react", "integrations": {"capacitor": {}}}`,
+			"capacitor.config.ts": `export default {appId: 'io.ionic.starter'};`,
+			"ios/App/Podfile":     ``,
+		})
+
+		project, err := ClassifyIonicProject(filepath.Join(tmpDir, "ionic.config.json"))
+		require.NoError(t, err)
+		require.Equal(t, IonicProjectModel{
+			Type:                "react",
+			Generation:          IonicGeneration4Plus,
+			Integration:         IonicIntegrationCapacitor,
+			CapacitorConfigPath: filepath.Join(tmpDir, "capacitor.config.ts"),
+		}, project)
+
+		exist, err := CapacitorNativeProjectExists(tmpDir, "ios")
+		require.NoError(t, err)
+		require.Equal(t, true, exist)
+
+		exist, err = CapacitorNativeProjectExists(tmpDir, "android")
+		require.NoError(t, err)
+		require.Equal(t, false, exist)
+	}
+
+	t.Log("ionic 4+ cordova project")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__ionic__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"ionic.config.json": `{"name": "app", "type": "angular", "integrations": {"cordova": {}}}`,
+		})
+
+		project, err := ClassifyIonicProject(filepath.Join(tmpDir, "ionic.config.json"))
+		require.NoError(t, err)
+		require.Equal(t, IonicGeneration4Plus, project.Generation)
+		require.Equal(t, IonicIntegrationCordova, project.Integration)
+	}
+
+	t.Log("ionic project without type")
+	{
+		for _, tc := range []struct {
+			files      map[string]string
+			generation IonicGeneration
+		}{
+			{
+				files: map[string]string{
+					"ionic.config.json": `{"name": "app", "app_id": ""}`,
+					"ionic.project":     `{"name": "app"}`,
+					"package.json":      `{"devDependencies": {"gulp": "^3.5.6"}}`,
+				},
+				generation: IonicGeneration1,
+			},
+			{
+				files: map[string]string{
+					"ionic.config.json": `{"name": "app", "app_id": ""}`,
+					"bower.json":        `{"devDependencies": {"ionic": "driftyco/ionic-bower#1.3.1"}}`,
+				},
+				generation: IonicGeneration1,
+			},
+			{
+				files: map[string]string{
+					"ionic.config.json": `{"name": "app", "app_id": ""}`,
+					"package.json":      `{"devDependencies": {"@ionic/app-scripts": "1.3.7"}}`,
+				},
+				generation: IonicGeneration3,
+			},
+			{
+				files: map[string]string{
+					"ionic.config.json": `{"name": "app", "app_id": ""}`,
+				},
+				generation: IonicGeneration3,
+			},
+		} {
+			tmpDir, err := pathutil.NormalizedOSTempDirPath("__ionic__")
+			require.NoError(t, err)
+
+			createTestFiles(t, tmpDir, tc.files)
+
+			project, err := ClassifyIonicProject(filepath.Join(tmpDir, "ionic.config.json"))
+			require.NoError(t, err)
+			require.Equal(t, tc.generation, project.Generation)
+			require.Equal(t, IonicIntegrationCordova, project.Integration)
+
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}
+	}
+}
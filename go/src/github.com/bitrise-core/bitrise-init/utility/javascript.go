@@ -0,0 +1,539 @@
+package utility
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	packageJSONBasePath       = "package.json"
+	packageLockJSONBasePath   = "package-lock.json"
+	npmShrinkwrapJSONBasePath = "npm-shrinkwrap.json"
+	yarnLockBasePath          = "yarn.lock"
+	pnpmLockYAMLBasePath      = "pnpm-lock.yaml"
+	pnpmWorkspaceYAMLBasePath = "pnpm-workspace.yaml"
+	npmrcBasePath             = ".npmrc"
+	yarnrcBasePath            = ".yarnrc"
+	yarnrcYMLBasePath         = ".yarnrc.yml"
+	nodeModulesDirName        = "node_modules"
+)
+
+// JSPackageManager ...
+type JSPackageManager string
+
+const (
+	// JSPackageManagerNPM ...
+	JSPackageManagerNPM JSPackageManager = "npm"
+	// JSPackageManagerYarn ...
+	JSPackageManagerYarn JSPackageManager = "yarn"
+	// JSPackageManagerPNPM ...
+	JSPackageManagerPNPM JSPackageManager = "pnpm"
+)
+
+// JSDependenciesModel describes how the dependencies of a JavaScript project should be installed.
+type JSDependenciesModel struct {
+	PackageManager JSPackageManager
+	// InstallDir is the directory (relative to the root dir) where the install command has to run,
+	// it is the workspace root for projects which are part of a yarn, npm or pnpm workspace.
+	InstallDir  string
+	Lockfile    string
+	IsWorkspace bool
+}
+
+// CachePaths returns the dependency directories in bitrise cache path format (path -> indicator).
+func (dependencies JSDependenciesModel) CachePaths() []string {
+	return []string{CachePath(filepath.Join(dependencies.InstallDir, nodeModulesDirName), dependencies.Lockfile)}
+}
+
+// RelativeTo returns the model with its paths relative to the given (root dir relative) dir,
+// paths outside of dir are expressed with "..".
+func (dependencies JSDependenciesModel) RelativeTo(dir string) (JSDependenciesModel, error) {
+	installDir, err := filepath.Rel(dir, dependencies.InstallDir)
+	if err != nil {
+		return JSDependenciesModel{}, err
+	}
+	dependencies.InstallDir = installDir
+
+	if dependencies.Lockfile != "" {
+		lockfile, err := filepath.Rel(dir, dependencies.Lockfile)
+		if err != nil {
+			return JSDependenciesModel{}, err
+		}
+		dependencies.Lockfile = lockfile
+	}
+
+	return dependencies, nil
+}
+
+type workspacePackagesModel struct {
+	Packages []string `json:"packages"`
+}
+
+type packageJSONWorkspacesModel struct {
+	Workspaces     json.RawMessage `json:"workspaces"`
+	PackageManager string          `json:"packageManager"`
+}
+
+// packageJSONWorkspaces returns the workspace patterns defined in a package.json,
+// workspaces can be defined as an array or as an object with packages key.
+func packageJSONWorkspaces(content string) ([]string, string, error) {
+	var packageJSON packageJSONWorkspacesModel
+	if err := json.Unmarshal([]byte(content), &packageJSON); err != nil {
+		return []string{}, "", err
+	}
+
+	if len(packageJSON.Workspaces) == 0 {
+		return []string{}, packageJSON.PackageManager, nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(packageJSON.Workspaces, &patterns); err == nil {
+		return patterns, packageJSON.PackageManager, nil
+	}
+
+	var workspaces workspacePackagesModel
+	if err := json.Unmarshal(packageJSON.Workspaces, &workspaces); err != nil {
+		return []string{}, "", fmt.Errorf("invalid workspaces definition, error: %s", err)
+	}
+	return workspaces.Packages, packageJSON.PackageManager, nil
+}
+
+// pnpmWorkspacePackages returns the package patterns listed in a pnpm-workspace.yaml.
+func pnpmWorkspacePackages(content string) []string {
+	patterns := []string{}
+	inPackages := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+
+		if inPackages && strings.HasPrefix(trimmed, "-") {
+			pattern := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			pattern = strings.Trim(pattern, `"'`)
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// matchWorkspacePattern checks if the given (workspace root relative) project dir matches a workspace pattern,
+// like: packages/*, apps/**, apps/mobile or **/test/**.
+func matchWorkspacePattern(pattern, relProjectDir string) bool {
+	pattern = strings.TrimPrefix(filepath.Clean(pattern), "./")
+	if strings.HasPrefix(pattern, "!") {
+		return false
+	}
+
+	if strings.HasPrefix(pattern, "**/") {
+		// matches at any depth
+		components := strings.Split(relProjectDir, "/")
+		for i := range components {
+			if matchWorkspacePattern(strings.TrimPrefix(pattern, "**/"), strings.Join(components[i:], "/")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if strings.HasSuffix(pattern, "/**") {
+		prefix := strings.TrimSuffix(pattern, "/**")
+		return relProjectDir == prefix || strings.HasPrefix(relProjectDir, prefix+"/")
+	}
+
+	matched, err := filepath.Match(pattern, relProjectDir)
+	if err != nil {
+		return false
+	}
+	return matched
+}
+
+// isInWorkspace checks if the given (workspace root relative) project dir matches any of the workspace patterns,
+// and none of the negated (!) ones.
+func isInWorkspace(patterns []string, relProjectDir string) bool {
+	included := false
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "!") && matchWorkspacePattern(pattern, relProjectDir) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") && matchWorkspacePattern(strings.TrimPrefix(pattern, "!"), relProjectDir) {
+			return false
+		}
+	}
+	return true
+}
+
+func existingFileInDir(dir string, baseNames ...string) (string, error) {
+	for _, baseName := range baseNames {
+		pth := filepath.Join(dir, baseName)
+		if exist, err := pathutil.IsPathExists(pth); err != nil {
+			return "", err
+		} else if exist {
+			return pth, nil
+		}
+	}
+	return "", nil
+}
+
+// findJSWorkspaceRoot walks up from the project dir to the root dir and returns the
+// closest directory which defines a workspace containing the project.
+func findJSWorkspaceRoot(absProjectDir, absRootDir string) (string, JSPackageManager, error) {
+	dir := filepath.Dir(absProjectDir)
+	for absProjectDir != absRootDir && (dir == absRootDir || strings.HasPrefix(dir, absRootDir+string(filepath.Separator))) {
+		relProjectDir, err := filepath.Rel(dir, absProjectDir)
+		if err != nil {
+			return "", "", err
+		}
+
+		pnpmWorkspacePth := filepath.Join(dir, pnpmWorkspaceYAMLBasePath)
+		if exist, err := pathutil.IsPathExists(pnpmWorkspacePth); err != nil {
+			return "", "", err
+		} else if exist {
+			content, err := fileutil.ReadStringFromFile(pnpmWorkspacePth)
+			if err != nil {
+				return "", "", err
+			}
+			if isInWorkspace(pnpmWorkspacePackages(content), relProjectDir) {
+				return dir, JSPackageManagerPNPM, nil
+			}
+		}
+
+		packageJSONPth := filepath.Join(dir, packageJSONBasePath)
+		if exist, err := pathutil.IsPathExists(packageJSONPth); err != nil {
+			return "", "", err
+		} else if exist {
+			content, err := fileutil.ReadStringFromFile(packageJSONPth)
+			if err != nil {
+				return "", "", err
+			}
+			patterns, _, err := packageJSONWorkspaces(content)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to parse %s, error: %s", packageJSONPth, err)
+			}
+			if isInWorkspace(patterns, relProjectDir) {
+				return dir, "", nil
+			}
+		}
+
+		if dir == absRootDir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	return "", "", nil
+}
+
+// packageManagerInDir detects the package manager used in the given dir, based on the lock and config files.
+func packageManagerInDir(dir string) (JSPackageManager, string, error) {
+	lockfileManagers := []struct {
+		base    string
+		manager JSPackageManager
+	}{
+		{pnpmLockYAMLBasePath, JSPackageManagerPNPM},
+		{yarnLockBasePath, JSPackageManagerYarn},
+		{packageLockJSONBasePath, JSPackageManagerNPM},
+		{npmShrinkwrapJSONBasePath, JSPackageManagerNPM},
+	}
+	for _, lockfileManager := range lockfileManagers {
+		pth, err := existingFileInDir(dir, lockfileManager.base)
+		if err != nil {
+			return "", "", err
+		} else if pth != "" {
+			return lockfileManager.manager, pth, nil
+		}
+	}
+
+	// no lockfile, check the packageManager field of package.json
+	packageJSONPth := filepath.Join(dir, packageJSONBasePath)
+	if exist, err := pathutil.IsPathExists(packageJSONPth); err != nil {
+		return "", "", err
+	} else if exist {
+		content, err := fileutil.ReadStringFromFile(packageJSONPth)
+		if err != nil {
+			return "", "", err
+		}
+		if _, packageManager, err := packageJSONWorkspaces(content); err == nil && packageManager != "" {
+			name := strings.Split(packageManager, "@")[0]
+			switch JSPackageManager(name) {
+			case JSPackageManagerNPM, JSPackageManagerYarn, JSPackageManagerPNPM:
+				return JSPackageManager(name), "", nil
+			}
+		}
+	}
+
+	// fall back to the package manager specific rc files
+	if pth, err := existingFileInDir(dir, yarnrcBasePath, yarnrcYMLBasePath); err != nil {
+		return "", "", err
+	} else if pth != "" {
+		return JSPackageManagerYarn, "", nil
+	}
+
+	if pth, err := existingFileInDir(dir, npmrcBasePath); err != nil {
+		return "", "", err
+	} else if pth != "" {
+		return JSPackageManagerNPM, "", nil
+	}
+
+	return "", "", nil
+}
+
+// DetectJSDependencies detects the package manager and the install directory of the JavaScript project
+// placed in projectDir. If the project is part of a workspace (yarn/npm workspaces or pnpm-workspace.yaml)
+// below rootDir, the dependencies have to be installed in the workspace root.
+// The returned paths are relative to rootDir.
+func DetectJSDependencies(projectDir, rootDir string) (JSDependenciesModel, error) {
+	absRootDir, err := pathutil.AbsPath(rootDir)
+	if err != nil {
+		return JSDependenciesModel{}, err
+	}
+
+	absProjectDir, err := pathutil.AbsPath(projectDir)
+	if err != nil {
+		return JSDependenciesModel{}, err
+	}
+
+	installDir := absProjectDir
+	isWorkspace := false
+
+	workspaceRoot, workspaceManager, err := findJSWorkspaceRoot(absProjectDir, absRootDir)
+	if err != nil {
+		return JSDependenciesModel{}, err
+	}
+	if workspaceRoot != "" {
+		installDir = workspaceRoot
+		isWorkspace = true
+	}
+
+	packageManager, lockfile, err := packageManagerInDir(installDir)
+	if err != nil {
+		return JSDependenciesModel{}, err
+	}
+	if packageManager == "" {
+		packageManager = workspaceManager
+	}
+	if packageManager == "" {
+		packageManager = JSPackageManagerNPM
+	}
+
+	relInstallDir, err := RelPath(absRootDir, installDir)
+	if err != nil {
+		return JSDependenciesModel{}, err
+	}
+
+	relLockfile := ""
+	if lockfile != "" {
+		relLockfile, err = RelPath(absRootDir, lockfile)
+		if err != nil {
+			return JSDependenciesModel{}, err
+		}
+	}
+
+	return JSDependenciesModel{
+		PackageManager: packageManager,
+		InstallDir:     relInstallDir,
+		Lockfile:       relLockfile,
+		IsWorkspace:    isWorkspace,
+	}, nil
+}
+
+// JSTestFramework ...
+type JSTestFramework string
+
+const (
+	// JSTestFrameworkKarma ...
+	JSTestFrameworkKarma JSTestFramework = "karma"
+	// JSTestFrameworkJasmine ...
+	JSTestFrameworkJasmine JSTestFramework = "jasmine"
+	// JSTestFrameworkJest ...
+	JSTestFrameworkJest JSTestFramework = "jest"
+	// JSTestFrameworkProtractor ...
+	JSTestFrameworkProtractor JSTestFramework = "protractor"
+	// JSTestFrameworkCypress ...
+	JSTestFrameworkCypress JSTestFramework = "cypress"
+)
+
+// JSTestModel describes a detected test setup of a JavaScript project.
+type JSTestModel struct {
+	Framework JSTestFramework
+	// ConfigPath is the project dir relative path of the framework's config file (if any).
+	ConfigPath string
+	// Script is the package.json script, which runs the tests (if any).
+	Script string
+	// ScriptCommand is the command of the Script.
+	ScriptCommand string
+	IsE2E         bool
+}
+
+type jsTestFrameworkDescriptor struct {
+	framework JSTestFramework
+	isE2E     bool
+	// dependency is searched for in the dependencies and devDependencies
+	dependency string
+	// configPaths are the project dir relative config file paths
+	configPaths []string
+	// commands are searched for in the package.json scripts
+	commands []string
+	// preferredScripts are the names of the scripts usually running the tests
+	preferredScripts []string
+}
+
+// jsTestFrameworkDescriptors lists the supported frameworks, karma precedes jasmine as
+// karma projects usually use jasmine as the test framework.
+var jsTestFrameworkDescriptors = []jsTestFrameworkDescriptor{
+	{
+		framework:        JSTestFrameworkKarma,
+		dependency:       "karma",
+		configPaths:      []string{"karma.conf.js", "src/karma.conf.js"},
+		commands:         []string{"karma", "ng test"},
+		preferredScripts: []string{"test"},
+	},
+	{
+		framework:        JSTestFrameworkJasmine,
+		dependency:       "jasmine",
+		configPaths:      []string{"spec/support/jasmine.json"},
+		commands:         []string{"jasmine"},
+		preferredScripts: []string{"test"},
+	},
+	{
+		framework:        JSTestFrameworkJest,
+		dependency:       "jest",
+		configPaths:      []string{"jest.config.js", "jest.config.ts", "jest.config.json"},
+		commands:         []string{"jest", "react-scripts test"},
+		preferredScripts: []string{"test", "test:unit"},
+	},
+	{
+		framework:        JSTestFrameworkProtractor,
+		isE2E:            true,
+		dependency:       "protractor",
+		configPaths:      []string{"protractor.conf.js", "e2e/protractor.conf.js"},
+		commands:         []string{"protractor", "ng e2e"},
+		preferredScripts: []string{"e2e", "test:e2e"},
+	},
+	{
+		framework:        JSTestFrameworkCypress,
+		isE2E:            true,
+		dependency:       "cypress",
+		configPaths:      []string{"cypress.json", "cypress.config.js", "cypress.config.ts"},
+		commands:         []string{"cypress run"},
+		preferredScripts: []string{"e2e", "test:e2e"},
+	},
+}
+
+func hasJSDependency(packages PackagesModel, dependency string) bool {
+	for _, dependencies := range []map[string]string{packages.Dependencies, packages.DevDependencies} {
+		for name := range dependencies {
+			if name == dependency || strings.HasPrefix(name, dependency+"-") || strings.HasPrefix(name, "@"+dependency+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// testScript returns the package.json script running the given framework,
+// the preferred script names are checked first.
+func testScript(scripts map[string]string, descriptor jsTestFrameworkDescriptor) (string, string) {
+	runsFramework := func(command string) bool {
+		for _, frameworkCommand := range descriptor.commands {
+			if strings.Contains(command, frameworkCommand) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, name := range descriptor.preferredScripts {
+		if command, ok := scripts[name]; ok && runsFramework(command) {
+			return name, command
+		}
+	}
+
+	// map iteration order is random, keep the result stable
+	names := []string{}
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if runsFramework(scripts[name]) {
+			return name, scripts[name]
+		}
+	}
+	return "", ""
+}
+
+func detectJSTests(projectDir string, packages PackagesModel) ([]JSTestModel, error) {
+	tests := []JSTestModel{}
+	usedScripts := map[string]bool{}
+
+	for _, descriptor := range jsTestFrameworkDescriptors {
+		configPth, err := existingFileInDir(projectDir, descriptor.configPaths...)
+		if err != nil {
+			return []JSTestModel{}, err
+		}
+		if configPth != "" {
+			if configPth, err = RelPath(projectDir, configPth); err != nil {
+				return []JSTestModel{}, err
+			}
+		}
+
+		script, command := testScript(packages.Scripts, descriptor)
+		if usedScripts[script] {
+			// for example karma projects run jasmine tests through the same script
+			script, command = "", ""
+		}
+
+		// a test setup is detected if the project depends on the framework and
+		// either configures it or runs it from a script
+		if !hasJSDependency(packages, descriptor.dependency) || (configPth == "" && script == "") {
+			continue
+		}
+		if descriptor.framework == JSTestFrameworkJasmine && len(tests) > 0 && tests[0].Framework == JSTestFrameworkKarma {
+			continue
+		}
+
+		if script != "" {
+			usedScripts[script] = true
+		}
+
+		tests = append(tests, JSTestModel{
+			Framework:     descriptor.framework,
+			ConfigPath:    configPth,
+			Script:        script,
+			ScriptCommand: command,
+			IsE2E:         descriptor.isE2E,
+		})
+	}
+
+	return tests, nil
+}
+
+// DetectJSTests detects the unit and e2e test setups (Karma, Jasmine, Jest, Protractor and Cypress)
+// of the JavaScript project placed in projectDir, based on the package.json dependencies and scripts
+// and on the frameworks' config files.
+func DetectJSTests(projectDir string) ([]JSTestModel, error) {
+	packages, err := ParsePackagesJSON(filepath.Join(projectDir, packageJSONBasePath))
+	if err != nil {
+		return []JSTestModel{}, err
+	}
+	return detectJSTests(projectDir, packages)
+}
@@ -0,0 +1,338 @@
+package utility
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestFiles(t *testing.T, rootDir string, fileContentMap map[string]string) {
+	for pth, content := range fileContentMap {
+		pth = filepath.Join(rootDir, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0700))
+		require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	}
+}
+
+func TestPackageJSONWorkspaces(t *testing.T) {
+	t.Log("array workspaces")
+	{
+		patterns, packageManager, err := packageJSONWorkspaces(`{"private": true, "workspaces": ["packages/*", "apps/mobile"]}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"packages/*", "apps/mobile"}, patterns)
+		require.Equal(t, "", packageManager)
+	}
+
+	t.Log("object workspaces")
+	{
+		patterns, packageManager, err := packageJSONWorkspaces(`{"workspaces": {"packages": ["apps/**"], "nohoist": ["**/react-native"]}, "packageManager": "yarn@3.2.0"}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"apps/**"}, patterns)
+		require.Equal(t, "yarn@3.2.0", packageManager)
+	}
+
+	t.Log("no workspaces")
+	{
+		patterns, _, err := packageJSONWorkspaces(`{"name": "app"}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{}, patterns)
+	}
+}
+
+func TestPnpmWorkspacePackages(t *testing.T) {
+	content := `packages:
+  # all packages in subdirs of packages/
+  - 'packages/*'
+  - "apps/**"
+  - '!**/test/**'
+catalog:
+  react: ^18.0.0
+`
+	require.Equal(t, []string{"packages/*", "apps/**", "!**/test/**"}, pnpmWorkspacePackages(content))
+}
+
+func TestMatchWorkspacePattern(t *testing.T) {
+	require.Equal(t, true, matchWorkspacePattern("packages/*", "packages/app"))
+	require.Equal(t, true, matchWorkspacePattern("./packages/*", "packages/app"))
+	require.Equal(t, true, matchWorkspacePattern("apps/**", "apps/mobile/ionic"))
+	require.Equal(t, true, matchWorkspacePattern("apps/mobile", "apps/mobile"))
+
+	require.Equal(t, false, matchWorkspacePattern("packages/*", "packages/app/nested"))
+	require.Equal(t, false, matchWorkspacePattern("apps/**", "packages/app"))
+	require.Equal(t, false, matchWorkspacePattern("!apps/**", "apps/app"))
+
+	require.Equal(t, true, matchWorkspacePattern("**/test/**", "apps/test/e2e"))
+	require.Equal(t, true, matchWorkspacePattern("**/test/**", "test"))
+	require.Equal(t, false, matchWorkspacePattern("**/test/**", "apps/testing"))
+}
+
+func TestIsInWorkspace(t *testing.T) {
+	patterns := []string{"apps/**", "!apps/legacy", "!**/test/**"}
+
+	require.Equal(t, true, isInWorkspace(patterns, "apps/mobile"))
+
+	require.Equal(t, false, isInWorkspace(patterns, "apps/legacy"))
+	require.Equal(t, false, isInWorkspace(patterns, "apps/mobile/test/fixture"))
+	require.Equal(t, false, isInWorkspace(patterns, "packages/ui"))
+}
+
+func TestFindJSWorkspaceRoot(t *testing.T) {
+	t.Log("workspace root is a sibling of the root dir with the same prefix")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_workspace_root__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"app-mobile/package.json":                `{"private": true, "workspaces": ["packages/*"]}`,
+			"app-mobile/packages/ionic/package.json": `{"name": "ionic"}`,
+		})
+
+		workspaceRoot, _, err := findJSWorkspaceRoot(filepath.Join(tmpDir, "app-mobile/packages/ionic"), filepath.Join(tmpDir, "app"))
+		require.NoError(t, err)
+		require.Equal(t, "", workspaceRoot)
+	}
+
+	t.Log("negated workspace pattern")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_workspace_root__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"pnpm-workspace.yaml":      "packages:\n  - 'apps/*'\n  - '!apps/legacy'\n",
+			"apps/mobile/package.json": `{"name": "mobile"}`,
+			"apps/legacy/package.json": `{"name": "legacy"}`,
+		})
+
+		workspaceRoot, manager, err := findJSWorkspaceRoot(filepath.Join(tmpDir, "apps/mobile"), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, tmpDir, workspaceRoot)
+		require.Equal(t, JSPackageManagerPNPM, manager)
+
+		workspaceRoot, _, err = findJSWorkspaceRoot(filepath.Join(tmpDir, "apps/legacy"), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, "", workspaceRoot)
+	}
+}
+
+func TestDetectJSDependencies(t *testing.T) {
+	t.Log("npm project")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_dependencies__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"package.json":      `{"name": "app"}`,
+			"package-lock.json": `{}`,
+		})
+
+		dependencies, err := DetectJSDependencies(tmpDir, tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, JSDependenciesModel{
+			PackageManager: JSPackageManagerNPM,
+			InstallDir:     ".",
+			Lockfile:       "package-lock.json",
+		}, dependencies)
+		require.Equal(t, []string{"node_modules -> package-lock.json"}, dependencies.CachePaths())
+	}
+
+	t.Log("yarn project without lockfile")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_dependencies__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"app/package.json": `{"name": "app"}`,
+			"app/.yarnrc":      `registry "https://registry.npmjs.org"`,
+		})
+
+		dependencies, err := DetectJSDependencies(filepath.Join(tmpDir, "app"), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, JSDependenciesModel{
+			PackageManager: JSPackageManagerYarn,
+			InstallDir:     "app",
+		}, dependencies)
+		require.Equal(t, []string{"app/node_modules"}, dependencies.CachePaths())
+	}
+
+	t.Log("yarn workspace")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_dependencies__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"package.json":              `{"private": true, "workspaces": ["apps/*"]}`,
+			"yarn.lock":                 ``,
+			"apps/cordova/package.json": `{"name": "cordova"}`,
+		})
+
+		dependencies, err := DetectJSDependencies(filepath.Join(tmpDir, "apps/cordova"), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, JSDependenciesModel{
+			PackageManager: JSPackageManagerYarn,
+			InstallDir:     ".",
+			Lockfile:       "yarn.lock",
+			IsWorkspace:    true,
+		}, dependencies)
+	}
+
+	t.Log("pnpm workspace without lockfile")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_dependencies__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"mono/package.json":                `{"private": true}`,
+			"mono/pnpm-workspace.yaml":         "packages:\n  - 'packages/**'\n",
+			"mono/packages/ionic/package.json": `{"name": "ionic"}`,
+		})
+
+		dependencies, err := DetectJSDependencies(filepath.Join(tmpDir, "mono/packages/ionic"), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, JSDependenciesModel{
+			PackageManager: JSPackageManagerPNPM,
+			InstallDir:     "mono",
+			IsWorkspace:    true,
+		}, dependencies)
+	}
+
+	t.Log("project outside of the workspace patterns")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_dependencies__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"package.json":          `{"private": true, "workspaces": ["packages/*"]}`,
+			"yarn.lock":             ``,
+			"app/package.json":      `{"name": "app"}`,
+			"app/package-lock.json": `{}`,
+		})
+
+		dependencies, err := DetectJSDependencies(filepath.Join(tmpDir, "app"), tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, JSDependenciesModel{
+			PackageManager: JSPackageManagerNPM,
+			InstallDir:     "app",
+			Lockfile:       "app/package-lock.json",
+		}, dependencies)
+	}
+}
+
+func TestJSDependenciesRelativeTo(t *testing.T) {
+	dependencies, err := JSDependenciesModel{
+		PackageManager: JSPackageManagerYarn,
+		InstallDir:     ".",
+		Lockfile:       "yarn.lock",
+		IsWorkspace:    true,
+	}.RelativeTo("apps/ionic")
+	require.NoError(t, err)
+	require.Equal(t, "../..", dependencies.InstallDir)
+	require.Equal(t, "../../yarn.lock", dependencies.Lockfile)
+}
+
+func TestDetectJSTests(t *testing.T) {
+	t.Log("angular karma and protractor tests")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_tests__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"src/karma.conf.js":      ``,
+			"e2e/protractor.conf.js": ``,
+		})
+
+		tests, err := detectJSTests(tmpDir, PackagesModel{
+			DevDependencies: map[string]string{"karma": "~6.3.0", "karma-jasmine": "~4.0.0", "jasmine-core": "~3.8.0", "protractor": "~7.0.0"},
+			Scripts:         map[string]string{"test": "ng test", "lint": "ng lint", "e2e": "ng e2e"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []JSTestModel{
+			{Framework: JSTestFrameworkKarma, ConfigPath: "src/karma.conf.js", Script: "test", ScriptCommand: "ng test"},
+			{Framework: JSTestFrameworkProtractor, ConfigPath: "e2e/protractor.conf.js", Script: "e2e", ScriptCommand: "ng e2e", IsE2E: true},
+		}, tests)
+	}
+
+	t.Log("jest and cypress tests from scripts")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_tests__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		tests, err := detectJSTests(tmpDir, PackagesModel{
+			Dependencies:    map[string]string{"react": "^17.0.0"},
+			DevDependencies: map[string]string{"jest": "^27.0.0", "cypress": "^9.0.0"},
+			Scripts:         map[string]string{"test.unit": "jest --coverage", "test.e2e": "cypress run"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []JSTestModel{
+			{Framework: JSTestFrameworkJest, Script: "test.unit", ScriptCommand: "jest --coverage"},
+			{Framework: JSTestFrameworkCypress, Script: "test.e2e", ScriptCommand: "cypress run", IsE2E: true},
+		}, tests)
+	}
+
+	t.Log("jasmine test from config")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_tests__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		createTestFiles(t, tmpDir, map[string]string{
+			"spec/support/jasmine.json": `{}`,
+		})
+
+		tests, err := detectJSTests(tmpDir, PackagesModel{
+			DevDependencies: map[string]string{"jasmine": "^3.0.0"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []JSTestModel{
+			{Framework: JSTestFrameworkJasmine, ConfigPath: "spec/support/jasmine.json"},
+		}, tests)
+	}
+
+	t.Log("framework dependency without config or script")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__js_tests__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		tests, err := detectJSTests(tmpDir, PackagesModel{
+			DevDependencies: map[string]string{"jest": "^27.0.0"},
+			Scripts:         map[string]string{"build": "tsc"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []JSTestModel{}, tests)
+	}
+}
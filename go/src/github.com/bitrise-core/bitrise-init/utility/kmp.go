@@ -0,0 +1,198 @@
+package utility
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+const (
+	kmpIOSAppDirName               = "iosApp"
+	kmpEmbedAndSignFrameworkTaskID = "embedAndSignAppleFrameworkForXcode"
+)
+
+// KMPTarget is a target of a Kotlin Multiplatform module.
+type KMPTarget string
+
+const (
+	// KMPTargetAndroid ...
+	KMPTargetAndroid KMPTarget = "android"
+	// KMPTargetIOSArm64 ...
+	KMPTargetIOSArm64 KMPTarget = "iosArm64"
+	// KMPTargetIOSSimulatorArm64 ...
+	KMPTargetIOSSimulatorArm64 KMPTarget = "iosSimulatorArm64"
+	// KMPTargetIOSX64 ...
+	KMPTargetIOSX64 KMPTarget = "iosX64"
+	// KMPTargetJVM ...
+	KMPTargetJVM KMPTarget = "jvm"
+	// KMPTargetJS ...
+	KMPTargetJS KMPTarget = "js"
+)
+
+// kmpTargets is the order of the detected targets.
+var kmpTargets = []KMPTarget{KMPTargetAndroid, KMPTargetIOSArm64, KMPTargetIOSSimulatorArm64, KMPTargetIOSX64, KMPTargetJVM, KMPTargetJS}
+
+// IsIOS reports whether the target builds for iOS.
+func (target KMPTarget) IsIOS() bool {
+	return strings.HasPrefix(string(target), "ios")
+}
+
+// KMPFrameworkIntegration tells how the iOS app embeds the shared module's framework.
+type KMPFrameworkIntegration string
+
+const (
+	// KMPFrameworkIntegrationDirect means an Xcode build phase runs the embedAndSignAppleFrameworkForXcode gradle task.
+	KMPFrameworkIntegrationDirect KMPFrameworkIntegration = "direct"
+	// KMPFrameworkIntegrationCocoaPods means the framework is a CocoaPods dependency, generated by the kotlin cocoapods gradle plugin.
+	KMPFrameworkIntegrationCocoaPods KMPFrameworkIntegration = "cocoapods"
+)
+
+var (
+	kmpPluginRegexp = regexp.MustCompile(`(?m)^\s*(?:kotlin\(\s*"multiplatform"\s*\)|id\s*\(?\s*["']org\.jetbrains\.kotlin\.multiplatform["']\s*\)?|alias\(\s*libs\.plugins\.kotlin[.\-]?[Mm]ultiplatform\s*\))(.*)$`)
+	// apply false (kotlin dsl and groovy) or .apply(false) (version catalog alias)
+	kmpApplyFalseRegexp = regexp.MustCompile(`\bapply\s*(?:\(\s*false\s*\)|false\b)`)
+	// android { is the Android Gradle Plugin's block, only the deprecated android() declares a target
+	kmpTargetRegexp         = regexp.MustCompile(`\b(?:(android)\s*\(|(androidTarget|iosArm64|iosSimulatorArm64|iosX64|jvm|js)\s*[({])`)
+	kmpCocoaPodsBlockRegexp = regexp.MustCompile(`(?m)^\s*cocoapods\s*\{`)
+)
+
+// KMPModuleModel is a gradle module applying the Kotlin Multiplatform plugin.
+type KMPModuleModel struct {
+	GradleFile string
+	Targets    []KMPTarget
+	// HasCocoaPods is true, if the module publishes its framework with the kotlin cocoapods gradle plugin.
+	HasCocoaPods bool
+}
+
+// IOSTargets returns the module's iOS targets.
+func (module KMPModuleModel) IOSTargets() []KMPTarget {
+	targets := []KMPTarget{}
+	for _, target := range module.Targets {
+		if target.IsIOS() {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// HasTarget ...
+func (module KMPModuleModel) HasTarget(target KMPTarget) bool {
+	for _, t := range module.Targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKMPModuleGradleFileContent reports whether the gradle file content applies the Kotlin Multiplatform plugin,
+// the root gradle files declaring the plugin with apply false (or .apply(false)) are not modules.
+func IsKMPModuleGradleFileContent(content string) bool {
+	for _, match := range kmpPluginRegexp.FindAllStringSubmatch(content, -1) {
+		if !kmpApplyFalseRegexp.MatchString(match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseKMPTargets returns the targets declared in the Kotlin Multiplatform module's gradle file content.
+func ParseKMPTargets(content string) []KMPTarget {
+	targetMap := map[KMPTarget]bool{}
+	for _, match := range kmpTargetRegexp.FindAllStringSubmatch(content, -1) {
+		target := KMPTarget(match[1] + match[2])
+		if target == "androidTarget" {
+			target = KMPTargetAndroid
+		}
+		targetMap[target] = true
+	}
+
+	targets := []KMPTarget{}
+	for _, target := range kmpTargets {
+		if targetMap[target] {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// FilterKMPModules returns the Kotlin Multiplatform modules of the file list, sorted by their depth.
+func FilterKMPModules(fileList []string) ([]KMPModuleModel, error) {
+	modules := []KMPModuleModel{}
+	for _, pth := range fileList {
+		if base := filepath.Base(pth); base != buildGradleBasePath && base != buildGradleKtsBasePath {
+			continue
+		}
+
+		content, err := fileutil.ReadStringFromFile(pth)
+		if err != nil {
+			return []KMPModuleModel{}, err
+		}
+		if !IsKMPModuleGradleFileContent(content) {
+			continue
+		}
+
+		modules = append(modules, KMPModuleModel{
+			GradleFile:   pth,
+			Targets:      ParseKMPTargets(content),
+			HasCocoaPods: kmpCocoaPodsBlockRegexp.MatchString(content),
+		})
+	}
+	return modules, nil
+}
+
+// KMPIOSAppModel is the Xcode project of a Kotlin Multiplatform project's iOS app.
+type KMPIOSAppModel struct {
+	ProjectPath string
+	// WorkspacePath is the CocoaPods generated workspace's path, next to the project.
+	WorkspacePath        string
+	Scheme               string
+	FrameworkIntegration KMPFrameworkIntegration
+}
+
+// KMPIOSApp returns the Xcode project placed in the iosApp dir of the project's rootDir,
+// the module is the shared module, embedded into the iOS app; nil is returned if the iOS app is not found.
+func KMPIOSApp(fileList []string, rootDir string, module KMPModuleModel) (*KMPIOSAppModel, error) {
+	iosAppDir := filepath.Join(rootDir, kmpIOSAppDirName)
+
+	projectPth := ""
+	for _, pth := range fileList {
+		if filepath.Ext(pth) == ".xcodeproj" && filepath.Dir(pth) == iosAppDir {
+			projectPth = pth
+			break
+		}
+	}
+	if projectPth == "" {
+		return nil, nil
+	}
+
+	iosApp := &KMPIOSAppModel{
+		ProjectPath: projectPth,
+		Scheme:      strings.TrimSuffix(filepath.Base(projectPth), ".xcodeproj"),
+	}
+
+	if module.HasCocoaPods {
+		iosApp.FrameworkIntegration = KMPFrameworkIntegrationCocoaPods
+		iosApp.WorkspacePath = strings.TrimSuffix(projectPth, ".xcodeproj") + ".xcworkspace"
+		return iosApp, nil
+	}
+
+	pbxprojPth := filepath.Join(projectPth, "project.pbxproj")
+	if exist, err := pathutil.IsPathExists(pbxprojPth); err != nil {
+		return nil, err
+	} else if !exist {
+		return iosApp, nil
+	}
+
+	content, err := fileutil.ReadStringFromFile(pbxprojPth)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(content, kmpEmbedAndSignFrameworkTaskID) {
+		iosApp.FrameworkIntegration = KMPFrameworkIntegrationDirect
+	}
+	return iosApp, nil
+}
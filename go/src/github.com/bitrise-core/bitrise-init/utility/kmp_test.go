@@ -0,0 +1,104 @@
+package utility
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsKMPModuleGradleFileContent(t *testing.T) {
+	t.Log("kotlin dsl plugin")
+	{
+		require.True(t, IsKMPModuleGradleFileContent("plugins {\n    kotlin(\"multiplatform\")\n    id(\"com.android.library\")\n}"))
+	}
+
+	t.Log("plugin id")
+	{
+		require.True(t, IsKMPModuleGradleFileContent("plugins {\n    id 'org.jetbrains.kotlin.multiplatform'\n}"))
+	}
+
+	t.Log("version catalog alias")
+	{
+		require.True(t, IsKMPModuleGradleFileContent("plugins {\n    alias(libs.plugins.kotlinMultiplatform)\n}"))
+	}
+
+	t.Log("root gradle file")
+	{
+		require.False(t, IsKMPModuleGradleFileContent("plugins {\n    kotlin(\"multiplatform\") version \"2.0.21\" apply false\n    alias(libs.plugins.kotlinMultiplatform) apply false\n}"))
+		require.False(t, IsKMPModuleGradleFileContent("plugins {\n    alias(libs.plugins.kotlinMultiplatform).apply(false)\n    id(\"org.jetbrains.kotlin.multiplatform\").apply(false)\n}"))
+	}
+
+	t.Log("android module")
+	{
+		require.False(t, IsKMPModuleGradleFileContent("plugins {\n    id(\"com.android.application\")\n    kotlin(\"android\")\n}"))
+	}
+}
+
+func TestParseKMPTargets(t *testing.T) {
+	content := `kotlin {
+    androidTarget {
+        compilations.all {}
+    }
+    listOf(
+        iosArm64(),
+        iosSimulatorArm64()
+    ).forEach {
+        it.binaries.framework { baseName = "shared" }
+    }
+    js(IR) { browser() }
+    jvmToolchain(17)
+}
+
+android {
+    namespace = "io.bitrise.sample.shared"
+}`
+	require.Equal(t, []KMPTarget{KMPTargetAndroid, KMPTargetIOSArm64, KMPTargetIOSSimulatorArm64, KMPTargetJS}, ParseKMPTargets(content))
+
+	require.Equal(t, []KMPTarget{KMPTargetJVM}, ParseKMPTargets("kotlin {\n    jvm {\n    }\n}\n\nandroid {\n    compileSdk = 34\n}"))
+
+	require.Equal(t, []KMPTarget{KMPTargetAndroid, KMPTargetJVM}, ParseKMPTargets("kotlin {\n    android()\n    jvm()\n}"))
+}
+
+func TestKMPIOSApp(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__kmp_ios_app__")
+	require.NoError(t, err)
+
+	projectPth := filepath.Join(tmpDir, "iosApp", "iosApp.xcodeproj")
+	require.NoError(t, os.MkdirAll(projectPth, 0777))
+	fileList := []string{filepath.Join(tmpDir, "iosApp"), projectPth}
+
+	t.Log("no framework integration")
+	{
+		iosApp, err := KMPIOSApp(fileList, tmpDir, KMPModuleModel{})
+		require.NoError(t, err)
+		require.Equal(t, &KMPIOSAppModel{ProjectPath: projectPth, Scheme: "iosApp"}, iosApp)
+	}
+
+	t.Log("direct integration")
+	{
+		require.NoError(t, fileutil.WriteStringToFile(filepath.Join(projectPth, "project.pbxproj"), `shellScript = "./gradlew :shared:embedAndSignAppleFrameworkForXcode\n";`))
+
+		iosApp, err := KMPIOSApp(fileList, tmpDir, KMPModuleModel{})
+		require.NoError(t, err)
+		require.Equal(t, KMPFrameworkIntegrationDirect, iosApp.FrameworkIntegration)
+	}
+
+	t.Log("cocoapods integration")
+	{
+		iosApp, err := KMPIOSApp(fileList, tmpDir, KMPModuleModel{HasCocoaPods: true})
+		require.NoError(t, err)
+		require.Equal(t, KMPFrameworkIntegrationCocoaPods, iosApp.FrameworkIntegration)
+		require.Equal(t, filepath.Join(tmpDir, "iosApp", "iosApp.xcworkspace"), iosApp.WorkspacePath)
+	}
+
+	t.Log("no iOS app")
+	{
+		iosApp, err := KMPIOSApp([]string{projectPth}, filepath.Join(tmpDir, "iosApp"), KMPModuleModel{})
+		require.NoError(t, err)
+		require.Nil(t, iosApp)
+	}
+}
@@ -1,6 +1,7 @@
 package utility
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -18,7 +19,7 @@ const podfileBase = "Podfile"
 // AllowPodfileBaseFilter ...
 var AllowPodfileBaseFilter = BaseFilter(podfileBase, true)
 
-func getTargetDefinitionProjectMap(podfilePth, cocoapodsVersion string) (map[string]string, error) {
+func getTargetDefinitionProjectMap(ctx context.Context, podfilePth, cocoapodsVersion string) (map[string]string, error) {
 	gemfileCocoapodsVersion := ""
 	if cocoapodsVersion != "" {
 		gemfileCocoapodsVersion = fmt.Sprintf(`, '%s'`, cocoapodsVersion)
@@ -61,7 +62,7 @@ end
 	envs := []string{fmt.Sprintf("PODFILE_PATH=%s", absPodfilePth)}
 	podfileDir := filepath.Dir(absPodfilePth)
 
-	out, err := runRubyScriptForOutput(rubyScriptContent, gemfileContent, podfileDir, envs)
+	out, err := runRubyScriptForOutput(ctx, rubyScriptContent, gemfileContent, podfileDir, envs)
 	if err != nil {
 		return map[string]string{}, fmt.Errorf("ruby script failed, error: %s", err)
 	}
@@ -87,8 +88,8 @@ end
 	return targetDefinitionOutput.Data, nil
 }
 
-func getUserDefinedProjectRelavtivePath(podfilePth, cocoapodsVersion string) (string, error) {
-	targetProjectMap, err := getTargetDefinitionProjectMap(podfilePth, cocoapodsVersion)
+func getUserDefinedProjectRelavtivePath(ctx context.Context, podfilePth, cocoapodsVersion string) (string, error) {
+	targetProjectMap, err := getTargetDefinitionProjectMap(ctx, podfilePth, cocoapodsVersion)
 	if err != nil {
 		return "", fmt.Errorf("failed to get target definition map, error: %s", err)
 	}
@@ -101,7 +102,7 @@ func getUserDefinedProjectRelavtivePath(podfilePth, cocoapodsVersion string) (st
 	return "", nil
 }
 
-func getUserDefinedWorkspaceRelativePath(podfilePth, cocoapodsVersion string) (string, error) {
+func getUserDefinedWorkspaceRelativePath(ctx context.Context, podfilePth, cocoapodsVersion string) (string, error) {
 	gemfileCocoapodsVersion := ""
 	if cocoapodsVersion != "" {
 		gemfileCocoapodsVersion = fmt.Sprintf(`, '%s'`, cocoapodsVersion)
@@ -134,7 +135,7 @@ end
 	envs := []string{fmt.Sprintf("PODFILE_PATH=%s", absPodfilePth)}
 	podfileDir := filepath.Dir(absPodfilePth)
 
-	out, err := runRubyScriptForOutput(rubyScriptContent, gemfileContent, podfileDir, envs)
+	out, err := runRubyScriptForOutput(ctx, rubyScriptContent, gemfileContent, podfileDir, envs)
 	if err != nil {
 		return "", fmt.Errorf("ruby script failed, error: %s", err)
 	}
@@ -165,7 +166,7 @@ end
 // If more then one project exists in the Podfile's directory, root 'xcodeproj/project' property have to be defined in the Podfile.
 // Root 'xcodeproj/project' property will be mapped to the default cocoapods target (Pods).
 // If workspace property defined in the Podfile, it will override the workspace name.
-func GetWorkspaceProjectMap(podfilePth string, projects []string) (map[string]string, error) {
+func GetWorkspaceProjectMap(ctx context.Context, podfilePth string, projects []string) (map[string]string, error) {
 	podfileDir := filepath.Dir(podfilePth)
 
 	cocoapodsVersion := ""
@@ -206,7 +207,7 @@ func GetWorkspaceProjectMap(podfilePth string, projects []string) (map[string]st
 	}
 	// ----
 
-	projectRelPth, err := getUserDefinedProjectRelavtivePath(podfilePth, cocoapodsVersion)
+	projectRelPth, err := getUserDefinedProjectRelavtivePath(ctx, podfilePth, cocoapodsVersion)
 	if err != nil {
 		return map[string]string{}, fmt.Errorf("failed to get user defined project path, error: %s", err)
 	}
@@ -233,7 +234,7 @@ func GetWorkspaceProjectMap(podfilePth string, projects []string) (map[string]st
 		return map[string]string{}, fmt.Errorf("project not found at: %s", projectPth)
 	}
 
-	workspaceRelPth, err := getUserDefinedWorkspaceRelativePath(podfilePth, cocoapodsVersion)
+	workspaceRelPth, err := getUserDefinedWorkspaceRelativePath(ctx, podfilePth, cocoapodsVersion)
 	if err != nil {
 		return map[string]string{}, fmt.Errorf("failed to get user defined workspace path, error: %s", err)
 	}
@@ -303,3 +304,9 @@ func MergePodWorkspaceProjectMap(podWorkspaceProjectMap map[string]string, stand
 
 	return mergedStandaloneProjects, mergedWorkspaces, nil
 }
+
+// CocoaPodsCachePaths returns the cache path of the Pods dir next to the Podfile in the podfileDir,
+// keyed by its Podfile.lock.
+func CocoaPodsCachePaths(podfileDir string) []string {
+	return []string{CachePath(podfileDir+"/Pods", podfileDir+"/Podfile.lock")}
+}
@@ -1,6 +1,7 @@
 package utility
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -82,7 +83,7 @@ pod 'Alamofire', '~> 3.4'
 		expectedTargetDefinition := map[string]string{
 			"Pods": "MyXcodeProject.xcodeproj",
 		}
-		actualTargetDefinition, err := getTargetDefinitionProjectMap(podfilePth, "")
+		actualTargetDefinition, err := getTargetDefinitionProjectMap(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedTargetDefinition, actualTargetDefinition)
 	}
@@ -99,7 +100,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedTargetDefinition := map[string]string{}
-		actualTargetDefinition, err := getTargetDefinitionProjectMap(podfilePth, "")
+		actualTargetDefinition, err := getTargetDefinitionProjectMap(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedTargetDefinition, actualTargetDefinition)
 	}
@@ -130,7 +131,7 @@ end
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedTargetDefinition := map[string]string{}
-		actualTargetDefinition, err := getTargetDefinitionProjectMap(podfilePth, "0.38.0")
+		actualTargetDefinition, err := getTargetDefinitionProjectMap(context.Background(), podfilePth, "0.38.0")
 		require.NoError(t, err)
 		require.Equal(t, expectedTargetDefinition, actualTargetDefinition)
 	}
@@ -156,7 +157,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedProject := "MyXcodeProject.xcodeproj"
-		actualProject, err := getUserDefinedProjectRelavtivePath(podfilePth, "")
+		actualProject, err := getUserDefinedProjectRelavtivePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedProject, actualProject)
 	}
@@ -173,7 +174,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedProject := ""
-		actualProject, err := getUserDefinedProjectRelavtivePath(podfilePth, "")
+		actualProject, err := getUserDefinedProjectRelavtivePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedProject, actualProject)
 	}
@@ -199,7 +200,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedWorkspace := "MyWorkspace.xcworkspace"
-		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(podfilePth, "")
+		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedWorkspace, actualWorkspace)
 	}
@@ -216,7 +217,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedWorkspace := ""
-		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(podfilePth, "")
+		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedWorkspace, actualWorkspace)
 	}
@@ -240,7 +241,7 @@ pod 'Alamofire', '~> 3.4'
 		podfilePth := filepath.Join(tmpDir, "Podfile")
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{})
 		require.Error(t, err)
 		require.Equal(t, 0, len(workspaceProjectMap))
 
@@ -262,7 +263,7 @@ pod 'Alamofire', '~> 3.4'
 		projectPth := filepath.Join(tmpDir, "project.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(projectPth, project))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{projectPth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{projectPth})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -299,7 +300,7 @@ pod 'Alamofire', '~> 3.4'
 		project2Pth := filepath.Join(tmpDir, "project2.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(project2Pth, project2))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{project1Pth, project2Pth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{project1Pth, project2Pth})
 		require.Error(t, err)
 		require.Equal(t, 0, len(workspaceProjectMap))
 
@@ -318,7 +319,7 @@ pod 'Alamofire', '~> 3.4'
 		podfilePth := filepath.Join(tmpDir, "Podfile")
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{})
 		require.Error(t, err)
 		require.Equal(t, 0, len(workspaceProjectMap))
 
@@ -341,7 +342,7 @@ pod 'Alamofire', '~> 3.4'
 		projectPth := filepath.Join(tmpDir, "project.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(projectPth, project))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{projectPth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{projectPth})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -379,7 +380,7 @@ pod 'Alamofire', '~> 3.4'
 		project2Pth := filepath.Join(tmpDir, "project2.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(project2Pth, project2))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{project1Pth, project2Pth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{project1Pth, project2Pth})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -413,7 +414,7 @@ pod 'Alamofire', '~> 3.4'
 		projectPth := filepath.Join(tmpDir, "project.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(projectPth, project))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{projectPth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{projectPth})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -452,7 +453,7 @@ pod 'Alamofire', '~> 3.4'
 		project2Pth := filepath.Join(tmpDir, "project2.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(project2Pth, project2))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{project1Pth, project2Pth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), podfilePth, []string{project1Pth, project2Pth})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -1,6 +1,7 @@
 package utility
 
 import (
+	"context"
 	"errors"
 	"path"
 
@@ -13,7 +14,7 @@ import (
 	"github.com/bitrise-io/go-utils/pathutil"
 )
 
-func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnvs []string) (string, error) {
+func runRubyScriptForOutput(ctx context.Context, scriptContent, gemfileContent, inDir string, withEnvs []string) (string, error) {
 	tmpDir, err := pathutil.NormalizedOSTempDirPath("__bitrise-init__")
 	if err != nil {
 		return "", err
@@ -40,7 +41,7 @@ func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnv
 		withEnvs = append(withEnvs, "BUNDLE_GEMFILE="+gemfilePth)
 		cmd.AppendEnvs(withEnvs...)
 
-		if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		if out, err := RunCmdContextAndReturnTrimmedCombinedOutput(ctx, cmd.GetCmd()); err != nil {
 			if errorutil.IsExitStatusError(err) {
 				return "", errors.New(out)
 			}
@@ -70,7 +71,7 @@ func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnv
 		cmd.AppendEnvs(withEnvs...)
 	}
 
-	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	out, err := RunCmdContextAndReturnTrimmedCombinedOutput(ctx, cmd.GetCmd())
 	if err != nil {
 		if errorutil.IsExitStatusError(err) {
 			return "", errors.New(out)
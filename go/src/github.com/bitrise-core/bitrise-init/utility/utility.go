@@ -212,3 +212,12 @@ func FileContains(pth, str string) (bool, error) {
 
 	return strings.Contains(content, str), nil
 }
+
+// CachePath returns the path in the cache-push step's cache path format: path -> indicator,
+// the cache of the path is updated if the indicator file changes, the indicator is omitted if empty.
+func CachePath(pth, indicator string) string {
+	if indicator == "" {
+		return pth
+	}
+	return pth + " -> " + indicator
+}
@@ -72,3 +72,9 @@ func GetSolutionConfigs(solutionFile string) (map[string][]string, error) {
 
 	return configMap, nil
 }
+
+// NuGetCachePaths returns the cache path of the global NuGet packages folder,
+// keyed by the packages.lock.json lockfile if it is not empty.
+func NuGetCachePaths(lockfile string) []string {
+	return []string{CachePath("$HOME/.nuget/packages", lockfile)}
+}
@@ -61,8 +61,14 @@ function validate_required_input {
 echo_info "Configs:"
 echo_details "* scan_dir: $scan_dir"
 echo_details "* output_dir: $output_dir"
+echo_details "* scanners: $scanners"
+echo_details "* skip_scanners: $skip_scanners"
 echo_details "* scan_result_submit_url: $scan_result_submit_url"
-echo_details "* scan_result_submit_api_token: $scan_result_submit_api_token"
+if [ ! -z "${scan_result_submit_api_token}" ] ; then
+	echo_details "* scan_result_submit_api_token: ***"
+else
+	echo_details "* scan_result_submit_api_token:"
+fi
 
 echo
 
@@ -102,6 +108,9 @@ echo_done "ceated at: ${bin_pth}"
 # Running scanner
 echo_info "Running scanner..."
 
+export BITRISE_INIT_SCANNERS="$scanners"
+export BITRISE_INIT_SKIP_SCANNERS="$skip_scanners"
+
 set +e
 
 $bin_pth --ci config --dir $scan_dir --output-dir $output_dir --format json
@@ -117,19 +126,14 @@ if [ ! -z "${scan_result_submit_url}" ] ; then
 		echo_fail "only run in CI mode generated result to upload"
 	fi
 
-	if [[ ! -f "${output_dir}/result.json" ]] ; then
-		echo_warn "no scan result found at ${output_dir}/result.json"
-		echo_fail "nothing to upload"
-	fi
-
 	echo_info "Submitting results..."
 
-	curl --fail -H "Content-Type: application/json" \
-		--data-binary @${output_dir}/result.json \
-		"${scan_result_submit_url}?api_token=${scan_result_submit_api_token}"
+	export BITRISE_INIT_SUBMIT_URL="$scan_result_submit_url"
+	export BITRISE_INIT_SUBMIT_API_TOKEN="$scan_result_submit_api_token"
 
-	echo
-	echo_done "submitted"
+	if ! $bin_pth --ci submit --result "${output_dir}/result.json" --receipt "${output_dir}/receipt.json" ; then
+		echo_fail "failed to submit results"
+	fi
 fi
 
 if [[ $scanner_exit_code -eq 0 ]] ; then